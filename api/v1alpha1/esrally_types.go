@@ -38,9 +38,12 @@ const (
 )
 
 // EsrallySpec defines the desired state of Esrally.
+// +kubebuilder:validation:XValidation:rule="!(has(self.dataProfile) && self.dataProfile == 'dense_vector' && has(self.targetVersion) && (self.targetVersion == '6' || self.targetVersion.startsWith('6.')))",message="dataProfile dense_vector requires Elasticsearch 7 or newer"
 type EsrallySpec struct {
 	// targetVersion is the Elasticsearch target version used for ESRally compatibility decisions.
 	// It is not passed to Rally as --distribution-version in benchmark-only mode.
+	// The generated workload supports Elasticsearch 6 and newer.
+	// +kubebuilder:validation:Pattern=`^([6-9]|[1-9][0-9]+)(\..*)?$`
 	// +optional
 	TargetVersion string `json:"targetVersion,omitempty"`
 
@@ -72,6 +75,20 @@ type EsrallySpec struct {
 	// +optional
 	Workload string `json:"workload,omitempty"`
 
+	// indexConfigMap names a ConfigMap in the benchmark namespace whose
+	// settings.json and mappings.json keys replace the generated index settings
+	// and mappings. Both keys are optional.
+	// +optional
+	IndexConfigMap string `json:"indexConfigMap,omitempty"`
+
+	// reportFormat selects the Rally summary report read by the exporter.
+	// json reports the results of every task, including the percentiles of
+	// the latency and the service time.
+	// +kubebuilder:validation:Enum={csv,json}
+	// +kubebuilder:default=csv
+	// +optional
+	ReportFormat string `json:"reportFormat,omitempty"`
+
 	BenchCommon `json:",inline"`
 }
 
@@ -96,6 +113,31 @@ type EsrallyStatus struct {
 
 	// the completion timestamp of the test.
 	CompletionTimestamp *metav1.Time `json:"completionTimestamp,omitempty"`
+
+	// the faults injected into the run jobs.
+	// +optional
+	Faults []FaultRecord `json:"faults,omitempty"`
+
+	// the target resolved from targetRef.
+	// +optional
+	Target *ResolvedTarget `json:"target,omitempty"`
+
+	// the benchmarks of targets
+	// +optional
+	Targets []TargetStatus `json:"targets,omitempty"`
+
+	// where the logs of the jobs were archived
+	// +optional
+	Logs []ArchivedLog `json:"logs,omitempty"`
+
+	// the reason the benchmark failed, TimedOut if it ran out of time and
+	// JobFailed if one of its jobs failed
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// the step that ran out of time, total if the benchmark did
+	// +optional
+	TimedOutStep string `json:"timedOutStep,omitempty"`
 }
 
 //+kubebuilder:object:root=true
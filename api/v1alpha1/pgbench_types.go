@@ -62,6 +62,15 @@ type PgbenchSpec struct {
 	// +optional
 	SelectOnly bool `json:"selectOnly,omitempty"`
 
+	// the built-in scripts to run with their weights instead of tpcb-like,
+	// every transaction picks a script of builtins and scripts by weight
+	// +optional
+	Builtins []PgbenchBuiltin `json:"builtins,omitempty"`
+
+	// the custom scripts of ConfigMaps to run with their weights
+	// +optional
+	Scripts []PgbenchScript `json:"scripts,omitempty"`
+
 	// Number of transactions each client runs.
 	// Note: the transactions and duration parameters are mutually exclusive.
 	// +kubebuilder:validation:Minimum=0
@@ -77,9 +86,61 @@ type PgbenchSpec struct {
 	// +optional
 	Duration int `json:"duration,omitempty"`
 
+	// warmup runs an untimed pass before the measured pass, its output is
+	// not part of the result. Either a duration such as 30s or 2m, or a
+	// percentage such as 10% of duration, or of transactions when
+	// transactions is set.
+	// +kubebuilder:validation:Pattern=`^(([0-9]+(s|m|h))+|[0-9]{1,2}(\.[0-9]+)?%)$`
+	// +optional
+	Warmup string `json:"warmup,omitempty"`
+
+	// Write the latency of every transaction to a log on the shared volume,
+	// the exporter computes the latency percentiles, the latency histogram
+	// of every interval and the statistics of every script from it.
+	// +optional
+	Log bool `json:"log,omitempty"`
+
+	// Aggregate the log over this many seconds instead of logging every
+	// transaction, the aggregated log holds the count, average, min and max
+	// latency of every interval but no percentiles. Implies log.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	AggregateInterval int `json:"aggregateInterval,omitempty"`
+
 	BenchCommon `json:",inline"`
 }
 
+// PgbenchBuiltin is a built-in script of pgbench, run with -b name@weight
+type PgbenchBuiltin struct {
+	// the name of the built-in script
+	// +kubebuilder:validation:Enum={tpcb-like,simple-update,select-only}
+	// +required
+	Name string `json:"name"`
+
+	// the relative frequency of the script
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	// +optional
+	Weight int `json:"weight,omitempty"`
+}
+
+// PgbenchScript is a custom script of a ConfigMap, run with -f file@weight
+type PgbenchScript struct {
+	// the ConfigMap holding the script
+	// +required
+	ConfigMap string `json:"configMap"`
+
+	// the key of the script in the ConfigMap
+	// +required
+	Key string `json:"key"`
+
+	// the relative frequency of the script
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	// +optional
+	Weight int `json:"weight,omitempty"`
+}
+
 // PgbenchStatus defines the observed state of Pgbench
 type PgbenchStatus struct {
 	// Phase is the current state of the test. Valid values are Disabled, Enabled, Failed, Enabling, Disabling.
@@ -101,6 +162,95 @@ type PgbenchStatus struct {
 
 	// the completion timestamp of the test
 	CompletionTimestamp *metav1.Time `json:"completionTimestamp,omitempty"`
+
+	// the faults injected into the run jobs
+	// +optional
+	Faults []FaultRecord `json:"faults,omitempty"`
+
+	// the target resolved from targetRef
+	// +optional
+	Target *ResolvedTarget `json:"target,omitempty"`
+
+	// the benchmarks of targets
+	// +optional
+	Targets []TargetStatus `json:"targets,omitempty"`
+
+	// where the logs of the jobs were archived
+	// +optional
+	Logs []ArchivedLog `json:"logs,omitempty"`
+
+	// the reason the benchmark failed, TimedOut if it ran out of time and
+	// JobFailed if one of its jobs failed
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// the step that ran out of time, total if the benchmark did
+	// +optional
+	TimedOutStep string `json:"timedOutStep,omitempty"`
+
+	// the latency statistics of the transaction logs of the run jobs
+	// +optional
+	LatencyStats []PgbenchLatencyStats `json:"latencyStats,omitempty"`
+}
+
+// PgbenchLatencyStats is the analysis of the transaction log of a run job,
+// the latencies are in milliseconds
+type PgbenchLatencyStats struct {
+	// the run job
+	Job string `json:"job"`
+
+	// the number of transactions logged
+	Transactions int64 `json:"transactions"`
+
+	// the number of failed transactions
+	// +optional
+	Failed int64 `json:"failed,omitempty"`
+
+	// the number of transactions skipped for the rate limit
+	// +optional
+	Skipped int64 `json:"skipped,omitempty"`
+
+	// the latency percentiles, empty for aggregated logs
+	// +optional
+	P50 string `json:"p50,omitempty"`
+	// +optional
+	P95 string `json:"p95,omitempty"`
+	// +optional
+	P99 string `json:"p99,omitempty"`
+
+	// the max latency
+	// +optional
+	Max string `json:"max,omitempty"`
+
+	// the statistics of every script, empty for aggregated logs
+	// +optional
+	Scripts []PgbenchScriptStats `json:"scripts,omitempty"`
+}
+
+// PgbenchScriptStats are the statistics of the transactions of a script,
+// the latencies are in milliseconds
+type PgbenchScriptStats struct {
+	// the number of the script in the order of the pgbench arguments
+	Script int `json:"script"`
+
+	// the number of transactions of the script
+	Transactions int64 `json:"transactions"`
+
+	// the number of failed transactions of the script
+	// +optional
+	Failed int64 `json:"failed,omitempty"`
+
+	// the average latency
+	// +optional
+	Avg string `json:"avg,omitempty"`
+
+	// the 95th percentile latency
+	// +optional
+	P95 string `json:"p95,omitempty"`
+
+	// the max latency
+	// +optional
+	Max string `json:"max,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -34,17 +34,39 @@ type SysbenchSpec struct {
 	// +optional
 	Threads []int `json:"threads,omitempty"`
 
-	// the sysbench test types to run
+	// the sysbench test types to run, a built-in test or the name of a Lua
+	// script of the scripts without .lua
 	// +kubebuilder:validation:MinItems=1
 	// +kubebuilder:default={"oltp_read_write"}
 	// +optional
 	Types []string `json:"types,omitempty"`
 
+	// the ConfigMaps holding custom *.lua scripts, the script <name>.lua can
+	// be used as the type <name>
+	// +optional
+	Scripts []string `json:"scripts,omitempty"`
+
 	// the number of seconds to run sysbench
 	// +kubebuilder:validation:Minimum=1
 	// +optional
 	Duration int `json:"duration,omitempty"`
 
+	// warmup runs an untimed pass before the measured pass, its output is
+	// not part of the result. Either a duration such as 30s or 2m, or a
+	// percentage of duration such as 10%.
+	// +kubebuilder:validation:Pattern=`^(([0-9]+(s|m|h))+|[0-9]{1,2}(\.[0-9]+)?%)$`
+	// +optional
+	Warmup string `json:"warmup,omitempty"`
+
+	// the latency percentile sysbench reports every interval and in the
+	// statistics, the exporter computes p50 to p99.9 from the latency
+	// histogram in addition
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:default=99
+	// +optional
+	Percentile int `json:"percentile,omitempty"`
+
 	BenchCommon `json:",inline"`
 }
 
@@ -69,6 +91,31 @@ type SysbenchStatus struct {
 
 	// the completion timestamp of the test
 	CompletionTimestamp *metav1.Time `json:"completionTimestamp,omitempty"`
+
+	// the faults injected into the run jobs
+	// +optional
+	Faults []FaultRecord `json:"faults,omitempty"`
+
+	// the target resolved from targetRef
+	// +optional
+	Target *ResolvedTarget `json:"target,omitempty"`
+
+	// the benchmarks of targets
+	// +optional
+	Targets []TargetStatus `json:"targets,omitempty"`
+
+	// where the logs of the jobs were archived
+	// +optional
+	Logs []ArchivedLog `json:"logs,omitempty"`
+
+	// the reason the benchmark failed, TimedOut if it ran out of time and
+	// JobFailed if one of its jobs failed
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// the step that ran out of time, total if the benchmark did
+	// +optional
+	TimedOutStep string `json:"timedOutStep,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -114,6 +114,31 @@ type TpccStatus struct {
 
 	// the completion timestamp of the test
 	CompletionTimestamp *metav1.Time `json:"completionTimestamp,omitempty"`
+
+	// the faults injected into the run jobs
+	// +optional
+	Faults []FaultRecord `json:"faults,omitempty"`
+
+	// the target resolved from targetRef
+	// +optional
+	Target *ResolvedTarget `json:"target,omitempty"`
+
+	// the benchmarks of targets
+	// +optional
+	Targets []TargetStatus `json:"targets,omitempty"`
+
+	// where the logs of the jobs were archived
+	// +optional
+	Logs []ArchivedLog `json:"logs,omitempty"`
+
+	// the reason the benchmark failed, TimedOut if it ran out of time and
+	// JobFailed if one of its jobs failed
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// the step that ran out of time, total if the benchmark did
+	// +optional
+	TimedOutStep string `json:"timedOutStep,omitempty"`
 }
 
 //+kubebuilder:object:root=true
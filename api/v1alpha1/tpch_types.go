@@ -24,6 +24,7 @@ import (
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // TpchSpec defines the desired state of Tpch
+// +kubebuilder:validation:XValidation:rule="!(has(self.hooks) && has(self.hooks.afterPrepare) && size(self.hooks.afterPrepare) > 0 && (!has(self.step) || self.step == 'all'))",message="afterPrepare hooks are not supported with step all, TPC-H prepares and runs in a single job"
 type TpchSpec struct {
 	// overall scale of the tpch test
 	// +kubebuilder:validation:Minimum=1
@@ -55,6 +56,35 @@ type TpchStatus struct {
 
 	// the completion timestamp of the test
 	CompletionTimestamp *metav1.Time `json:"completionTimestamp,omitempty"`
+
+	// the faults injected into the run jobs
+	// +optional
+	Faults []FaultRecord `json:"faults,omitempty"`
+
+	// the target resolved from targetRef
+	// +optional
+	Target *ResolvedTarget `json:"target,omitempty"`
+
+	// the benchmarks of targets
+	// +optional
+	Targets []TargetStatus `json:"targets,omitempty"`
+
+	// where the logs of the jobs were archived
+	// +optional
+	Logs []ArchivedLog `json:"logs,omitempty"`
+
+	// the reason the benchmark failed, TimedOut if it ran out of time and
+	// JobFailed if one of its jobs failed
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// the step that ran out of time, total if the benchmark did
+	// +optional
+	TimedOutStep string `json:"timedOutStep,omitempty"`
+
+	// the results of the queries of the run job
+	// +optional
+	QueryStats *QueryStats `json:"queryStats,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -1,6 +1,9 @@
 package v1alpha1
 
-import corev1 "k8s.io/api/core/v1"
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 // BenchmarkPhase is the current state of the test.
 // +kubebuilder:validation:Enum={Pending,Running,Completed,Failed}
@@ -14,6 +17,7 @@ const (
 )
 
 // BenchCommon defines common attributes for all benchmarks.
+// +kubebuilder:validation:XValidation:rule="[has(self.target) && has(self.target.host) && size(self.target.host) > 0, has(self.targetRef), has(self.targets) && size(self.targets) > 0].filter(x, x).size() == 1",message="exactly one of target with a host, targetRef or targets must be set, the host of targetRef is resolved"
 type BenchCommon struct {
 	// step is all, will exec cleanup, prepare, run
 	// step is cleanup, will exec cleanup
@@ -25,8 +29,26 @@ type BenchCommon struct {
 	Step string `json:"step,omitempty"`
 
 	// the database target to run benchmark
-	// +required
-	Target Target `json:"target"`
+	// +optional
+	Target Target `json:"target,omitempty"`
+
+	// targetRef points to a Service or a KubeBlocks Cluster, the controller
+	// resolves host, port, driver and credentials from it and fills target.
+	// Values set in target are kept, except host and port.
+	// +optional
+	TargetRef *TargetRef `json:"targetRef,omitempty"`
+
+	// targets runs the benchmark against each of the targets instead of
+	// target, every target gets its own benchmark named <name>-<target name>
+	// +optional
+	Targets []BenchTarget `json:"targets,omitempty"`
+
+	// targetsPolicy runs the benchmarks of the targets one after another,
+	// or at the same time
+	// +kubebuilder:validation:Enum={Sequential,Parallel}
+	// +kubebuilder:default=Sequential
+	// +optional
+	TargetsPolicy string `json:"targetsPolicy,omitempty"`
 
 	// the other sysbench run command flags to use for benchmark
 	// +optional
@@ -39,6 +61,198 @@ type BenchCommon struct {
 	// the resource requirements for the benchmark
 	ResourceLimits   *ResourceList `json:"resourceLimits,omitempty"`
 	ResourceRequests *ResourceList `json:"resourceRequests,omitempty"`
+
+	// hooks run extra jobs around the benchmark steps, e.g. to flush caches
+	// or take a snapshot between prepare and run
+	// +optional
+	Hooks *Hooks `json:"hooks,omitempty"`
+
+	// faults are injected into every run job at their offset, e.g. to see
+	// how tps and latency behave during a failover
+	// +optional
+	Faults []Fault `json:"faults,omitempty"`
+
+	// logArchive keeps the full log of every job, the conditions only keep
+	// the end of the last one
+	// +optional
+	LogArchive *LogArchive `json:"logArchive,omitempty"`
+
+	// timeouts fail the benchmark with the reason TimedOut when a step or
+	// the whole benchmark runs longer than allowed
+	// +optional
+	Timeouts *Timeouts `json:"timeouts,omitempty"`
+
+	// ttlSecondsAfterFinished deletes the jobs of the benchmark that many
+	// seconds after it completed or failed, once their results and logs are
+	// recorded. Defaults to the ttl of the manager, no jobs are deleted if
+	// neither is set.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+
+	// deleteAfterTTL deletes the benchmark itself instead of only its jobs
+	// when the ttl expired
+	// +optional
+	DeleteAfterTTL bool `json:"deleteAfterTTL,omitempty"`
+
+	// otlp pushes the metrics of the exporter sidecar of the run jobs to an
+	// OTLP endpoint besides serving them to prometheus
+	// +optional
+	OTLP *OTLP `json:"otlp,omitempty"`
+}
+
+// OTLP is the endpoint the exporter pushes the metrics to, the metrics carry
+// the benchmark, job, kind and target as resource attributes
+type OTLP struct {
+	// the host:port of the collector, e.g. otel-collector.monitoring:4317
+	// +required
+	Endpoint string `json:"endpoint"`
+
+	// +kubebuilder:validation:Enum={grpc,http}
+	// +kubebuilder:default=grpc
+	// +optional
+	Protocol string `json:"protocol,omitempty"`
+
+	// insecure pushes without TLS
+	// +optional
+	Insecure bool `json:"insecure,omitempty"`
+
+	// the seconds between two pushes
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=15
+	// +optional
+	IntervalSeconds int `json:"intervalSeconds,omitempty"`
+}
+
+// Timeouts limits the time of every job of a step, and of the benchmark as a
+// whole counted from its creation. Zero means no limit.
+type Timeouts struct {
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	PreCheckSeconds int `json:"precheckSeconds,omitempty"`
+
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	CleanupSeconds int `json:"cleanupSeconds,omitempty"`
+
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	PrepareSeconds int `json:"prepareSeconds,omitempty"`
+
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RunSeconds int `json:"runSeconds,omitempty"`
+
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TotalSeconds int `json:"totalSeconds,omitempty"`
+}
+
+// Hooks lists the hooks of every phase, hooks of the same phase run one
+// after another in the order listed. Hooks of a step that is not executed
+// are skipped, afterAll always runs after the last step.
+type Hooks struct {
+	// +optional
+	BeforePrepare []Hook `json:"beforePrepare,omitempty"`
+
+	// +optional
+	AfterPrepare []Hook `json:"afterPrepare,omitempty"`
+
+	// +optional
+	BeforeRun []Hook `json:"beforeRun,omitempty"`
+
+	// +optional
+	AfterRun []Hook `json:"afterRun,omitempty"`
+
+	// +optional
+	AfterAll []Hook `json:"afterAll,omitempty"`
+}
+
+// Hook runs exactly one of container, sql or http as its own job.
+// +kubebuilder:validation:MinProperties=2
+// +kubebuilder:validation:MaxProperties=2
+type Hook struct {
+	// the name of the hook, used in the job name
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=20
+	// +required
+	Name string `json:"name"`
+
+	// run a container
+	// +optional
+	Container *ContainerHook `json:"container,omitempty"`
+
+	// run the sql scripts of a ConfigMap with the tools image
+	// +optional
+	SQL *SQLHook `json:"sql,omitempty"`
+
+	// send an http request with the tools image
+	// +optional
+	HTTP *HTTPHook `json:"http,omitempty"`
+}
+
+type ContainerHook struct {
+	// the image to run
+	// +required
+	Image string `json:"image"`
+
+	// +optional
+	Command []string `json:"command,omitempty"`
+
+	// +optional
+	Args []string `json:"args,omitempty"`
+
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+}
+
+type SQLHook struct {
+	// the ConfigMap holding the *.sql files, files run in name order
+	// +required
+	ConfigMap string `json:"configMap"`
+
+	// the database to run the scripts in, defaults to the target database
+	// +optional
+	Database string `json:"database,omitempty"`
+
+	// none runs every statement in autocommit mode, file wraps every file
+	// and all wraps every file in a single transaction
+	// +kubebuilder:default=none
+	// +kubebuilder:validation:Enum={none,file,all}
+	// +optional
+	Transaction string `json:"transaction,omitempty"`
+
+	// keep running the remaining statements after a statement failed,
+	// the hook still fails
+	// +optional
+	ContinueOnError bool `json:"continueOnError,omitempty"`
+}
+
+type HTTPHook struct {
+	// the url to call, e.g. http://es.default.svc:9200/_cache/clear
+	// +required
+	URL string `json:"url"`
+
+	// +kubebuilder:default=GET
+	// +kubebuilder:validation:Enum={GET,POST,PUT,PATCH,DELETE,HEAD}
+	// +optional
+	Method string `json:"method,omitempty"`
+
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// +optional
+	Body string `json:"body,omitempty"`
+
+	// the request timeout in seconds
+	// +kubebuilder:default=60
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+
+	// skip the verification of the server certificate
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
 }
 
 type ResourceList struct {
@@ -78,3 +292,288 @@ type Target struct {
 	// +kubebuilder:default=kubebench
 	Database string `json:"database,omitempty"`
 }
+
+// Fault runs exactly one of deletePods, scaleStatefulSet, cordonNode or
+// container once the run job has been running for offsetSeconds.
+type Fault struct {
+	// the name of the fault, used in the status and the container job name
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=20
+	// +required
+	Name string `json:"name"`
+
+	// the seconds after the start of the run job to inject the fault
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	OffsetSeconds int `json:"offsetSeconds,omitempty"`
+
+	// delete the pods matching the selector, e.g. the primary of the target
+	// +optional
+	DeletePods *DeletePodsFault `json:"deletePods,omitempty"`
+
+	// scale a StatefulSet to the given replicas
+	// +optional
+	ScaleStatefulSet *ScaleStatefulSetFault `json:"scaleStatefulSet,omitempty"`
+
+	// mark a node unschedulable
+	// +optional
+	CordonNode *CordonNodeFault `json:"cordonNode,omitempty"`
+
+	// run a container as its own job next to the run job
+	// +optional
+	Container *ContainerHook `json:"container,omitempty"`
+}
+
+type DeletePodsFault struct {
+	// the namespace of the pods, defaults to the namespace of the benchmark.
+	// Other namespaces need the manager to allow cluster faults.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// +required
+	Selector metav1.LabelSelector `json:"selector"`
+
+	// the grace period of the deletion, 0 kills the pods immediately
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	GracePeriodSeconds *int64 `json:"gracePeriodSeconds,omitempty"`
+}
+
+type ScaleStatefulSetFault struct {
+	// the namespace of the StatefulSet, defaults to the namespace of the
+	// benchmark. Other namespaces need the manager to allow cluster faults.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// +required
+	Name string `json:"name"`
+
+	// +kubebuilder:validation:Minimum=0
+	// +required
+	Replicas int32 `json:"replicas"`
+}
+
+// CordonNodeFault marks the node unschedulable until the run job finished,
+// it needs the manager to allow cluster faults
+type CordonNodeFault struct {
+	// +required
+	Name string `json:"name"`
+}
+
+// FaultRecord records when a fault was injected into a run job
+type FaultRecord struct {
+	Name string `json:"name"`
+
+	// the run job the fault was injected into
+	Job string `json:"job"`
+
+	Time metav1.Time `json:"time"`
+
+	// what the fault did, or why it failed
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// +optional
+	Failed bool `json:"failed,omitempty"`
+}
+
+type TargetRef struct {
+	// +kubebuilder:validation:Enum={Service,Cluster}
+	// +kubebuilder:default=Cluster
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// +required
+	Name string `json:"name"`
+
+	// defaults to the namespace of the benchmark
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// the component of the Cluster, defaults to the first component
+	// +optional
+	Component string `json:"component,omitempty"`
+
+	// the name of the service port, defaults to the first port
+	// +optional
+	PortName string `json:"portName,omitempty"`
+
+	// the Secret with the username and password keys. For a Cluster it
+	// defaults to the connection credential the cluster publishes.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// ResolvedTarget records what a targetRef resolved to, the password stays
+// in the Secret
+type ResolvedTarget struct {
+	// +optional
+	Driver string `json:"driver,omitempty"`
+
+	Host string `json:"host"`
+
+	Port int `json:"port"`
+
+	// +optional
+	User string `json:"user,omitempty"`
+
+	// the Secret the credentials are read from
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// the time the targetRef was resolved
+	ResolvedAt metav1.Time `json:"resolvedAt"`
+}
+
+// BenchTarget is one of the targets a benchmark is compared across, it sets
+// target, targetRef or both, with targetRef target only carries the
+// credentials and the database
+// +kubebuilder:validation:XValidation:rule="[has(self.target) && has(self.target.host) && size(self.target.host) > 0, has(self.targetRef)].filter(x, x).size() == 1",message="exactly one of target with a host or targetRef must be set, the host of targetRef is resolved"
+type BenchTarget struct {
+	// the id of the target, used in the benchmark name and the target label
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=20
+	// +required
+	Name string `json:"name"`
+
+	// +optional
+	Target *Target `json:"target,omitempty"`
+
+	// +optional
+	TargetRef *TargetRef `json:"targetRef,omitempty"`
+}
+
+// TargetStatus is the progress of the benchmark of one target
+type TargetStatus struct {
+	Name string `json:"name"`
+
+	// the benchmark that runs against the target
+	Benchmark string `json:"benchmark"`
+
+	// +optional
+	Phase BenchmarkPhase `json:"phase,omitempty"`
+
+	// +optional
+	Completions string `json:"completions,omitempty"`
+}
+
+// LogArchive stores the logs of all containers of every job in exactly one of
+// a PVC, an S3 compatible bucket or ConfigMaps. The logs are stored as
+// <prefix>/<benchmark>/<job>/<container>.log.
+type LogArchive struct {
+	// the prefix of the archived files
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// +optional
+	PVC *PVCArchive `json:"pvc,omitempty"`
+
+	// +optional
+	S3 *S3Archive `json:"s3,omitempty"`
+
+	// +optional
+	ConfigMap *ConfigMapArchive `json:"configMap,omitempty"`
+}
+
+// PVCArchive copies the logs into the claim with a job once the job finished
+type PVCArchive struct {
+	// +required
+	ClaimName string `json:"claimName"`
+}
+
+// S3Archive uploads the logs to a bucket with a job once the job finished
+type S3Archive struct {
+	// the endpoint of the service, e.g. https://s3.amazonaws.com or
+	// http://minio.default.svc:9000
+	// +required
+	Endpoint string `json:"endpoint"`
+
+	// +required
+	Bucket string `json:"bucket"`
+
+	// +kubebuilder:default=us-east-1
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// the Secret with the accessKeyId and secretAccessKey keys, it is read
+	// by the job uploading the logs
+	// +required
+	SecretName string `json:"secretName"`
+
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// ConfigMapArchive splits the logs into ConfigMaps named
+// <job>-<container>-log-<index>, they are deleted with the benchmark
+type ConfigMapArchive struct {
+	// the size of a chunk in bytes
+	// +kubebuilder:validation:Minimum=1024
+	// +kubebuilder:validation:Maximum=1000000
+	// +kubebuilder:default=524288
+	// +optional
+	ChunkBytes int `json:"chunkBytes,omitempty"`
+}
+
+// ArchivedLog is where the log of a container of a job was archived
+type ArchivedLog struct {
+	Job string `json:"job"`
+
+	Container string `json:"container"`
+
+	// pvc://<claim>/<path>, s3://<bucket>/<key> or configmap://<namespace>/<name prefix>,
+	// a log archived to a pvc has none until it is copied into the claim
+	// +optional
+	Location string `json:"location,omitempty"`
+
+	// the number of ConfigMaps the log is split into
+	// +optional
+	Chunks int `json:"chunks,omitempty"`
+
+	// the error when the log could not be archived
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// QueryStats are the results of the queries of the run job of TPC-H or
+// TPC-DS, the durations are in seconds
+type QueryStats struct {
+	// the run job
+	Job string `json:"job"`
+
+	// the duration of all queries
+	// +optional
+	Duration string `json:"duration,omitempty"`
+
+	// the number of failed queries
+	// +optional
+	Failed int `json:"failed,omitempty"`
+
+	// 3600 times the scale over the geometric mean of the query durations
+	// +optional
+	Power string `json:"power,omitempty"`
+
+	// the queries per hour times the scale
+	// +optional
+	Throughput string `json:"throughput,omitempty"`
+
+	// the result of every query
+	// +optional
+	Queries []QueryResult `json:"queries,omitempty"`
+}
+
+// QueryResult is the result of a query
+type QueryResult struct {
+	Query int `json:"query"`
+
+	// the duration in seconds
+	// +optional
+	Duration string `json:"duration,omitempty"`
+
+	// the rows the query returned
+	// +optional
+	Rows int64 `json:"rows,omitempty"`
+
+	// +optional
+	Failed bool `json:"failed,omitempty"`
+}
@@ -37,6 +37,13 @@ type YcsbSpec struct {
 	// +optional
 	OperationCount int `json:"operationCount,omitempty"`
 
+	// warmup runs an untimed pass before the measured pass, its output is
+	// not part of the result. Either a duration such as 30s or 2m, or a
+	// percentage of operationCount such as 10%.
+	// +kubebuilder:validation:Pattern=`^(([0-9]+(s|m|h))+|[0-9]{1,2}(\.[0-9]+)?%)$`
+	// +optional
+	Warmup string `json:"warmup,omitempty"`
+
 	// the proportion of reads in the run phase.
 	// +kubebuilder:validation:Minimum=0
 	// +kubebuilder:validation:Maximum=100
@@ -133,6 +140,31 @@ type YcsbStatus struct {
 
 	// the completion timestamp of the test
 	CompletionTimestamp *metav1.Time `json:"completionTimestamp,omitempty"`
+
+	// the faults injected into the run jobs
+	// +optional
+	Faults []FaultRecord `json:"faults,omitempty"`
+
+	// the target resolved from targetRef
+	// +optional
+	Target *ResolvedTarget `json:"target,omitempty"`
+
+	// the benchmarks of targets
+	// +optional
+	Targets []TargetStatus `json:"targets,omitempty"`
+
+	// where the logs of the jobs were archived
+	// +optional
+	Logs []ArchivedLog `json:"logs,omitempty"`
+
+	// the reason the benchmark failed, TimedOut if it ran out of time and
+	// JobFailed if one of its jobs failed
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// the step that ran out of time, total if the benchmark did
+	// +optional
+	TimedOutStep string `json:"timedOutStep,omitempty"`
 }
 
 //+kubebuilder:object:root=true
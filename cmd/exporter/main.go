@@ -1,46 +1,140 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"k8s.io/klog/v2"
 
 	"github.com/apecloud/kubebench/internal/exporter"
+	"github.com/apecloud/kubebench/pkg/constants"
 )
 
 var (
 	benchType string
 	benchName string
 	jobName   string
+	target    string
+	dumpFile  bool
 	file      string
 	doneFile  string
+
+	terminationLog string
+	duration       time.Duration
+
+	otlp exporter.OTLPConfig
+
+	// the params of the run and the labels of the benchmark
+	params = paramsFlag{}
+	labels = paramsFlag{}
 )
 
+// paramsFlag collects the name=value flags given more than once
+type paramsFlag map[string]string
+
+func (p paramsFlag) String() string {
+	return fmt.Sprint(map[string]string(p))
+}
+
+func (p paramsFlag) Set(value string) error {
+	name, v, ok := strings.Cut(value, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("expected name=value, got %s", value)
+	}
+	p[name] = v
+	return nil
+}
+
 func main() {
 	// parse flags
 	flag.StringVar(&benchType, "type", "", "benchmark type")
 	flag.StringVar(&file, "file", "", "log file")
 	flag.StringVar(&benchName, "bench", "", "benchmark name")
 	flag.StringVar(&jobName, "job", "", "job name")
+	flag.StringVar(&target, "target", "", "optional target id added as label to all metrics")
+	flag.BoolVar(&dumpFile, "dump-file", false, "print the scraped file when scraping finished, so it is kept with the container log")
 	flag.StringVar(&doneFile, "done-file", "", "optional marker file that tells the exporter to stop waiting")
+	flag.StringVar(&terminationLog, "termination-log", "", "optional file the summary of the result is written to, the termination message of the container")
+	flag.StringVar(&otlp.Endpoint, "otlp-endpoint", "", "optional host:port of an OTLP collector the metrics are pushed to")
+	flag.StringVar(&otlp.Protocol, "otlp-protocol", exporter.OTLPProtocolGRPC, "the protocol of the OTLP collector, grpc or http")
+	flag.BoolVar(&otlp.Insecure, "otlp-insecure", false, "push the metrics to the OTLP collector without TLS")
+	flag.DurationVar(&otlp.Interval, "otlp-interval", 15*time.Second, "the interval between two pushes to the OTLP collector")
+	flag.DurationVar(&duration, "duration", 0, "optional duration of the run including the warmup, the progress reports the percent complete of it")
+	flag.Var(params, "param", "a name=value param of the run added to the run info metric, can be given more than once")
+	flag.Var(labels, "label", "a name=value label of the benchmark added to the run info metric, can be given more than once")
 	flag.Parse()
 
 	quit := make(chan struct{}, 1)
 
 	r := gin.Default()
 	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	exporter.RegisterAPI(r, duration)
 
 	go r.Run(":9187")
 
+	if target != "" {
+		prometheus.DefaultRegisterer = prometheus.WrapRegistererWith(prometheus.Labels{"target": target}, prometheus.DefaultRegisterer)
+	}
 	exporter.InitMetrics()
 	exporter.Register()
-	exporter.Scrape(benchType, file, benchName, jobName, doneFile, quit)
+	for name, value := range labels {
+		params[exporter.LabelParamPrefix+name] = value
+	}
+	prometheus.MustRegister(exporter.NewRunInfo(benchName, jobName, benchType, params))
+
+	shutdownOTLP := func(context.Context) error { return nil }
+	if otlp.Endpoint != "" {
+		res := exporter.OTLPResource{Benchmark: benchName, Job: jobName, Kind: benchType, Target: target, Params: params}
+		shutdown, err := exporter.StartOTLP(context.Background(), otlp, res, prometheus.DefaultGatherer)
+		if err != nil {
+			klog.Errorf("failed to start the otlp exporter: %v", err)
+		} else {
+			shutdownOTLP = shutdown
+		}
+	}
+
+	result := exporter.Scrape(benchType, file, benchName, jobName, doneFile, quit)
 
 	// get signal, exit
 	<-quit
 
+	if result != nil && terminationLog != "" {
+		writeTerminationLog(result)
+	}
+
+	if dumpFile {
+		exporter.DumpFile(file)
+		exporter.DumpFile(filepath.Join(filepath.Dir(file), constants.ResultFileName))
+	}
+
 	// wait prometheus to collect data
 	time.Sleep(30 * time.Second)
+
+	// push the final metrics
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := shutdownOTLP(ctx); err != nil {
+		klog.Errorf("failed to push the metrics to %s: %v", otlp.Endpoint, err)
+	}
+}
+
+// writeTerminationLog writes the summary of the result to the termination
+// log, the controller reads it from the status of the pod
+func writeTerminationLog(result *exporter.Result) {
+	data, err := exporter.TerminationMessage(result)
+	if err != nil {
+		klog.Errorf("failed to marshal the termination message: %v", err)
+		return
+	}
+	if err := os.WriteFile(terminationLog, data, 0644); err != nil {
+		klog.Errorf("failed to write the termination log %s: %v", terminationLog, err)
+	}
 }
@@ -15,6 +15,13 @@ func main() {
 	rootCmd.AddCommand(tools.NewRedisCmd())
 	rootCmd.AddCommand(tools.NewElasticsearchCmd())
 	rootCmd.AddCommand(tools.NewGaussdbCmd())
+	rootCmd.AddCommand(tools.NewTiDBCmd())
+	rootCmd.AddCommand(tools.NewMssqlCmd())
+	rootCmd.AddCommand(tools.NewOceanBaseOracleCmd())
+	rootCmd.AddCommand(tools.NewSQLCmd())
+	rootCmd.AddCommand(tools.NewHTTPCmd())
+	rootCmd.AddCommand(tools.NewConcatCmd())
+	rootCmd.AddCommand(tools.NewS3Cmd())
 
 	if err := rootCmd.Execute(); err != nil {
 		panic(err)
@@ -24,6 +24,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -31,8 +32,8 @@ import (
 
 	benchmarkv1alpha1 "github.com/apecloud/kubebench/api/v1alpha1"
 	intctrlutil "github.com/apecloud/kubebench/internal/controllerutil"
-	"github.com/apecloud/kubebench/internal/exporter"
 	"github.com/apecloud/kubebench/internal/utils"
+	"github.com/apecloud/kubebench/pkg/constants"
 )
 
 // EsrallyReconciler reconciles an Esrally object.
@@ -40,6 +41,7 @@ type EsrallyReconciler struct {
 	client.Client
 	Scheme     *runtime.Scheme
 	RestConfig *rest.Config
+	Recorder   record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=benchmark.apecloud.io,resources=esrallies,verbs=get;list;watch;create;update;patch;delete
@@ -48,6 +50,8 @@ type EsrallyReconciler struct {
 
 func (r *EsrallyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	l := log.FromContext(ctx)
+	// the summary and the archive of a finished job share its logs
+	ctx = utils.WithPodLogs(ctx)
 
 	var esrally benchmarkv1alpha1.Esrally
 	if err := r.Get(ctx, req.NamespacedName, &esrally); err != nil {
@@ -55,11 +59,57 @@ func (r *EsrallyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	}
 	old := esrally.DeepCopy()
 
+	// record the logs archived to a pvc once they are copied into the claim
+	archiving, err := utils.SyncArchivedLogs(r.Client, ctx, &esrally, esrally.Spec.LogArchive, &esrally.Status.Logs)
+	if err != nil {
+		return intctrlutil.RequeueWithError(err, l, "unable to sync the archived logs")
+	}
+
 	if esrally.Status.Phase == benchmarkv1alpha1.Completed || esrally.Status.Phase == benchmarkv1alpha1.Failed {
-		return intctrlutil.Reconciled()
+		if err := r.Status().Patch(ctx, &esrally, client.MergeFrom(old)); err != nil {
+			l.Error(err, "failed to patch esrally status")
+			return intctrlutil.RequeueWithError(err, l, "failed to patch esrally status")
+		}
+		if archiving {
+			return intctrlutil.RequeueAfter(intctrlutil.RequeueDuration)
+		}
+		// collect the jobs once the ttl expired
+		requeueAfter, err := utils.CollectFinished(r.Client, ctx, &esrally, &esrally.Spec.BenchCommon, esrally.Status.CompletionTimestamp)
+		if err != nil {
+			return intctrlutil.RequeueWithError(err, l, "failed to collect the finished esrally")
+		}
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
 	}
 
-	jobs := NewEsrallyJobs(&esrally)
+	if len(esrally.Spec.Targets) > 0 {
+		phase, succeeded, err := utils.ReconcileTargets(r.Client, ctx, r.Scheme, &esrally, esrally.Spec.Targets, esrally.Spec.TargetsPolicy, &esrally.Status.Targets)
+		if err != nil {
+			l.Error(err, "failed to reconcile the targets", "esrally", esrally.Name)
+			return intctrlutil.RequeueWithError(err, l, "failed to reconcile the targets")
+		}
+		esrally.Status.Phase = phase
+		esrally.Status.Succeeded = succeeded
+		esrally.Status.Total = len(esrally.Spec.Targets)
+		esrally.Status.Completions = fmt.Sprintf("%d/%d", esrally.Status.Succeeded, esrally.Status.Total)
+		if phase == benchmarkv1alpha1.Completed || phase == benchmarkv1alpha1.Failed {
+			esrally.Status.CompletionTimestamp = &metav1.Time{Time: time.Now()}
+		}
+		utils.RecordPhaseTransition(r.Recorder, &esrally, old.Status.Phase, esrally.Status.Phase)
+		if err := r.Status().Patch(ctx, &esrally, client.MergeFrom(old)); err != nil {
+			l.Error(err, "failed to patch esrally status")
+			return intctrlutil.RequeueWithError(err, l, "failed to patch esrally status")
+		}
+		return intctrlutil.RequeueAfter(intctrlutil.RequeueDuration)
+	}
+
+	resolved, err := utils.ResolveTarget(r.Client, ctx, esrally.Namespace, esrally.Spec.TargetRef, esrally.Status.Target, &esrally.Spec.Target)
+	if err != nil {
+		l.Error(err, "failed to resolve the target", "esrally", esrally.Name)
+		return intctrlutil.RequeueWithError(err, l, "failed to resolve the target")
+	}
+	esrally.Status.Target = resolved
+
+	jobs := utils.SetJobDeadlines(NewEsrallyJobs(&esrally), esrally.Spec.Timeouts)
 
 	if esrally.Status.Phase == "" {
 		l.Info("start esrally", "esrally", esrally.Name)
@@ -70,6 +120,10 @@ func (r *EsrallyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	if esrally.Status.Succeeded >= esrally.Status.Total {
 		l.Info("esrally complete", "esrally", esrally.Name)
 		esrally.Status.Phase = benchmarkv1alpha1.Completed
+		if esrally.Status.Reason != "" {
+			// a job failed and the afterAll hooks ran after it
+			esrally.Status.Phase = benchmarkv1alpha1.Failed
+		}
 		esrally.Status.CompletionTimestamp = &metav1.Time{Time: time.Now()}
 	} else {
 		job := jobs[esrally.Status.Succeeded]
@@ -92,6 +146,7 @@ func (r *EsrallyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 			}
 
 			l.Info("created job", "job", job.Name)
+			utils.RecordJobCreated(r.Recorder, &esrally, job, esrally.Spec.Target.Password)
 			return intctrlutil.RequeueAfter(intctrlutil.RequeueDuration)
 		}
 
@@ -100,25 +155,65 @@ func (r *EsrallyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 			l.Error(err, "failed to get job status", "job", job.Name)
 			return intctrlutil.RequeueWithError(err, l, "failed to get job status")
 		}
+		// the nodes cordoned by the faults are schedulable again once the run job finished
+		if err := utils.UncordonNodes(r.Client, ctx, esrally.Spec.Faults, job, status); err != nil {
+			return intctrlutil.RequeueWithError(err, l, "unable to uncordon the nodes")
+		}
 
 		if status.Succeeded > 0 {
 			l.Info("job completed", "job", job.Name)
+			utils.RecordJobFinished(r.Client, ctx, r.Recorder, &esrally, job, true)
+			utils.ObserveJobFinished(r.Client, ctx, constants.EsrallyType, jobs, esrally.Status.Succeeded, true)
 			esrally.Status.Succeeded++
-			if err := utils.LogJobPodToCond(r.Client, r.RestConfig, ctx, job.Name, esrally.Namespace, &esrally.Status.Conditions, ParseEsrally); err != nil {
+			if err := utils.RecordJobSummary(r.Client, r.RestConfig, ctx, job.Name, esrally.Namespace, &esrally.Status.Conditions, constants.EsrallyType); err != nil {
 				return intctrlutil.RequeueWithError(err, l, "unable to record the log")
 			}
+			if err := utils.ArchiveJobLogs(r.Client, r.RestConfig, ctx, r.Scheme, &esrally, esrally.Spec.LogArchive, job.Name, &esrally.Status.Logs); err != nil {
+				return intctrlutil.RequeueWithError(err, l, "unable to archive the log")
+			}
+		} else if step := utils.TimedOutStep(&esrally, esrally.Spec.Timeouts, job, status); step != "" {
+			if esrally.Status.TimedOutStep != "" {
+				step = esrally.Status.TimedOutStep
+			}
+			esrally.Status.TimedOutStep = step
+			done, err := utils.StopTimedOutJob(r.Client, ctx, r.Recorder, &esrally, constants.EsrallyType, job, step)
+			if err != nil {
+				return intctrlutil.RequeueWithError(err, l, "unable to stop the job")
+			}
+			if done {
+				l.Info("job timed out", "job", job.Name, "step", step)
+				esrally.Status.Phase = utils.FailJob(jobs, &esrally.Status.Succeeded)
+				esrally.Status.Reason = utils.TimedOutReason
+				if err := utils.LogJobPodToCond(r.Client, r.RestConfig, ctx, job.Name, esrally.Namespace, &esrally.Status.Conditions, nil); err != nil {
+					return intctrlutil.RequeueWithError(err, l, "unable to record the log")
+				}
+				if err := utils.ArchiveJobLogs(r.Client, r.RestConfig, ctx, r.Scheme, &esrally, esrally.Spec.LogArchive, job.Name, &esrally.Status.Logs); err != nil {
+					return intctrlutil.RequeueWithError(err, l, "unable to archive the log")
+				}
+			}
 		} else if status.Failed > 0 {
 			l.Info("job failed", "job", job.Name)
-			esrally.Status.Phase = benchmarkv1alpha1.Failed
+			utils.RecordJobFinished(r.Client, ctx, r.Recorder, &esrally, job, false)
+			utils.ObserveJobFinished(r.Client, ctx, constants.EsrallyType, jobs, esrally.Status.Succeeded, false)
+			if esrally.Status.Reason == "" {
+				esrally.Status.Reason = utils.JobFailedReason
+			}
+			esrally.Status.Phase = utils.FailJob(jobs, &esrally.Status.Succeeded)
 			if err := utils.LogJobPodToCond(r.Client, r.RestConfig, ctx, job.Name, esrally.Namespace, &esrally.Status.Conditions, nil); err != nil {
 				return intctrlutil.RequeueWithError(err, l, "unable to record the log")
 			}
+			if err := utils.ArchiveJobLogs(r.Client, r.RestConfig, ctx, r.Scheme, &esrally, esrally.Spec.LogArchive, job.Name, &esrally.Status.Logs); err != nil {
+				return intctrlutil.RequeueWithError(err, l, "unable to archive the log")
+			}
 		} else {
 			l.Info("job running", "job", job.Name)
+			utils.RecordJobStarted(r.Client, ctx, r.Recorder, &esrally, job.Name, job.Namespace)
+			utils.InjectFaults(r.Client, ctx, r.Scheme, &esrally, esrally.Spec.Faults, job, status, &esrally.Status.Faults)
 		}
 	}
 
 	esrally.Status.Completions = fmt.Sprintf("%d/%d", esrally.Status.Succeeded, esrally.Status.Total)
+	utils.RecordPhaseTransition(r.Recorder, &esrally, old.Status.Phase, esrally.Status.Phase)
 	if err := r.Status().Patch(ctx, &esrally, client.MergeFrom(old)); err != nil {
 		l.Error(err, "failed to patch esrally status")
 		return intctrlutil.RequeueWithError(err, l, "failed to patch esrally status")
@@ -132,7 +227,3 @@ func (r *EsrallyReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		For(&benchmarkv1alpha1.Esrally{}).
 		Complete(r)
 }
-
-func ParseEsrally(msg string) string {
-	return exporter.SummarizeEsrallyCSV(msg, 12)
-}
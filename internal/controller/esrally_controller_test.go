@@ -3,14 +3,17 @@ package controller
 import (
 	"strings"
 	"testing"
+
+	"github.com/apecloud/kubebench/internal/exporter"
+	"github.com/apecloud/kubebench/pkg/constants"
 )
 
-func TestParseEsrallyReturnsBoundedSummaryWithoutRawLogs(t *testing.T) {
+func TestEsrallySummaryIsBoundedWithoutRawLogs(t *testing.T) {
 	msg := `esrally race finished
 basic_auth_password:'top-secret'
 api_key:'abc123'`
 
-	summary := ParseEsrally(msg)
+	summary := exporter.SummaryFunc(constants.EsrallyType)(msg)
 	if !strings.Contains(summary, "No numeric Rally CSV summary was found.") {
 		t.Fatalf("expected bounded fallback summary, got %q", summary)
 	}
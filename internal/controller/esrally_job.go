@@ -3,7 +3,6 @@ package controller
 import (
 	"encoding/json"
 	"fmt"
-	"strconv"
 	"strings"
 
 	batchv1 "k8s.io/api/batch/v1"
@@ -21,17 +20,17 @@ const (
 	esrallyGeneratedTrackPath = "/tmp/kubebench-esrally-track"
 	esrallyDocumentsFile      = esrallyGeneratedTrackPath + "/documents.json"
 	esrallyDefaultOnError     = "abort"
-	esrallyReportFormat       = "csv"
-	esrallyReportFile         = "/var/log/esrally-report.csv"
+	esrallyCSVReportFile      = "/var/log/esrally-report.csv"
+	esrallyJSONReportFile     = "/var/log/esrally-report.json"
 	esrallyDefaultIndex       = "kubebench"
 	esrallyDefaultDocs        = 10000
 	esrallyTargetIndexParam   = "target_index"
 	esrallyTargetVersionParam = "target_version"
 	esrallyScriptDir          = "/usr/local/share/kubebench/esrally"
-	esrallyCleanupScriptPath  = esrallyScriptDir + "/cleanup.py"
-	esrallyPrepareScriptPath  = esrallyScriptDir + "/prepare.py"
 	esrallyGenerateScriptPath = esrallyScriptDir + "/generate_track.py"
 	esrallyRunScriptPath      = esrallyScriptDir + "/run.sh"
+	esrallyIndexConfigVolume  = "index-config"
+	esrallyIndexConfigPath    = "/etc/kubebench/esrally-index"
 )
 
 func NewEsrallyJobs(cr *v1alpha1.Esrally) []*batchv1.Job {
@@ -62,13 +61,21 @@ func newEsrallyWorkJobs(cr *v1alpha1.Esrally) []*batchv1.Job {
 	step := esrallyStep(cr)
 
 	if step == constants.CleanupStep || step == constants.AllStep {
-		jobs = append(jobs, NewEsrallyCleanupJobs(cr)...)
+		jobs = append(jobs, utils.MarkStepJobs(NewEsrallyCleanupJobs(cr), constants.CleanupStep)...)
 	}
+	// the generated track deletes and creates the index itself, so prepare
+	// has no job of its own and only runs its hooks
 	if step == constants.PrepareStep || step == constants.AllStep {
-		jobs = append(jobs, NewEsrallyPrepareJobs(cr)...)
+		jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.BeforePrepareHook, cr.Spec.Hooks, &cr.Spec.Target)...)
+		jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.AfterPrepareHook, cr.Spec.Hooks, &cr.Spec.Target)...)
 	}
 	if step == constants.RunStep || step == constants.AllStep {
-		jobs = append(jobs, NewEsrallyRunJobs(cr)...)
+		jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.BeforeRunHook, cr.Spec.Hooks, &cr.Spec.Target)...)
+		jobs = append(jobs, utils.MarkRunJobs(NewEsrallyRunJobs(cr))...)
+		jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.AfterRunHook, cr.Spec.Hooks, &cr.Spec.Target)...)
+	}
+	if len(jobs) > 0 {
+		jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.AfterAllHook, cr.Spec.Hooks, &cr.Spec.Target)...)
 	}
 
 	return jobs
@@ -78,39 +85,19 @@ func newEsrallyPreCheckJob(cr *v1alpha1.Esrally) *batchv1.Job {
 	return utils.NewPreCheckJob(cr.Name, cr.Namespace, constants.ElasticsearchDriver, &cr.Spec.Target)
 }
 
+// NewEsrallyCleanupJobs deletes the target index with the tools image, the
+// target version is validated by the API server so an unsupported version
+// never loses the index
 func NewEsrallyCleanupJobs(cr *v1alpha1.Esrally) []*batchv1.Job {
 	job := utils.JobTemplate(fmt.Sprintf("%s-cleanup", cr.Name), cr.Namespace)
 	job.Spec.Template.Spec.Containers = append(
 		job.Spec.Template.Spec.Containers,
 		corev1.Container{
 			Name:            constants.ContainerName,
-			Image:           constants.GetBenchmarkImage(constants.KubebenchEnvEsrally),
-			ImagePullPolicy: corev1.PullIfNotPresent,
-			Command:         []string{"python3"},
-			Args:            []string{esrallyCleanupScriptPath},
-			Env:             esrallyGeneratedDataEnv(cr),
-			VolumeMounts: []corev1.VolumeMount{
-				{Name: "log", MountPath: "/var/log"},
-			},
-		},
-	)
-	return []*batchv1.Job{job}
-}
-
-func NewEsrallyPrepareJobs(cr *v1alpha1.Esrally) []*batchv1.Job {
-	job := utils.JobTemplate(fmt.Sprintf("%s-prepare", cr.Name), cr.Namespace)
-	job.Spec.Template.Spec.Containers = append(
-		job.Spec.Template.Spec.Containers,
-		corev1.Container{
-			Name:            constants.ContainerName,
-			Image:           constants.GetBenchmarkImage(constants.KubebenchEnvEsrally),
+			Image:           constants.GetBenchmarkImage(constants.KubebenchTools),
 			ImagePullPolicy: corev1.PullIfNotPresent,
-			Command:         []string{"python3"},
-			Args:            []string{esrallyPrepareScriptPath},
-			Env:             esrallyGeneratedDataEnv(cr),
-			VolumeMounts: []corev1.VolumeMount{
-				{Name: "log", MountPath: "/var/log"},
-			},
+			Command:         []string{"/tools"},
+			Args:            esrallyToolsArgs(cr, "delete-index"),
 		},
 	)
 	return []*batchv1.Job{job}
@@ -134,13 +121,16 @@ func NewEsrallyRunJobs(cr *v1alpha1.Esrally) []*batchv1.Job {
 		{Name: "CLIENT_OPTIONS", Value: esrallyClientOptions(cr)},
 		{Name: "ON_ERROR", Value: esrallyOnError(cr)},
 		{Name: "TELEMETRY", Value: strings.Join(esrallyTelemetry(cr), ",")},
-		{Name: "REPORT_FORMAT", Value: esrallyReportFormat},
-		{Name: "REPORT_FILE", Value: esrallyReportFile},
+		{Name: "REPORT_FORMAT", Value: esrallyReportFormat(cr)},
+		{Name: "REPORT_FILE", Value: esrallyReportFile(cr)},
 		{Name: "ESRALLY_LOG_FILE", Value: esrallyLogFile},
 		{Name: "ESRALLY_EXIT_FILE", Value: esrallyExitFile},
 		{Name: "GENERATE_TRACK_SCRIPT", Value: esrallyGenerateScriptPath},
 		{Name: "EXTRA_ARGS", Value: strings.Join(cr.Spec.ExtraArgs, " ")},
 	}
+	if cr.Spec.IndexConfigMap != "" {
+		env = append(env, corev1.EnvVar{Name: "INDEX_CONFIG_DIR", Value: esrallyIndexConfigPath})
+	}
 
 	job.Spec.Template.Spec.Containers = append(
 		job.Spec.Template.Spec.Containers,
@@ -160,34 +150,65 @@ func NewEsrallyRunJobs(cr *v1alpha1.Esrally) []*batchv1.Job {
 
 	job.Spec.Template.Spec.Containers = append(
 		job.Spec.Template.Spec.Containers,
-		corev1.Container{
-			Name:            "metrics",
-			Image:           constants.GetBenchmarkImage(constants.KubebenchExporter),
-			ImagePullPolicy: corev1.PullIfNotPresent,
-			Ports: []corev1.ContainerPort{
-				{
-					ContainerPort: 9187,
-					Name:          "http-metrics",
-					Protocol:      corev1.ProtocolTCP,
-				},
-			},
-			Command: []string{"/exporter"},
-			Args: []string{
-				"-type", constants.EsrallyType,
-				"-file", esrallyReportFile,
-				"-bench", cr.Name,
-				"-job", jobName,
-				"-done-file", esrallyExitFile,
-			},
-			VolumeMounts: []corev1.VolumeMount{
-				{Name: "log", MountPath: "/var/log"},
-			},
-		},
+		utils.NewExporterContainer(constants.EsrallyType, esrallyReportFile(cr), cr.Name, jobName,
+			append(append([]string{"-done-file", esrallyExitFile}, utils.ExporterArgs(cr.Labels, &cr.Spec.BenchCommon)...),
+				utils.ExporterParamArgs(map[string]string{
+					"workload":     esrallyWorkload(cr),
+					"data_profile": esrallyDataProfile(cr),
+				})...)...),
 	)
 
+	addEsrallyIndexConfigVolume(cr, job)
+
 	return []*batchv1.Job{job}
 }
 
+// esrallyToolsArgs returns the tools arguments running an elasticsearch index
+// command against the target index
+func esrallyToolsArgs(cr *v1alpha1.Esrally, command string) []string {
+	args := []string{"elasticsearch", command, esrallyIndexName(cr),
+		"--user", cr.Spec.Target.User,
+		"--password", cr.Spec.Target.Password,
+		"--host", cr.Spec.Target.Host,
+		"--port", fmt.Sprintf("%d", cr.Spec.Target.Port),
+		"--scheme", esrallyTargetScheme(cr),
+	}
+	if cr.Spec.Target.TLS {
+		args = append(args, "--insecure-skip-verify")
+	}
+	return args
+}
+
+// addEsrallyIndexConfigVolume mounts the index settings and mappings into the
+// first container of the job
+func addEsrallyIndexConfigVolume(cr *v1alpha1.Esrally, job *batchv1.Job) {
+	if cr.Spec.IndexConfigMap == "" {
+		return
+	}
+
+	optional := true
+	job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, corev1.Volume{
+		Name: esrallyIndexConfigVolume,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: cr.Spec.IndexConfigMap},
+				Items: []corev1.KeyToPath{
+					{Key: "settings.json", Path: "settings.json"},
+					{Key: "mappings.json", Path: "mappings.json"},
+				},
+				// both keys are optional
+				Optional: &optional,
+			},
+		},
+	})
+	container := &job.Spec.Template.Spec.Containers[0]
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      esrallyIndexConfigVolume,
+		MountPath: esrallyIndexConfigPath,
+		ReadOnly:  true,
+	})
+}
+
 func addEsrallyHomeVolume(job *batchv1.Job) {
 	job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, corev1.Volume{
 		Name: "rally-home",
@@ -197,21 +218,6 @@ func addEsrallyHomeVolume(job *batchv1.Job) {
 	})
 }
 
-func esrallyGeneratedDataEnv(cr *v1alpha1.Esrally) []corev1.EnvVar {
-	return []corev1.EnvVar{
-		{Name: "TARGET_URL", Value: esrallyTargetURL(cr)},
-		{Name: "INDEX_NAME", Value: esrallyIndexName(cr)},
-		{Name: "DATA_PROFILE", Value: esrallyDataProfile(cr)},
-		{Name: "DOCUMENT_COUNT", Value: fmt.Sprintf("%d", esrallyDocumentCount(cr))},
-		{Name: "WORKLOAD", Value: esrallyWorkload(cr)},
-		{Name: "TARGET_VERSION", Value: esrallyTargetVersion(cr)},
-		{Name: "ES_USERNAME", Value: cr.Spec.Target.User},
-		{Name: "ES_PASSWORD", Value: cr.Spec.Target.Password},
-		{Name: "ES_INSECURE_SKIP_VERIFY", Value: strconv.FormatBool(cr.Spec.Target.TLS)},
-		{Name: "ESRALLY_LOG_FILE", Value: esrallyLogFile},
-	}
-}
-
 func esrallyStep(cr *v1alpha1.Esrally) string {
 	if cr.Spec.Step != "" {
 		return cr.Spec.Step
@@ -240,6 +246,22 @@ func esrallyWorkload(cr *v1alpha1.Esrally) string {
 	return constants.EsrallyWorkloadAll
 }
 
+func esrallyReportFormat(cr *v1alpha1.Esrally) string {
+	if cr.Spec.ReportFormat != "" {
+		return cr.Spec.ReportFormat
+	}
+	return constants.EsrallyReportFormatCSV
+}
+
+// esrallyReportFile returns the report shared with the exporter, the run
+// script writes the results of the race there for the json format
+func esrallyReportFile(cr *v1alpha1.Esrally) string {
+	if esrallyReportFormat(cr) == constants.EsrallyReportFormatJSON {
+		return esrallyJSONReportFile
+	}
+	return esrallyCSVReportFile
+}
+
 func esrallyIndexName(cr *v1alpha1.Esrally) string {
 	if cr.Spec.Target.Database != "" {
 		return cr.Spec.Target.Database
@@ -247,10 +269,6 @@ func esrallyIndexName(cr *v1alpha1.Esrally) string {
 	return esrallyDefaultIndex
 }
 
-func esrallyTargetURL(cr *v1alpha1.Esrally) string {
-	return fmt.Sprintf("%s://%s:%d", esrallyTargetScheme(cr), cr.Spec.Target.Host, cr.Spec.Target.Port)
-}
-
 func esrallyTargetHosts(cr *v1alpha1.Esrally) string {
 	return fmt.Sprintf("%s:%d", cr.Spec.Target.Host, cr.Spec.Target.Port)
 }
@@ -21,7 +21,7 @@ func TestNewEsrallyJobsDefaultGeneratedDataWorkflow(t *testing.T) {
 	cr.Spec.Target.Password = "secret"
 
 	jobs := NewEsrallyJobs(cr)
-	wantNames := []string{"rally-precheck", "rally-cleanup", "rally-prepare", "rally-run"}
+	wantNames := []string{"rally-precheck", "rally-cleanup", "rally-run"}
 	if got := jobNames(jobs); strings.Join(got, ",") != strings.Join(wantNames, ",") {
 		t.Fatalf("expected jobs %v, got %v", wantNames, got)
 	}
@@ -29,14 +29,14 @@ func TestNewEsrallyJobsDefaultGeneratedDataWorkflow(t *testing.T) {
 		t.Fatalf("unexpected precheck args: %#v", got)
 	}
 
-	runJob := jobs[3]
+	runJob := jobs[2]
 	if runJob.Labels[constants.KubeBenchTypeLabel] != constants.EsrallyType {
 		t.Fatalf("missing esrally label: %#v", runJob.Labels)
 	}
 	if len(runJob.Spec.Template.Spec.Containers) != 2 {
 		t.Fatalf("expected workload and metrics containers, got %d", len(runJob.Spec.Template.Spec.Containers))
 	}
-	if got := metricsContainerArg(runJob, "-file"); got != esrallyReportFile {
+	if got := metricsContainerArg(runJob, "-file"); got != esrallyCSVReportFile {
 		t.Fatalf("expected default exporter report file, got %s", got)
 	}
 
@@ -63,10 +63,10 @@ func TestNewEsrallyRunJobsDefaultsMetricsToCSVSharedReport(t *testing.T) {
 	if len(job.Spec.Template.Spec.Containers) != 2 {
 		t.Fatalf("expected metrics container by default, got %d containers", len(job.Spec.Template.Spec.Containers))
 	}
-	if got := metricsContainerArg(job, "-file"); got != esrallyReportFile {
+	if got := metricsContainerArg(job, "-file"); got != esrallyCSVReportFile {
 		t.Fatalf("expected default report file, got %s", got)
 	}
-	if got := envValue(job, "REPORT_FORMAT"); got != esrallyReportFormat {
+	if got := envValue(job, "REPORT_FORMAT"); got != constants.EsrallyReportFormatCSV {
 		t.Fatalf("expected default report format env, got %s", got)
 	}
 	if got := envValue(job, "GENERATED_TRACK_PATH"); got != esrallyGeneratedTrackPath {
@@ -95,15 +95,14 @@ func TestNewEsrallyJobsUsesHTTPSTargetOptions(t *testing.T) {
 		t.Fatalf("expected https precheck args, got %#v", precheckArgs)
 	}
 
-	cleanupJob := jobs[1]
-	if got := envValue(cleanupJob, "TARGET_URL"); got != "https://es.default.svc:9200" {
-		t.Fatalf("expected https cleanup target URL, got %q", got)
-	}
-	if got := envValue(cleanupJob, "ES_INSECURE_SKIP_VERIFY"); got != "true" {
-		t.Fatalf("expected cleanup to skip TLS verification, got %q", got)
+	for _, job := range jobs[1:2] {
+		args := job.Spec.Template.Spec.Containers[0].Args
+		if !containsAll(args, []string{"--scheme", "https", "--insecure-skip-verify", "--user", "elastic", "--password", "secret"}) {
+			t.Fatalf("expected https args for %s, got %#v", job.Name, args)
+		}
 	}
 
-	runJob := jobs[3]
+	runJob := jobs[2]
 	options := envValue(runJob, "CLIENT_OPTIONS")
 	for _, want := range []string{"use_ssl:true", "verify_certs:false", "basic_auth_user:'elastic'", "basic_auth_password:'secret'"} {
 		if !strings.Contains(options, want) {
@@ -158,7 +157,7 @@ func TestNewEsrallyJobsHonorsStepForGeneratedData(t *testing.T) {
 	}{
 		{
 			name: "default all",
-			want: []string{"rally-precheck", "rally-cleanup", "rally-prepare", "rally-run"},
+			want: []string{"rally-precheck", "rally-cleanup", "rally-run"},
 		},
 		{
 			name: "cleanup",
@@ -166,9 +165,10 @@ func TestNewEsrallyJobsHonorsStepForGeneratedData(t *testing.T) {
 			want: []string{"rally-precheck", "rally-cleanup"},
 		},
 		{
+			// the generated track creates the index in the run job
 			name: "prepare",
 			step: constants.PrepareStep,
-			want: []string{"rally-precheck", "rally-prepare"},
+			want: []string{},
 		},
 		{
 			name: "run",
@@ -190,58 +190,6 @@ func TestNewEsrallyJobsHonorsStepForGeneratedData(t *testing.T) {
 	}
 }
 
-func TestNewEsrallyPrepareJobsValidatesGeneratedDataEnvAndScript(t *testing.T) {
-	cr := newEsrallyTestCR()
-	cr.Spec.DataProfile = constants.EsrallyDataProfileGeonames
-	cr.Spec.DocumentCount = 1234
-	cr.Spec.Workload = constants.EsrallyWorkloadSearch
-	cr.Spec.Target.Database = "geonames-index"
-	cr.Spec.Target.User = "elastic"
-	cr.Spec.Target.Password = "secret"
-
-	job := NewEsrallyPrepareJobs(cr)[0]
-	if got := envValue(job, "TARGET_URL"); got != "http://es.default.svc:9200" {
-		t.Fatalf("expected target URL env, got %s", got)
-	}
-	if got := envValue(job, "INDEX_NAME"); got != "geonames-index" {
-		t.Fatalf("expected generated index env, got %s", got)
-	}
-	if got := envValue(job, "DATA_PROFILE"); got != constants.EsrallyDataProfileGeonames {
-		t.Fatalf("expected geonames data profile env, got %s", got)
-	}
-	if got := envValue(job, "DOCUMENT_COUNT"); got != "1234" {
-		t.Fatalf("expected document count env, got %s", got)
-	}
-	if got := envValue(job, "WORKLOAD"); got != constants.EsrallyWorkloadSearch {
-		t.Fatalf("expected workload env, got %s", got)
-	}
-	if got := envValue(job, "TARGET_VERSION"); got != "" {
-		t.Fatalf("expected empty target version env by default, got %s", got)
-	}
-
-	if got := job.Spec.Template.Spec.Containers[0].Args[0]; got != esrallyPrepareScriptPath {
-		t.Fatalf("expected prepare script path, got %s", got)
-	}
-	if got := strings.Join(job.Spec.Template.Spec.Containers[0].Command, " "); got != "python3" {
-		t.Fatalf("expected prepare to run python3 directly, got command %#v", job.Spec.Template.Spec.Containers[0].Command)
-	}
-
-	preparePython := scriptContent(t, "scripts/esrally/prepare.py")
-	for _, want := range []string{"Validated ESRally generated workload", "supported_workloads", "documentCount must be >= 1", "unsupported dataProfile", "unsupported workload", "targetVersion 6 or newer", "dense_vector requires Elasticsearch 7", "ESRALLY_LOG_FILE"} {
-		if !strings.Contains(preparePython, want) {
-			t.Fatalf("prepare python script missing %s:\n%s", want, preparePython)
-		}
-	}
-	for _, forbidden := range []string{"/_bulk", "create_index()", "_refresh", "urllib.request"} {
-		if strings.Contains(preparePython, forbidden) {
-			t.Fatalf("prepare script must only validate, found %s:\n%s", forbidden, preparePython)
-		}
-	}
-	if strings.Index(preparePython, "validate_target_version()") > strings.Index(preparePython, "Validated ESRally generated workload") {
-		t.Fatalf("expected targetVersion guard before success log:\n%s", preparePython)
-	}
-}
-
 func TestNewEsrallyJobScriptPaths(t *testing.T) {
 	cr := newEsrallyTestCR()
 
@@ -250,8 +198,7 @@ func TestNewEsrallyJobScriptPaths(t *testing.T) {
 		job  *batchv1.Job
 		want string
 	}{
-		{name: "cleanup", job: NewEsrallyCleanupJobs(cr)[0], want: esrallyCleanupScriptPath},
-		{name: "prepare", job: NewEsrallyPrepareJobs(cr)[0], want: esrallyPrepareScriptPath},
+		{name: "cleanup", job: NewEsrallyCleanupJobs(cr)[0], want: "elasticsearch"},
 		{name: "run", job: NewEsrallyRunJobs(cr)[0], want: "/bin/sh " + esrallyRunScriptPath},
 	}
 
@@ -313,20 +260,27 @@ func TestEsrallyDefaultsUseDerivedRallyImage(t *testing.T) {
 func TestNewEsrallyJobsUseScriptsBuiltIntoWorkloadImage(t *testing.T) {
 	cr := newEsrallyTestCR()
 
-	for _, job := range []*batchv1.Job{
-		NewEsrallyCleanupJobs(cr)[0],
-		NewEsrallyPrepareJobs(cr)[0],
-		NewEsrallyRunJobs(cr)[0],
-	} {
-		if len(job.Spec.Template.Spec.InitContainers) != 0 {
-			t.Fatalf("expected no script injection init containers, got %#v", job.Spec.Template.Spec.InitContainers)
-		}
-		if hasVolume(job, "esrally-scripts") {
-			t.Fatalf("expected scripts to come from workload image, got script volume: %#v", job.Spec.Template.Spec.Volumes)
-		}
-		if !strings.Contains(job.Spec.Template.Spec.Containers[0].Args[0], "/usr/local/share/kubebench/esrally/") {
-			t.Fatalf("expected workload image script path, got %#v", job.Spec.Template.Spec.Containers[0].Args)
-		}
+	// the API server validates the workload, cleanup only runs the tools image
+	cleanup := NewEsrallyCleanupJobs(cr)[0]
+	if len(cleanup.Spec.Template.Spec.InitContainers) != 0 {
+		t.Fatalf("expected no init containers in the cleanup job, got %#v", cleanup.Spec.Template.Spec.InitContainers)
+	}
+	if got := cleanup.Spec.Template.Spec.Containers[0].Image; got != constants.GetBenchmarkImage(constants.KubebenchTools) {
+		t.Fatalf("expected the cleanup job to run the tools image, got %s", got)
+	}
+
+	job := NewEsrallyRunJobs(cr)[0]
+	if len(job.Spec.Template.Spec.InitContainers) != 0 {
+		t.Fatalf("expected no init containers in the run job, got %#v", job.Spec.Template.Spec.InitContainers)
+	}
+	if hasVolume(job, "esrally-scripts") {
+		t.Fatalf("expected scripts to come from workload image, got script volume: %#v", job.Spec.Template.Spec.Volumes)
+	}
+	if !strings.Contains(job.Spec.Template.Spec.Containers[0].Args[0], "/usr/local/share/kubebench/esrally/") {
+		t.Fatalf("expected workload image script path, got %#v", job.Spec.Template.Spec.Containers[0].Args)
+	}
+	if _, err := os.Stat("../../scripts/esrally/prepare.py"); !os.IsNotExist(err) {
+		t.Fatalf("expected no prepare script in the workload image, got %v", err)
 	}
 }
 
@@ -362,37 +316,48 @@ func TestNewEsrallyGenerateTrackScriptSupportsExpandedGeneratedProfiles(t *testi
 	}
 }
 
-func TestNewEsrallyPrepareJobsCarriesTargetVersion(t *testing.T) {
+func TestNewEsrallyCleanupJobsDeletesGeneratedIndex(t *testing.T) {
 	cr := newEsrallyTestCR()
-	cr.Spec.TargetVersion = "8.12.2"
+	cr.Spec.Target.Database = "logs-index"
 
-	job := NewEsrallyPrepareJobs(cr)[0]
-	if got := envValue(job, "TARGET_VERSION"); got != "8.12.2" {
-		t.Fatalf("expected target version env, got %s", got)
+	job := NewEsrallyCleanupJobs(cr)[0]
+	container := job.Spec.Template.Spec.Containers[0]
+	if got := strings.Join(container.Command, " "); got != "/tools" {
+		t.Fatalf("expected cleanup to run the tools binary, got command %#v", container.Command)
+	}
+	if !containsAll(container.Args, []string{"elasticsearch", "delete-index", "logs-index", "--host", "es.default.svc", "--port", "9200", "--scheme", "http"}) {
+		t.Fatalf("unexpected cleanup args: %#v", container.Args)
+	}
+	if container.Image != constants.GetBenchmarkImage(constants.KubebenchTools) {
+		t.Fatalf("expected tools image, got %s", container.Image)
 	}
 }
 
-func TestNewEsrallyCleanupJobsDeletesGeneratedIndex(t *testing.T) {
+func TestNewEsrallyJobsMountIndexConfigMap(t *testing.T) {
 	cr := newEsrallyTestCR()
-	cr.Spec.Target.Database = "logs-index"
+	cr.Spec.IndexConfigMap = "rally-index"
 
-	job := NewEsrallyCleanupJobs(cr)[0]
-	if got := envValue(job, "INDEX_NAME"); got != "logs-index" {
-		t.Fatalf("expected cleanup index env, got %s", got)
+	run := NewEsrallyRunJobs(cr)[0]
+	if got := envValue(run, "INDEX_CONFIG_DIR"); got != esrallyIndexConfigPath {
+		t.Fatalf("expected run to pass the index config dir, got %q", got)
 	}
-
-	cleanupPython := scriptContent(t, "scripts/esrally/cleanup.py")
-	for _, want := range []string{`method="DELETE"`, `f"{target_url}/{index_name}"`, "status in (200, 202, 404)", "targetVersion 6 or newer", "ESRALLY_LOG_FILE"} {
-		if !strings.Contains(cleanupPython, want) {
-			t.Fatalf("cleanup python missing %s:\n%s", want, cleanupPython)
-		}
+	if !hasVolume(run, esrallyIndexConfigVolume) {
+		t.Fatalf("expected index config volume in %s", run.Name)
 	}
-	container := job.Spec.Template.Spec.Containers[0]
-	if got := strings.Join(container.Command, " "); got != "python3" {
-		t.Fatalf("expected cleanup to run python3 directly, got command %#v", container.Command)
+	if !hasVolumeMount(run.Spec.Template.Spec.Containers[0].VolumeMounts, esrallyIndexConfigVolume, esrallyIndexConfigPath) {
+		t.Fatalf("expected index config mount in %s", run.Name)
+	}
+	for _, volume := range run.Spec.Template.Spec.Volumes {
+		if volume.Name == esrallyIndexConfigVolume && volume.ConfigMap.Name != "rally-index" {
+			t.Fatalf("unexpected config map %s", volume.ConfigMap.Name)
+		}
 	}
-	if strings.Index(cleanupPython, "validate_target_version()") > strings.Index(cleanupPython, "Deleting generated ESRally index") {
-		t.Fatalf("expected targetVersion guard before index deletion:\n%s", cleanupPython)
+
+	script := scriptContent(t, "scripts/esrally/generate_track.py")
+	for _, want := range []string{"INDEX_CONFIG_DIR", "settings", "mappings", "apply_index_config(body)"} {
+		if !strings.Contains(script, want) {
+			t.Fatalf("generate track script missing %s", want)
+		}
 	}
 }
 
@@ -525,11 +490,11 @@ func TestNewEsrallyRunJobsProductionOptions(t *testing.T) {
 				"DOCUMENTS_FILE":       esrallyDocumentsFile,
 				"WORKLOAD":             constants.EsrallyWorkloadAll,
 				"ON_ERROR":             esrallyDefaultOnError,
-				"REPORT_FORMAT":        esrallyReportFormat,
-				"REPORT_FILE":          esrallyReportFile,
+				"REPORT_FORMAT":        constants.EsrallyReportFormatCSV,
+				"REPORT_FILE":          esrallyCSVReportFile,
 			},
 			wantScriptParts: []string{"--pipeline=benchmark-only", "--track-path", "--offline", "--report-format", "--report-file"},
-			wantMetricFile:  esrallyReportFile,
+			wantMetricFile:  esrallyCSVReportFile,
 		},
 		{
 			name: "telemetry and extra args are wired through",
@@ -546,7 +511,20 @@ func TestNewEsrallyRunJobsProductionOptions(t *testing.T) {
 				"EXTRA_ARGS": "--kill-running-processes --enable-driver-profiling",
 			},
 			wantScriptParts: []string{"--telemetry", "$EXTRA_ARGS"},
-			wantMetricFile:  esrallyReportFile,
+			wantMetricFile:  esrallyCSVReportFile,
+		},
+		{
+			name: "json report reads the results of the race",
+			mutate: func(cr *benchmarkv1alpha1.Esrally) {
+				cr.Spec.ReportFormat = constants.EsrallyReportFormatJSON
+			},
+			wantContainers: 2,
+			wantEnv: map[string]string{
+				"REPORT_FORMAT": constants.EsrallyReportFormatJSON,
+				"REPORT_FILE":   esrallyJSONReportFile,
+			},
+			wantScriptParts: []string{"--race-id", "race.json", "Rally JSON report:"},
+			wantMetricFile:  esrallyJSONReportFile,
 		},
 	}
 
@@ -608,6 +586,8 @@ func TestNewEsrallyRunJobsSharesReportVolumeWithExporter(t *testing.T) {
 	}
 }
 
+// validateContainerJob wraps the validation init container in a job so the
+// container helpers can inspect it
 func containsAll(values []string, wants []string) bool {
 	seen := make(map[string]bool, len(values))
 	for _, value := range values {
@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	benchmarkv1alpha1 "github.com/apecloud/kubebench/api/v1alpha1"
+	"github.com/apecloud/kubebench/internal/utils"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+func TestJobParamsMasksFields(t *testing.T) {
+	target := newVerifyTestTarget(constants.MySqlDriver)
+	target.Password = "a"
+	cr := &benchmarkv1alpha1.Sysbench{
+		ObjectMeta: metav1.ObjectMeta{Name: "sb", Namespace: "default"},
+		Spec: benchmarkv1alpha1.SysbenchSpec{
+			Duration: 60,
+			Threads:  []int{4},
+			Types:    []string{"oltp_read_write"},
+			BenchCommon: benchmarkv1alpha1.BenchCommon{
+				Target: target,
+			},
+		},
+	}
+
+	params := utils.JobParams(NewSysbenchRunJobs(cr)[0], target.Password)
+	for _, want := range []string{"CONFIGS=mode:run,driver:mysql,host:db.default.svc,", ",user:root,password:******,db:kubebench,", "-c \"${CONFIGS}\""} {
+		if !strings.Contains(params, want) {
+			t.Fatalf("expected %q in the params: %s", want, params)
+		}
+	}
+
+	precheck := utils.NewPreCheckJob("sb", "default", constants.MySqlDriver, &target)
+	if params := utils.JobParams(precheck, target.Password); !strings.Contains(params, "--user root --password ****** --host db.default.svc") {
+		t.Fatalf("expected only the password to be masked: %s", params)
+	}
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	benchmarkv1alpha1 "github.com/apecloud/kubebench/api/v1alpha1"
+	"github.com/apecloud/kubebench/internal/utils"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+func TestExporterArgsOTLP(t *testing.T) {
+	bench := &benchmarkv1alpha1.BenchCommon{}
+	if args := utils.ExporterArgs(nil, bench); len(args) != 0 {
+		t.Fatalf("expected no args, got %v", args)
+	}
+
+	bench.OTLP = &benchmarkv1alpha1.OTLP{Endpoint: "otel-collector:4318", Protocol: "http", Insecure: true, IntervalSeconds: 5}
+	args := strings.Join(utils.ExporterArgs(nil, bench), " ")
+	if args != "-otlp-endpoint otel-collector:4318 -otlp-protocol http -otlp-insecure -otlp-interval 5s" {
+		t.Fatalf("unexpected args: %s", args)
+	}
+}
+
+func TestExporterArgsRunParams(t *testing.T) {
+	bench := &benchmarkv1alpha1.BenchCommon{Target: benchmarkv1alpha1.Target{Driver: constants.MySqlDriver, Host: "mysql.default"}}
+	labels := map[string]string{constants.KubeBenchTargetLabel: "mysql", "team": "db"}
+	args := strings.Join(utils.ExporterArgs(labels, bench), " ")
+	expected := "-target mysql -param driver=mysql -param target_host=mysql.default -label kubebench.apecloud.io/target=mysql -label team=db"
+	if args != expected {
+		t.Fatalf("expected %q, got %q", expected, args)
+	}
+
+	cr := &benchmarkv1alpha1.Sysbench{
+		ObjectMeta: metav1.ObjectMeta{Name: "sb", Namespace: "default"},
+		Spec: benchmarkv1alpha1.SysbenchSpec{
+			Threads:     []int{4, 8},
+			Types:       []string{"oltp_read_only", "oltp_write_only"},
+			BenchCommon: benchmarkv1alpha1.BenchCommon{Target: newVerifyTestTarget(constants.MySqlDriver)},
+		},
+	}
+	jobs := NewSysbenchRunJobs(cr)
+	args = strings.Join(jobs[3].Spec.Template.Spec.Containers[1].Args, " ")
+	if !strings.HasSuffix(args, "-param threads=8 -param type=oltp_write_only") {
+		t.Fatalf("expected the threads and the type of the job: %s", args)
+	}
+}
+
+func TestExporterDurationArgs(t *testing.T) {
+	cr := &benchmarkv1alpha1.Pgbench{
+		ObjectMeta: metav1.ObjectMeta{Name: "pg", Namespace: "default"},
+		Spec: benchmarkv1alpha1.PgbenchSpec{
+			Clients:     []int{4},
+			Duration:    60,
+			Warmup:      "10s",
+			BenchCommon: benchmarkv1alpha1.BenchCommon{Target: newVerifyTestTarget(constants.PostgreSqlDriver)},
+		},
+	}
+	args := strings.Join(NewPgbenchRunJobs(cr)[0].Spec.Template.Spec.Containers[1].Args, " ")
+	if !strings.HasSuffix(args, "-duration 70s") {
+		t.Fatalf("expected the duration of the warmup and the run: %s", args)
+	}
+
+	cr.Spec.Transactions = 1000
+	args = strings.Join(NewPgbenchRunJobs(cr)[0].Spec.Template.Spec.Containers[1].Args, " ")
+	if strings.Contains(args, "-duration") {
+		t.Fatalf("expected no duration for a run limited by transactions: %s", args)
+	}
+}
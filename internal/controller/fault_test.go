@@ -0,0 +1,36 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	benchmarkv1alpha1 "github.com/apecloud/kubebench/api/v1alpha1"
+	"github.com/apecloud/kubebench/internal/utils"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+func TestNewSysbenchJobsMarksRunJobs(t *testing.T) {
+	cr := &benchmarkv1alpha1.Sysbench{
+		ObjectMeta: metav1.ObjectMeta{Name: "sb", Namespace: "default"},
+		Spec: benchmarkv1alpha1.SysbenchSpec{
+			Threads: []int{4, 8},
+			Types:   []string{"oltp_read_write"},
+			BenchCommon: benchmarkv1alpha1.BenchCommon{
+				Step:   constants.AllStep,
+				Target: newVerifyTestTarget(constants.MySqlDriver),
+			},
+		},
+	}
+
+	runs := make([]string, 0)
+	for _, job := range NewSysbenchJobs(cr) {
+		if utils.IsRunJob(job) {
+			runs = append(runs, job.Name)
+		}
+	}
+	if strings.Join(runs, ",") != "sb-run-0,sb-run-1" {
+		t.Fatalf("expected only the run jobs to be marked, got %v", runs)
+	}
+}
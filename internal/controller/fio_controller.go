@@ -24,6 +24,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -32,6 +33,7 @@ import (
 	benchmarkv1alpha1 "github.com/apecloud/kubebench/api/v1alpha1"
 	intctrlutil "github.com/apecloud/kubebench/internal/controllerutil"
 	"github.com/apecloud/kubebench/internal/utils"
+	"github.com/apecloud/kubebench/pkg/constants"
 )
 
 // FioReconciler reconciles a Fio object
@@ -39,6 +41,7 @@ type FioReconciler struct {
 	client.Client
 	Scheme     *runtime.Scheme
 	RestConfig *rest.Config
+	Recorder   record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=benchmark.apecloud.io,resources=fios,verbs=get;list;watch;create;update;patch;delete
@@ -101,6 +104,7 @@ func (r *FioReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 
 			// wait for the job to be created
 			l.Info("created job", "job", job.Name)
+			utils.RecordJobCreated(r.Recorder, &fio, job)
 			return intctrlutil.RequeueAfter(intctrlutil.RequeueDuration)
 		}
 
@@ -113,6 +117,8 @@ func (r *FioReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 
 		if status.Succeeded > 0 {
 			l.Info("job completed", "job", job.Name)
+			utils.RecordJobFinished(r.Client, ctx, r.Recorder, &fio, job, true)
+			utils.ObserveJobFinished(r.Client, ctx, constants.FioType, jobs, fio.Status.Succeeded, true)
 			fio.Status.Succeeded++
 			// record the result
 			if err := utils.LogJobPodToCond(r.Client, r.RestConfig, ctx, job.Name, fio.Namespace, &fio.Status.Conditions, nil); err != nil {
@@ -120,13 +126,17 @@ func (r *FioReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 			}
 		} else if status.Failed > 0 {
 			l.Info("job failed", "job", job.Name)
+			utils.RecordJobFinished(r.Client, ctx, r.Recorder, &fio, job, false)
+			utils.ObserveJobFinished(r.Client, ctx, constants.FioType, jobs, fio.Status.Succeeded, false)
 			fio.Status.Phase = benchmarkv1alpha1.Failed
 		} else {
 			l.Info("job is running", "job", job.Name)
+			utils.RecordJobStarted(r.Client, ctx, r.Recorder, &fio, job.Name, job.Namespace)
 		}
 	}
 
 	fio.Status.Completions = fmt.Sprintf("%d/%d", fio.Status.Succeeded, fio.Status.Total)
+	utils.RecordPhaseTransition(r.Recorder, &fio, old.Status.Phase, fio.Status.Phase)
 	if err := r.Status().Patch(ctx, &fio, client.MergeFrom(old)); err != nil {
 		return intctrlutil.RequeueWithError(err, l, "unable to update fio status")
 	}
@@ -0,0 +1,197 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	benchmarkv1alpha1 "github.com/apecloud/kubebench/api/v1alpha1"
+	"github.com/apecloud/kubebench/internal/utils"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+func newHookTestHooks() *benchmarkv1alpha1.Hooks {
+	return &benchmarkv1alpha1.Hooks{
+		BeforePrepare: []benchmarkv1alpha1.Hook{
+			{Name: "snapshot", Container: &benchmarkv1alpha1.ContainerHook{Image: "bitnami/kubectl", Args: []string{"get", "pods"}}},
+		},
+		AfterPrepare: []benchmarkv1alpha1.Hook{
+			{Name: "analyze", SQL: &benchmarkv1alpha1.SQLHook{ConfigMap: "analyze", Transaction: "file"}},
+		},
+		BeforeRun: []benchmarkv1alpha1.Hook{
+			{Name: "flush", HTTP: &benchmarkv1alpha1.HTTPHook{
+				URL:            "http://proxy.default.svc/flush",
+				Method:         "POST",
+				Headers:        map[string]string{"X-Token": "t", "Content-Type": "application/json"},
+				Body:           `{"all":true}`,
+				TimeoutSeconds: 30,
+			}},
+		},
+		AfterRun: []benchmarkv1alpha1.Hook{
+			{Name: "compact", SQL: &benchmarkv1alpha1.SQLHook{ConfigMap: "compact", Database: "other", ContinueOnError: true}},
+		},
+		AfterAll: []benchmarkv1alpha1.Hook{
+			{Name: "notify", HTTP: &benchmarkv1alpha1.HTTPHook{URL: "http://hook.default.svc/done"}},
+		},
+	}
+}
+
+func TestNewSysbenchJobsRunsHooksAroundSteps(t *testing.T) {
+	cr := &benchmarkv1alpha1.Sysbench{
+		ObjectMeta: metav1.ObjectMeta{Name: "sb", Namespace: "default"},
+		Spec: benchmarkv1alpha1.SysbenchSpec{
+			Tables:  1,
+			Size:    1000,
+			Threads: []int{4},
+			Types:   []string{"oltp_read_write"},
+			BenchCommon: benchmarkv1alpha1.BenchCommon{
+				Step:   constants.AllStep,
+				Target: newVerifyTestTarget(constants.MySqlDriver),
+				Hooks:  newHookTestHooks(),
+			},
+		},
+	}
+
+	jobs := NewSysbenchJobs(cr)
+	want := []string{
+		"sb-precheck", "sb-cleanup",
+		"sb-before-prepare-snapshot", "sb-prepare", "sb-verify", "sb-after-prepare-analyze",
+		"sb-before-run-flush", "sb-run-0", "sb-after-run-compact",
+		"sb-after-all-notify",
+	}
+	if got := jobNames(jobs); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected jobs %v, got %v", want, got)
+	}
+
+	// hook jobs are labeled like every other job of the benchmark
+	for _, job := range jobs {
+		if job.Labels[constants.KubeBenchNameLabel] != "sb" {
+			t.Fatalf("expected job %s to be labeled, got %v", job.Name, job.Labels)
+		}
+	}
+
+	snapshot := jobs[2].Spec.Template.Spec.Containers[0]
+	if snapshot.Image != "bitnami/kubectl" || strings.Join(snapshot.Args, " ") != "get pods" {
+		t.Fatalf("unexpected container hook: %+v", snapshot)
+	}
+
+	analyze := jobs[5].Spec.Template.Spec.Containers[0]
+	if !containsAll(analyze.Args, []string{"sql", "exec", "--driver", "mysql", "--database", "kubebench", "--transaction", "file"}) {
+		t.Fatalf("unexpected sql hook args: %#v", analyze.Args)
+	}
+	if !hasVolumeMount(analyze.VolumeMounts, "sql", utils.SQLScriptMountPath) {
+		t.Fatalf("expected sql scripts to be mounted: %#v", analyze.VolumeMounts)
+	}
+
+	flush := jobs[6].Spec.Template.Spec.Containers[0]
+	wantArgs := []string{"http", "--url", "http://proxy.default.svc/flush", "--method", "POST",
+		"--header", "Content-Type: application/json", "--header", "X-Token: t",
+		"--body", `{"all":true}`, "--timeout", "30s"}
+	if strings.Join(flush.Args, "|") != strings.Join(wantArgs, "|") {
+		t.Fatalf("expected http hook args %#v, got %#v", wantArgs, flush.Args)
+	}
+
+	compact := jobs[8].Spec.Template.Spec.Containers[0]
+	if !containsAll(compact.Args, []string{"--database", "other", "--continue-on-error"}) {
+		t.Fatalf("unexpected sql hook args: %#v", compact.Args)
+	}
+}
+
+func TestNewSysbenchJobsSkipsHooksOfSkippedSteps(t *testing.T) {
+	cr := &benchmarkv1alpha1.Sysbench{
+		ObjectMeta: metav1.ObjectMeta{Name: "sb", Namespace: "default"},
+		Spec: benchmarkv1alpha1.SysbenchSpec{
+			Threads: []int{4},
+			Types:   []string{"oltp_read_write"},
+			BenchCommon: benchmarkv1alpha1.BenchCommon{
+				Step:   constants.RunStep,
+				Target: newVerifyTestTarget(constants.MySqlDriver),
+				Hooks:  newHookTestHooks(),
+			},
+		},
+	}
+
+	want := []string{"sb-precheck", "sb-before-run-flush", "sb-run-0", "sb-after-run-compact", "sb-after-all-notify"}
+	if got := jobNames(NewSysbenchJobs(cr)); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected jobs %v, got %v", want, got)
+	}
+}
+
+func TestNewHookJobsSkipsSQLHooksOfNonSQLTargets(t *testing.T) {
+	target := newVerifyTestTarget(constants.RedisDriver)
+	hooks := &benchmarkv1alpha1.Hooks{
+		AfterRun: []benchmarkv1alpha1.Hook{
+			{Name: "analyze", SQL: &benchmarkv1alpha1.SQLHook{ConfigMap: "analyze"}},
+			{Name: "restart", Container: &benchmarkv1alpha1.ContainerHook{
+				Image: "bitnami/kubectl",
+				Env:   []corev1.EnvVar{{Name: "TARGET", Value: "redis"}},
+			}},
+		},
+	}
+
+	jobs := utils.NewHookJobs("rb", "default", constants.AfterRunHook, hooks, &target)
+	if got := jobNames(jobs); strings.Join(got, ",") != "rb-after-run-restart" {
+		t.Fatalf("expected only the container hook, got %v", got)
+	}
+	if envValue(jobs[0], "TARGET") != "redis" {
+		t.Fatalf("expected container hook env, got %#v", jobs[0].Spec.Template.Spec.Containers[0].Env)
+	}
+
+	if jobs := utils.NewHookJobs("rb", "default", constants.AfterRunHook, nil, &target); len(jobs) != 0 {
+		t.Fatalf("expected no jobs without hooks, got %v", jobNames(jobs))
+	}
+}
+
+func TestFailJobRunsAfterAllHooks(t *testing.T) {
+	cr := &benchmarkv1alpha1.Sysbench{
+		ObjectMeta: metav1.ObjectMeta{Name: "sb", Namespace: "default"},
+		Spec: benchmarkv1alpha1.SysbenchSpec{
+			Threads: []int{4},
+			Types:   []string{"oltp_read_write"},
+			BenchCommon: benchmarkv1alpha1.BenchCommon{
+				Step:   constants.RunStep,
+				Target: newVerifyTestTarget(constants.MySqlDriver),
+				Hooks:  newHookTestHooks(),
+			},
+		},
+	}
+	jobs := NewSysbenchJobs(cr)
+
+	// the run job fails, the afterAll hook runs before the benchmark fails
+	succeeded := 2
+	if phase := utils.FailJob(jobs, &succeeded); phase != benchmarkv1alpha1.Running || jobs[succeeded].Name != "sb-after-all-notify" {
+		t.Fatalf("expected to skip ahead to the afterAll hook, got %s at %d", phase, succeeded)
+	}
+	if phase := utils.FailJob(jobs, &succeeded); phase != benchmarkv1alpha1.Failed {
+		t.Fatalf("expected a failing afterAll hook to fail the benchmark, got %s", phase)
+	}
+
+	cr.Spec.Hooks = nil
+	succeeded = 1
+	if phase := utils.FailJob(NewSysbenchJobs(cr), &succeeded); phase != benchmarkv1alpha1.Failed || succeeded != 1 {
+		t.Fatalf("expected to fail without afterAll hooks, got %s at %d", phase, succeeded)
+	}
+}
+
+func TestNewTpchJobsAllStepHooks(t *testing.T) {
+	hooks := newHookTestHooks()
+	hooks.AfterPrepare = nil
+	cr := &benchmarkv1alpha1.Tpch{
+		ObjectMeta: metav1.ObjectMeta{Name: "tpch", Namespace: "default"},
+		Spec: benchmarkv1alpha1.TpchSpec{
+			Size: 1,
+			BenchCommon: benchmarkv1alpha1.BenchCommon{
+				Step:   constants.AllStep,
+				Target: newVerifyTestTarget(constants.MySqlDriver),
+				Hooks:  hooks,
+			},
+		},
+	}
+
+	want := []string{"tpch-precheck", "tpch-before-prepare-snapshot", "tpch-before-run-flush", "tpch-all", "tpch-after-run-compact", "tpch-after-all-notify"}
+	if got := jobNames(NewTpchJobs(cr)); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected jobs %v, got %v", want, got)
+	}
+}
@@ -19,12 +19,14 @@ package controller
 import (
 	"context"
 	"fmt"
-	"strings"
+	"strconv"
 	"time"
 
+	batchv1 "k8s.io/api/batch/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -32,7 +34,9 @@ import (
 
 	benchmarkv1alpha1 "github.com/apecloud/kubebench/api/v1alpha1"
 	intctrlutil "github.com/apecloud/kubebench/internal/controllerutil"
+	"github.com/apecloud/kubebench/internal/exporter"
 	"github.com/apecloud/kubebench/internal/utils"
+	"github.com/apecloud/kubebench/pkg/constants"
 )
 
 // PgbenchReconciler reconciles a Pgbench object
@@ -40,6 +44,7 @@ type PgbenchReconciler struct {
 	client.Client
 	Scheme     *runtime.Scheme
 	RestConfig *rest.Config
+	Recorder   record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=benchmark.apecloud.io,resources=pgbenches,verbs=get;list;watch;create;update;patch;delete
@@ -57,6 +62,8 @@ type PgbenchReconciler struct {
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.15.0/pkg/reconcile
 func (r *PgbenchReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	l := log.FromContext(ctx)
+	// the summary and the archive of a finished job share its logs
+	ctx = utils.WithPodLogs(ctx)
 
 	var pgbench benchmarkv1alpha1.Pgbench
 	if err := r.Get(ctx, req.NamespacedName, &pgbench); err != nil {
@@ -64,11 +71,57 @@ func (r *PgbenchReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	}
 	old := pgbench.DeepCopy()
 
+	// record the logs archived to a pvc once they are copied into the claim
+	archiving, err := utils.SyncArchivedLogs(r.Client, ctx, &pgbench, pgbench.Spec.LogArchive, &pgbench.Status.Logs)
+	if err != nil {
+		return intctrlutil.RequeueWithError(err, l, "unable to sync the archived logs")
+	}
+
 	if pgbench.Status.Phase == benchmarkv1alpha1.Completed || pgbench.Status.Phase == benchmarkv1alpha1.Failed {
-		return intctrlutil.Reconciled()
+		if err := r.Status().Patch(ctx, &pgbench, client.MergeFrom(old)); err != nil {
+			l.Error(err, "failed to patch pgbench status")
+			return intctrlutil.RequeueWithError(err, l, "failed to patch pgbench status")
+		}
+		if archiving {
+			return intctrlutil.RequeueAfter(intctrlutil.RequeueDuration)
+		}
+		// collect the jobs once the ttl expired
+		requeueAfter, err := utils.CollectFinished(r.Client, ctx, &pgbench, &pgbench.Spec.BenchCommon, pgbench.Status.CompletionTimestamp)
+		if err != nil {
+			return intctrlutil.RequeueWithError(err, l, "failed to collect the finished pgbench")
+		}
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+
+	if len(pgbench.Spec.Targets) > 0 {
+		phase, succeeded, err := utils.ReconcileTargets(r.Client, ctx, r.Scheme, &pgbench, pgbench.Spec.Targets, pgbench.Spec.TargetsPolicy, &pgbench.Status.Targets)
+		if err != nil {
+			l.Error(err, "failed to reconcile the targets", "pgbench", pgbench.Name)
+			return intctrlutil.RequeueWithError(err, l, "failed to reconcile the targets")
+		}
+		pgbench.Status.Phase = phase
+		pgbench.Status.Succeeded = succeeded
+		pgbench.Status.Total = len(pgbench.Spec.Targets)
+		pgbench.Status.Completions = fmt.Sprintf("%d/%d", pgbench.Status.Succeeded, pgbench.Status.Total)
+		if phase == benchmarkv1alpha1.Completed || phase == benchmarkv1alpha1.Failed {
+			pgbench.Status.CompletionTimestamp = &metav1.Time{Time: time.Now()}
+		}
+		utils.RecordPhaseTransition(r.Recorder, &pgbench, old.Status.Phase, pgbench.Status.Phase)
+		if err := r.Status().Patch(ctx, &pgbench, client.MergeFrom(old)); err != nil {
+			l.Error(err, "failed to patch pgbench status")
+			return intctrlutil.RequeueWithError(err, l, "failed to patch pgbench status")
+		}
+		return intctrlutil.RequeueAfter(intctrlutil.RequeueDuration)
+	}
+
+	resolved, err := utils.ResolveTarget(r.Client, ctx, pgbench.Namespace, pgbench.Spec.TargetRef, pgbench.Status.Target, &pgbench.Spec.Target)
+	if err != nil {
+		l.Error(err, "failed to resolve the target", "pgbench", pgbench.Name)
+		return intctrlutil.RequeueWithError(err, l, "failed to resolve the target")
 	}
+	pgbench.Status.Target = resolved
 
-	jobs := NewPgbenchJobs(&pgbench)
+	jobs := utils.SetJobDeadlines(NewPgbenchJobs(&pgbench), pgbench.Spec.Timeouts)
 
 	if pgbench.Status.Phase == "" {
 		l.Info("start pgbench", "pgbench", pgbench.Name)
@@ -78,6 +131,10 @@ func (r *PgbenchReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 
 	if pgbench.Status.Succeeded >= pgbench.Status.Total {
 		pgbench.Status.Phase = benchmarkv1alpha1.Completed
+		if pgbench.Status.Reason != "" {
+			// a job failed and the afterAll hooks ran after it
+			pgbench.Status.Phase = benchmarkv1alpha1.Failed
+		}
 		pgbench.Status.CompletionTimestamp = &metav1.Time{Time: time.Now()}
 	} else {
 		job := jobs[pgbench.Status.Succeeded]
@@ -101,6 +158,7 @@ func (r *PgbenchReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 
 			// wait for the job to be created
 			l.Info("created job", "job", job.Name)
+			utils.RecordJobCreated(r.Recorder, &pgbench, job, pgbench.Spec.Target.Password)
 			return intctrlutil.RequeueAfter(intctrlutil.RequeueDuration)
 		}
 
@@ -110,26 +168,69 @@ func (r *PgbenchReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 			l.Error(err, "failed to get job status", "job", job.Name)
 			return intctrlutil.RequeueWithError(err, l, "failed to get job status")
 		}
+		// the nodes cordoned by the faults are schedulable again once the run job finished
+		if err := utils.UncordonNodes(r.Client, ctx, pgbench.Spec.Faults, job, status); err != nil {
+			return intctrlutil.RequeueWithError(err, l, "unable to uncordon the nodes")
+		}
 
 		if status.Succeeded > 0 {
 			l.Info("job completed", "job", job.Name)
+			utils.RecordJobFinished(r.Client, ctx, r.Recorder, &pgbench, job, true)
+			utils.ObserveJobFinished(r.Client, ctx, constants.PgbenchType, jobs, pgbench.Status.Succeeded, true)
 			pgbench.Status.Succeeded++
 			// record the result
-			if err := utils.LogJobPodToCond(r.Client, r.RestConfig, ctx, job.Name, pgbench.Namespace, &pgbench.Status.Conditions, ParsePgbench); err != nil {
+			if err := utils.RecordJobSummary(r.Client, r.RestConfig, ctx, job.Name, pgbench.Namespace, &pgbench.Status.Conditions, constants.PgbenchType); err != nil {
 				return intctrlutil.RequeueWithError(err, l, "unable to record the log")
 			}
+			if err := r.recordLatencyStats(ctx, &pgbench, job); err != nil {
+				return intctrlutil.RequeueWithError(err, l, "unable to record the latency stats")
+			}
+			if err := utils.ArchiveJobLogs(r.Client, r.RestConfig, ctx, r.Scheme, &pgbench, pgbench.Spec.LogArchive, job.Name, &pgbench.Status.Logs); err != nil {
+				return intctrlutil.RequeueWithError(err, l, "unable to archive the log")
+			}
+		} else if step := utils.TimedOutStep(&pgbench, pgbench.Spec.Timeouts, job, status); step != "" {
+			if pgbench.Status.TimedOutStep != "" {
+				step = pgbench.Status.TimedOutStep
+			}
+			pgbench.Status.TimedOutStep = step
+			done, err := utils.StopTimedOutJob(r.Client, ctx, r.Recorder, &pgbench, constants.PgbenchType, job, step)
+			if err != nil {
+				return intctrlutil.RequeueWithError(err, l, "unable to stop the job")
+			}
+			if done {
+				l.Info("job timed out", "job", job.Name, "step", step)
+				pgbench.Status.Phase = utils.FailJob(jobs, &pgbench.Status.Succeeded)
+				pgbench.Status.Reason = utils.TimedOutReason
+				if err := utils.LogJobPodToCond(r.Client, r.RestConfig, ctx, job.Name, pgbench.Namespace, &pgbench.Status.Conditions, nil); err != nil {
+					return intctrlutil.RequeueWithError(err, l, "unable to record the log")
+				}
+				if err := utils.ArchiveJobLogs(r.Client, r.RestConfig, ctx, r.Scheme, &pgbench, pgbench.Spec.LogArchive, job.Name, &pgbench.Status.Logs); err != nil {
+					return intctrlutil.RequeueWithError(err, l, "unable to archive the log")
+				}
+			}
 		} else if status.Failed > 0 {
 			l.Info("job failed", "job", job.Name)
-			pgbench.Status.Phase = benchmarkv1alpha1.Failed
+			utils.RecordJobFinished(r.Client, ctx, r.Recorder, &pgbench, job, false)
+			utils.ObserveJobFinished(r.Client, ctx, constants.PgbenchType, jobs, pgbench.Status.Succeeded, false)
+			if pgbench.Status.Reason == "" {
+				pgbench.Status.Reason = utils.JobFailedReason
+			}
+			pgbench.Status.Phase = utils.FailJob(jobs, &pgbench.Status.Succeeded)
 			if err := utils.LogJobPodToCond(r.Client, r.RestConfig, ctx, job.Name, pgbench.Namespace, &pgbench.Status.Conditions, nil); err != nil {
 				return intctrlutil.RequeueWithError(err, l, "unable to record the log")
 			}
+			if err := utils.ArchiveJobLogs(r.Client, r.RestConfig, ctx, r.Scheme, &pgbench, pgbench.Spec.LogArchive, job.Name, &pgbench.Status.Logs); err != nil {
+				return intctrlutil.RequeueWithError(err, l, "unable to archive the log")
+			}
 		} else {
 			l.Info("job is running", "job", job.Name)
+			utils.RecordJobStarted(r.Client, ctx, r.Recorder, &pgbench, job.Name, job.Namespace)
+			utils.InjectFaults(r.Client, ctx, r.Scheme, &pgbench, pgbench.Spec.Faults, job, status, &pgbench.Status.Faults)
 		}
 	}
 
 	pgbench.Status.Completions = fmt.Sprintf("%d/%d", pgbench.Status.Succeeded, pgbench.Status.Total)
+	utils.RecordPhaseTransition(r.Recorder, &pgbench, old.Status.Phase, pgbench.Status.Phase)
 	if err := r.Status().Patch(ctx, &pgbench, client.MergeFrom(old)); err != nil {
 		l.Error(err, "failed to patch pgbench status")
 		return intctrlutil.RequeueWithError(err, l, "failed to patch pgbench status")
@@ -138,33 +239,70 @@ func (r *PgbenchReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	return intctrlutil.RequeueAfter(intctrlutil.RequeueDuration)
 }
 
-// SetupWithManager sets up the controller with the Manager.
-func (r *PgbenchReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&benchmarkv1alpha1.Pgbench{}).
-		Complete(r)
-}
+// recordLatencyStats records the analysis of the transaction log that the
+// exporter printed for the run job
+func (r *PgbenchReconciler) recordLatencyStats(ctx context.Context, pgbench *benchmarkv1alpha1.Pgbench, job *batchv1.Job) error {
+	if !utils.IsRunJob(job) || (!pgbench.Spec.Log && pgbench.Spec.AggregateInterval == 0) {
+		return nil
+	}
 
-func ParsePgbench(msg string) string {
-	result := ""
-	lines := strings.Split(msg, "\n")
-	index := len(lines)
+	result := &exporter.PgbenchLogResult{}
+	ok, err := utils.ReadResultReport(r.Client, r.RestConfig, ctx, job.Name, pgbench.Namespace, result)
+	if err != nil || !ok {
+		return err
+	}
+	setPgbenchLatencyStats(&pgbench.Status.LatencyStats, NewPgbenchLatencyStats(job.Name, result))
+	return nil
+}
 
-	for i, l := range lines {
-		if strings.Contains(l, "transaction type") {
-			index = i
-			result += fmt.Sprintf("%s\n", l)
-			break
-		}
+// NewPgbenchLatencyStats converts the analysis of the transaction log of the
+// job to its status
+func NewPgbenchLatencyStats(jobName string, result *exporter.PgbenchLogResult) benchmarkv1alpha1.PgbenchLatencyStats {
+	stats := benchmarkv1alpha1.PgbenchLatencyStats{
+		Job:          jobName,
+		Transactions: result.Transactions,
+		Failed:       result.Failed,
+		Skipped:      result.Skipped,
+		Max:          formatLatency(result.Max),
 	}
+	if len(result.Percentiles) == len(exporter.PgbenchPercentiles) {
+		stats.P50 = formatLatency(result.Percentiles[0])
+		stats.P95 = formatLatency(result.Percentiles[1])
+		stats.P99 = formatLatency(result.Percentiles[2])
+	}
+	for _, script := range result.Scripts {
+		stats.Scripts = append(stats.Scripts, benchmarkv1alpha1.PgbenchScriptStats{
+			Script:       script.Script,
+			Transactions: script.Transactions,
+			Failed:       script.Failed,
+			Avg:          formatLatency(script.Avg),
+			P95:          formatLatency(script.P95),
+			Max:          formatLatency(script.Max),
+		})
+	}
+	return stats
+}
 
-	for i := index + 1; i < len(lines); i++ {
-		if lines[i] != "" {
-			// align the output
-			result += fmt.Sprintf("%*s\n", len(lines[i])+27, lines[i])
+// setPgbenchLatencyStats replaces the stats of the job or appends them
+func setPgbenchLatencyStats(records *[]benchmarkv1alpha1.PgbenchLatencyStats, stats benchmarkv1alpha1.PgbenchLatencyStats) {
+	for i := range *records {
+		if (*records)[i].Job == stats.Job {
+			(*records)[i] = stats
+			return
 		}
 	}
+	*records = append(*records, stats)
+}
+
+// formatLatency formats the latency in milliseconds, the status has no
+// floats
+func formatLatency(latency float64) string {
+	return strconv.FormatFloat(latency, 'f', 3, 64)
+}
 
-	// delete the last \n
-	return strings.TrimSpace(result)
+// SetupWithManager sets up the controller with the Manager.
+func (r *PgbenchReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&benchmarkv1alpha1.Pgbench{}).
+		Complete(r)
 }
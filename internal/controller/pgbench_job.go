@@ -2,6 +2,8 @@ package controller
 
 import (
 	"fmt"
+	"path"
+	"strconv"
 	"strings"
 
 	batchv1 "k8s.io/api/batch/v1"
@@ -12,6 +14,11 @@ import (
 	"github.com/apecloud/kubebench/pkg/constants"
 )
 
+const (
+	pgbenchScriptsVolume = "scripts"
+	pgbenchScriptsPath   = "/etc/kubebench/pgbench"
+)
+
 func NewPgbenchJobs(cr *v1alpha1.Pgbench) []*batchv1.Job {
 	jobs := make([]*batchv1.Job, 0)
 
@@ -20,14 +27,20 @@ func NewPgbenchJobs(cr *v1alpha1.Pgbench) []*batchv1.Job {
 
 	step := cr.Spec.Step
 	if step == constants.CleanupStep || step == constants.AllStep {
-		jobs = append(jobs, NewPgbenchCleanupJobs(cr)...)
+		jobs = append(jobs, utils.MarkStepJobs(NewPgbenchCleanupJobs(cr), constants.CleanupStep)...)
 	}
 	if step == constants.PrepareStep || step == constants.AllStep {
-		jobs = append(jobs, NewPgbenchPrepareJobs(cr)...)
+		jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.BeforePrepareHook, cr.Spec.Hooks, &cr.Spec.Target)...)
+		jobs = append(jobs, utils.MarkStepJobs(NewPgbenchPrepareJobs(cr), constants.PrepareStep)...)
+		jobs = append(jobs, utils.MarkStepJobs(NewPgbenchVerifyJobs(cr), constants.PrepareStep)...)
+		jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.AfterPrepareHook, cr.Spec.Hooks, &cr.Spec.Target)...)
 	}
 	if step == constants.RunStep || step == constants.AllStep {
-		jobs = append(jobs, NewPgbenchRunJobs(cr)...)
+		jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.BeforeRunHook, cr.Spec.Hooks, &cr.Spec.Target)...)
+		jobs = append(jobs, utils.MarkRunJobs(NewPgbenchRunJobs(cr))...)
+		jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.AfterRunHook, cr.Spec.Hooks, &cr.Spec.Target)...)
 	}
+	jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.AfterAllHook, cr.Spec.Hooks, &cr.Spec.Target)...)
 
 	// set tolerations for all jobs
 	utils.AddTolerationToJobs(jobs, cr.Spec.Tolerations)
@@ -148,12 +161,34 @@ func NewPgbenchPrepareJobs(cr *v1alpha1.Pgbench) []*batchv1.Job {
 	return []*batchv1.Job{job}
 }
 
+// NewPgbenchVerifyJobs checks the row counts of the pgbench tables for the
+// configured scale factor
+func NewPgbenchVerifyJobs(cr *v1alpha1.Pgbench) []*batchv1.Job {
+	if cr.Spec.Scale <= 0 {
+		return nil
+	}
+
+	scale := int64(cr.Spec.Scale)
+	expect := map[string]int64{
+		"pgbench_branches": scale,
+		"pgbench_tellers":  scale * 10,
+		"pgbench_accounts": scale * 100000,
+	}
+
+	job := utils.NewVerifyJob(cr.Name, cr.Namespace, constants.PostgreSqlDriver, &cr.Spec.Target, cr.Spec.Target.Database, expect)
+	if job == nil {
+		return nil
+	}
+	return []*batchv1.Job{job}
+}
+
 func NewPgbenchRunJobs(cr *v1alpha1.Pgbench) []*batchv1.Job {
 	cmd := "pgbench"
 	cmd = fmt.Sprintf("%s -P 1", cmd)
 	cmd = fmt.Sprintf("%s -j %d", cmd, cr.Spec.Threads)
 
 	// priority: transactions > time
+	warmupCmd := pgbenchWarmupCmd(cr, cmd)
 	switch {
 	case cr.Spec.Transactions > 0:
 		cmd = fmt.Sprintf("%s -t %d", cmd, cr.Spec.Transactions)
@@ -161,20 +196,29 @@ func NewPgbenchRunJobs(cr *v1alpha1.Pgbench) []*batchv1.Job {
 		cmd = fmt.Sprintf("%s -T %d", cmd, cr.Spec.Duration)
 	}
 
+	options := ""
 	if cr.Spec.Connect {
-		cmd = fmt.Sprintf("%s -C", cmd)
+		options = fmt.Sprintf("%s -C", options)
 	}
 
 	if cr.Spec.SelectOnly {
-		cmd = fmt.Sprintf("%s -S", cmd)
+		options = fmt.Sprintf("%s -S", options)
 	}
+	options += pgbenchScriptOptions(cr)
 
 	// TODO add func to parse extra args
-	cmd = fmt.Sprintf("%s %s", cmd, strings.Join(cr.Spec.ExtraArgs, " "))
+	options = fmt.Sprintf("%s %s", options, strings.Join(cr.Spec.ExtraArgs, " "))
+	cmd += options
+
+	// only the measured pass logs the transactions
+	cmd += pgbenchLogOptions(cr)
 
 	jobs := make([]*batchv1.Job, 0)
 	for i, client := range cr.Spec.Clients {
 		curCmd := fmt.Sprintf("%s -c %d", cmd, client)
+		if warmupCmd != "" {
+			curCmd = utils.WarmupCommand(fmt.Sprintf("%s%s -c %d", warmupCmd, options, client), "/var/log/pgbench.log") + curCmd
+		}
 		jobName := fmt.Sprintf("%s-run-%d", cr.Name, i)
 		curJob := utils.JobTemplate(jobName, cr.Namespace)
 
@@ -185,7 +229,9 @@ func NewPgbenchRunJobs(cr *v1alpha1.Pgbench) []*batchv1.Job {
 				Image:           constants.GetBenchmarkImage(constants.KubebenchEnvPgbench),
 				ImagePullPolicy: corev1.PullIfNotPresent,
 				Command:         []string{"/bin/sh", "-c"},
-				Args:            []string{fmt.Sprintf("%s 2>&1 | tee -a /var/log/pgbench.log", curCmd)},
+				// the marker tells the exporter that the statistics of the
+				// scripts following the report are complete
+				Args: []string{fmt.Sprintf("%s 2>&1 | tee -a /var/log/pgbench.log; echo \"%s\" >> /var/log/pgbench.log", curCmd, constants.RunEndMarker)},
 				Env: []corev1.EnvVar{
 					{
 						Name:  "PGHOST",
@@ -215,32 +261,121 @@ func NewPgbenchRunJobs(cr *v1alpha1.Pgbench) []*batchv1.Job {
 					},
 				},
 			})
+		addPgbenchScriptsVolume(cr, curJob)
 
+		exporterArgs := append(utils.ExporterArgs(cr.Labels, &cr.Spec.BenchCommon), utils.ExporterParamArgs(map[string]string{
+			"clients": strconv.Itoa(client),
+			"scale":   strconv.Itoa(cr.Spec.Scale),
+		})...)
+		exporterArgs = append(exporterArgs, utils.ExporterDurationArgs(pgbenchDuration(cr))...)
 		curJob.Spec.Template.Spec.Containers = append(
 			curJob.Spec.Template.Spec.Containers,
-			corev1.Container{
-				Name:            "metrics",
-				Image:           constants.GetBenchmarkImage(constants.KubebenchExporter),
-				ImagePullPolicy: corev1.PullIfNotPresent,
-				Ports: []corev1.ContainerPort{
-					{
-						ContainerPort: 9187,
-						Name:          "http-metrics",
-						Protocol:      corev1.ProtocolTCP,
-					},
-				},
-				Command: []string{"/exporter"},
-				Args:    []string{"-type", "pgbench", "-file", "/var/log/pgbench.log", "-bench", cr.Name, "-job", jobName},
-				VolumeMounts: []corev1.VolumeMount{
-					{
-						Name:      "log",
-						MountPath: "/var/log",
-					},
-				},
-			})
+			utils.NewExporterContainer(constants.PgbenchType, "/var/log/pgbench.log", cr.Name, jobName, exporterArgs...))
 
 		jobs = append(jobs, curJob)
 	}
 
 	return jobs
 }
+
+// pgbenchScriptOptions returns the options selecting the built-in and the
+// custom scripts with their weights, pgbench numbers them in this order
+func pgbenchScriptOptions(cr *v1alpha1.Pgbench) string {
+	options := ""
+	for _, builtin := range cr.Spec.Builtins {
+		options = fmt.Sprintf("%s -b %s@%d", options, builtin.Name, pgbenchWeight(builtin.Weight))
+	}
+	for _, script := range cr.Spec.Scripts {
+		options = fmt.Sprintf("%s -f %s@%d", options, pgbenchScriptFile(script), pgbenchWeight(script.Weight))
+	}
+	return options
+}
+
+// pgbenchWeight returns the weight of a script, the benchmarks created
+// without defaulting run it with weight 1
+func pgbenchWeight(weight int) int {
+	if weight <= 0 {
+		return 1
+	}
+	return weight
+}
+
+func pgbenchScriptFile(script v1alpha1.PgbenchScript) string {
+	return path.Join(pgbenchScriptsPath, script.ConfigMap, script.Key)
+}
+
+// addPgbenchScriptsVolume mounts the custom scripts into the first container
+// of the job, every script under <configMap>/<key>
+func addPgbenchScriptsVolume(cr *v1alpha1.Pgbench, job *batchv1.Job) {
+	if len(cr.Spec.Scripts) == 0 {
+		return
+	}
+
+	sources := make([]corev1.VolumeProjection, 0, len(cr.Spec.Scripts))
+	mounted := map[string]bool{}
+	for _, script := range cr.Spec.Scripts {
+		// a script run twice with different weights is mounted once
+		file := path.Join(script.ConfigMap, script.Key)
+		if mounted[file] {
+			continue
+		}
+		mounted[file] = true
+		sources = append(sources, corev1.VolumeProjection{
+			ConfigMap: &corev1.ConfigMapProjection{
+				LocalObjectReference: corev1.LocalObjectReference{Name: script.ConfigMap},
+				Items:                []corev1.KeyToPath{{Key: script.Key, Path: file}},
+			},
+		})
+	}
+	job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, corev1.Volume{
+		Name: pgbenchScriptsVolume,
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{Sources: sources},
+		},
+	})
+	container := &job.Spec.Template.Spec.Containers[0]
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      pgbenchScriptsVolume,
+		MountPath: pgbenchScriptsPath,
+		ReadOnly:  true,
+	})
+}
+
+// pgbenchDuration returns the seconds of the warmup and the measured pass, 0
+// if the run is limited by the transactions
+func pgbenchDuration(cr *v1alpha1.Pgbench) int {
+	if cr.Spec.Transactions > 0 || cr.Spec.Duration <= 0 {
+		return 0
+	}
+	return utils.WarmupSeconds(cr.Spec.Warmup, cr.Spec.Duration) + cr.Spec.Duration
+}
+
+// pgbenchWarmupCmd returns cmd limited to the warmup, a percentage is taken of
+// the transactions when they are set and of the duration otherwise. An empty
+// string is returned without warmup.
+func pgbenchWarmupCmd(cr *v1alpha1.Pgbench, cmd string) string {
+	d, fraction := utils.ParseWarmup(cr.Spec.Warmup)
+	switch {
+	case fraction > 0 && cr.Spec.Transactions > 0:
+		return fmt.Sprintf("%s -t %d", cmd, utils.WarmupCount(fraction, cr.Spec.Transactions))
+	case fraction > 0 && cr.Spec.Duration > 0:
+		return fmt.Sprintf("%s -T %d", cmd, utils.WarmupCount(fraction, cr.Spec.Duration))
+	case d > 0:
+		return fmt.Sprintf("%s -T %d", cmd, utils.WarmupSeconds(cr.Spec.Warmup, 0))
+	default:
+		return ""
+	}
+}
+
+// pgbenchLogOptions returns the options that write the transaction log to
+// the log volume, aggregated if an aggregate interval is set
+func pgbenchLogOptions(cr *v1alpha1.Pgbench) string {
+	switch {
+	case cr.Spec.AggregateInterval > 0:
+		return fmt.Sprintf(" --log --aggregate-interval=%d --log-prefix=/var/log/%s", cr.Spec.AggregateInterval, constants.PgbenchAggregateLogPrefix)
+	case cr.Spec.Log:
+		return fmt.Sprintf(" --log --log-prefix=/var/log/%s", constants.PgbenchLogPrefix)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	benchmarkv1alpha1 "github.com/apecloud/kubebench/api/v1alpha1"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+func TestNewPgbenchRunJobsScripts(t *testing.T) {
+	cr := &benchmarkv1alpha1.Pgbench{
+		ObjectMeta: metav1.ObjectMeta{Name: "pgbench", Namespace: "default"},
+		Spec: benchmarkv1alpha1.PgbenchSpec{
+			Threads:  1,
+			Clients:  []int{8},
+			Duration: 60,
+			Warmup:   "10s",
+			Builtins: []benchmarkv1alpha1.PgbenchBuiltin{
+				{Name: "tpcb-like", Weight: 1},
+				{Name: "select-only", Weight: 3},
+			},
+			Scripts: []benchmarkv1alpha1.PgbenchScript{
+				{ConfigMap: "app-sql", Key: "checkout.sql", Weight: 2},
+				{ConfigMap: "app-sql", Key: "checkout.sql", Weight: 5},
+				{ConfigMap: "app-sql", Key: "search.sql"},
+			},
+			BenchCommon: benchmarkv1alpha1.BenchCommon{Target: newVerifyTestTarget(constants.PostgreSqlDriver)},
+		},
+	}
+
+	job := NewPgbenchRunJobs(cr)[0]
+	cmd := job.Spec.Template.Spec.Containers[0].Args[0]
+	options := "-b tpcb-like@1 -b select-only@3 -f /etc/kubebench/pgbench/app-sql/checkout.sql@2 -f /etc/kubebench/pgbench/app-sql/checkout.sql@5 -f /etc/kubebench/pgbench/app-sql/search.sql@1"
+	warmup, measured, ok := strings.Cut(cmd, constants.WarmupEndMarker)
+	if !ok || !strings.Contains(warmup, options) || !strings.Contains(measured, options) {
+		t.Fatalf("expected the scripts in the warmup and the measured pass: %s", cmd)
+	}
+	if !strings.HasSuffix(cmd, fmt.Sprintf("; echo \"%s\" >> /var/log/pgbench.log", constants.RunEndMarker)) {
+		t.Fatalf("expected the end marker after the run: %s", cmd)
+	}
+
+	var paths []string
+	for _, volume := range job.Spec.Template.Spec.Volumes {
+		if volume.Name == pgbenchScriptsVolume && volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				for _, item := range source.ConfigMap.Items {
+					paths = append(paths, source.ConfigMap.Name+":"+item.Key+"="+item.Path)
+				}
+			}
+		}
+	}
+	if strings.Join(paths, ",") != "app-sql:checkout.sql=app-sql/checkout.sql,app-sql:search.sql=app-sql/search.sql" {
+		t.Fatalf("expected every script mounted once, got %v", paths)
+	}
+	mounted := false
+	for _, mount := range job.Spec.Template.Spec.Containers[0].VolumeMounts {
+		mounted = mounted || (mount.Name == pgbenchScriptsVolume && mount.MountPath == pgbenchScriptsPath && mount.ReadOnly)
+	}
+	if !mounted {
+		t.Fatalf("scripts are not mounted: %#v", job.Spec.Template.Spec.Containers[0].VolumeMounts)
+	}
+
+	cr.Spec.Builtins = nil
+	cr.Spec.Scripts = nil
+	job = NewPgbenchRunJobs(cr)[0]
+	if cmd := job.Spec.Template.Spec.Containers[0].Args[0]; strings.Contains(cmd, " -b ") || strings.Contains(cmd, " -f ") {
+		t.Fatalf("expected the default script: %s", cmd)
+	}
+	if len(job.Spec.Template.Spec.Volumes) != 1 {
+		t.Fatalf("expected no scripts volume: %#v", job.Spec.Template.Spec.Volumes)
+	}
+}
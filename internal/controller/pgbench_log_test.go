@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	benchmarkv1alpha1 "github.com/apecloud/kubebench/api/v1alpha1"
+	"github.com/apecloud/kubebench/internal/exporter"
+	"github.com/apecloud/kubebench/internal/utils"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+func TestNewPgbenchRunJobsLog(t *testing.T) {
+	cr := &benchmarkv1alpha1.Pgbench{
+		ObjectMeta: metav1.ObjectMeta{Name: "pgbench", Namespace: "default"},
+		Spec: benchmarkv1alpha1.PgbenchSpec{
+			Clients:  []int{1},
+			Threads:  1,
+			Duration: 60,
+			Warmup:   "10s",
+			BenchCommon: benchmarkv1alpha1.BenchCommon{
+				Target: newVerifyTestTarget(constants.PostgreSqlDriver),
+			},
+		},
+	}
+
+	cmd := NewPgbenchRunJobs(cr)[0].Spec.Template.Spec.Containers[0].Args[0]
+	if strings.Contains(cmd, "--log") {
+		t.Fatalf("did not expect a transaction log: %s", cmd)
+	}
+
+	cr.Spec.Log = true
+	cmd = NewPgbenchRunJobs(cr)[0].Spec.Template.Spec.Containers[0].Args[0]
+	if strings.Count(cmd, "--log --log-prefix=/var/log/pgbench_log") != 1 || strings.Index(cmd, "--log") < strings.Index(cmd, constants.WarmupEndMarker) {
+		t.Fatalf("expected only the measured pass to log the transactions: %s", cmd)
+	}
+
+	cr.Spec.AggregateInterval = 5
+	cmd = NewPgbenchRunJobs(cr)[0].Spec.Template.Spec.Containers[0].Args[0]
+	if !strings.Contains(cmd, "--log --aggregate-interval=5 --log-prefix=/var/log/pgbench_agg") {
+		t.Fatalf("expected an aggregated log: %s", cmd)
+	}
+}
+
+func TestPgbenchLatencyStatsFromReport(t *testing.T) {
+	log := strings.Join([]string{
+		"I1019 10:00:00.000000       1 scrape.go:71] update pgbench total metrics",
+		fmt.Sprintf(`%s {"transactions":10,"failed":1,"percentiles":[1.5,4,9.25],"max":12,"scripts":[{"script":0,"transactions":10,"failed":1,"avg":2,"p95":4,"max":12}]}`, constants.ResultReportMarker),
+	}, "\n")
+
+	result := &exporter.PgbenchLogResult{}
+	ok, err := utils.ParseResultReport(log, result)
+	if err != nil || !ok {
+		t.Fatalf("expected the report to be parsed, got %v", err)
+	}
+
+	stats := NewPgbenchLatencyStats("pgbench-run-0", result)
+	if stats.Transactions != 10 || stats.Failed != 1 || stats.P50 != "1.500" || stats.P95 != "4.000" || stats.P99 != "9.250" || stats.Max != "12.000" {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if len(stats.Scripts) != 1 || stats.Scripts[0].Avg != "2.000" || stats.Scripts[0].Failed != 1 {
+		t.Fatalf("unexpected script stats: %+v", stats.Scripts)
+	}
+
+	records := []benchmarkv1alpha1.PgbenchLatencyStats{{Job: "pgbench-run-0"}, {Job: "pgbench-run-1"}}
+	setPgbenchLatencyStats(&records, stats)
+	if len(records) != 2 || records[0].Transactions != 10 {
+		t.Fatalf("expected the stats of the job to be replaced: %+v", records)
+	}
+
+	aggregated := NewPgbenchLatencyStats("pgbench-run-1", &exporter.PgbenchLogResult{Aggregated: true, Transactions: 5, Max: 3})
+	if aggregated.P50 != "" || aggregated.Max != "3.000" {
+		t.Fatalf("expected no percentiles from aggregated logs: %+v", aggregated)
+	}
+
+	if ok, err := utils.ParseResultReport("no report", result); ok || err != nil {
+		t.Fatalf("expected no report, got %v %v", ok, err)
+	}
+}
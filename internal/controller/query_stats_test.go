@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	benchmarkv1alpha1 "github.com/apecloud/kubebench/api/v1alpha1"
+	"github.com/apecloud/kubebench/internal/exporter"
+	"github.com/apecloud/kubebench/internal/utils"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+func TestNewQueryRunJobsExporter(t *testing.T) {
+	tpch := &benchmarkv1alpha1.Tpch{
+		ObjectMeta: metav1.ObjectMeta{Name: "tpch", Namespace: "default"},
+		Spec: benchmarkv1alpha1.TpchSpec{
+			Size:        2,
+			BenchCommon: benchmarkv1alpha1.BenchCommon{Target: newVerifyTestTarget(constants.PostgreSqlDriver)},
+		},
+	}
+	tpcds := &benchmarkv1alpha1.Tpcds{
+		ObjectMeta: metav1.ObjectMeta{Name: "tpcds", Namespace: "default"},
+		Spec: benchmarkv1alpha1.TpcdsSpec{
+			Size:        2,
+			BenchCommon: benchmarkv1alpha1.BenchCommon{Target: newVerifyTestTarget(constants.PostgreSqlDriver)},
+		},
+	}
+
+	for _, tc := range []struct {
+		job  string
+		cmd  string
+		file string
+	}{
+		{job: "tpch-run", cmd: NewTpchRunJobs(tpch)[0].Spec.Template.Spec.Containers[0].Args[0], file: tpchLogFile},
+		{job: "tpch-all", cmd: NewTpchAllJobs(tpch)[0].Spec.Template.Spec.Containers[0].Args[0], file: tpchLogFile},
+		{job: "tpcds-run", cmd: NewTpcdsRunJobs(*tpcds)[0].Spec.Template.Spec.Containers[0].Command[2], file: tpcdsLogFile},
+	} {
+		if !strings.HasPrefix(tc.cmd, fmt.Sprintf("echo \"scale factor: 2\" > %s;", tc.file)) {
+			t.Errorf("%s: expected the scale ahead of the queries: %s", tc.job, tc.cmd)
+		}
+		if !strings.Contains(tc.cmd, fmt.Sprintf("echo \"%s\" >> %s", constants.RunEndMarker, tc.file)) {
+			t.Errorf("%s: expected the end marker after the queries: %s", tc.job, tc.cmd)
+		}
+	}
+
+	containers := NewTpcdsRunJobs(*tpcds)[0].Spec.Template.Spec.Containers
+	if len(containers) != 2 || containers[1].Name != utils.MetricsContainerName {
+		t.Fatalf("expected the metrics sidecar: %+v", containers)
+	}
+	if !strings.HasSuffix(containers[0].Command[2], "exit $(cat /var/log/tpcds.exit)") {
+		t.Fatalf("expected the exit code of the runner: %s", containers[0].Command[2])
+	}
+}
+
+func TestQueryStatsFromReport(t *testing.T) {
+	log := fmt.Sprintf(`%s {"scale":2,"queries":[{"query":1,"duration":8,"rows":4},{"query":3,"duration":0,"rows":0,"failed":true}],"duration":8,"failed":1,"power":900,"throughput":900}`, constants.ResultReportMarker)
+
+	result := &exporter.QueryRunResult{}
+	ok, err := utils.ParseResultReport(log, result)
+	if err != nil || !ok {
+		t.Fatalf("expected the report to be parsed, got %v", err)
+	}
+
+	stats := NewQueryStats("tpch-run", result)
+	if stats.Job != "tpch-run" || stats.Duration != "8.000" || stats.Failed != 1 || stats.Power != "900.000" || stats.Throughput != "900.000" {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if len(stats.Queries) != 2 || stats.Queries[0].Duration != "8.000" || stats.Queries[0].Rows != 4 || !stats.Queries[1].Failed {
+		t.Fatalf("unexpected query stats: %+v", stats.Queries)
+	}
+}
@@ -19,12 +19,12 @@ package controller
 import (
 	"context"
 	"fmt"
-	"strings"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -33,6 +33,7 @@ import (
 	benchmarkv1alpha1 "github.com/apecloud/kubebench/api/v1alpha1"
 	intctrlutil "github.com/apecloud/kubebench/internal/controllerutil"
 	"github.com/apecloud/kubebench/internal/utils"
+	"github.com/apecloud/kubebench/pkg/constants"
 )
 
 // RedisbenchReconciler reconciles a Redisbench object
@@ -40,6 +41,7 @@ type RedisbenchReconciler struct {
 	client.Client
 	Scheme     *runtime.Scheme
 	RestConfig *rest.Config
+	Recorder   record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=benchmark.apecloud.io,resources=redisbenches,verbs=get;list;watch;create;update;patch;delete
@@ -57,6 +59,8 @@ type RedisbenchReconciler struct {
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.15.0/pkg/reconcile
 func (r *RedisbenchReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	l := log.FromContext(ctx)
+	// the summary and the archive of a finished job share its logs
+	ctx = utils.WithPodLogs(ctx)
 
 	var redisbench benchmarkv1alpha1.RedisBench
 	if err := r.Get(ctx, req.NamespacedName, &redisbench); err != nil {
@@ -64,11 +68,57 @@ func (r *RedisbenchReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	}
 	old := redisbench.DeepCopy()
 
+	// record the logs archived to a pvc once they are copied into the claim
+	archiving, err := utils.SyncArchivedLogs(r.Client, ctx, &redisbench, redisbench.Spec.LogArchive, &redisbench.Status.Logs)
+	if err != nil {
+		return intctrlutil.RequeueWithError(err, l, "unable to sync the archived logs")
+	}
+
 	if redisbench.Status.Phase == benchmarkv1alpha1.Completed || redisbench.Status.Phase == benchmarkv1alpha1.Failed {
-		return intctrlutil.Reconciled()
+		if err := r.Status().Patch(ctx, &redisbench, client.MergeFrom(old)); err != nil {
+			l.Error(err, "failed to patch redisbench status")
+			return intctrlutil.RequeueWithError(err, l, "failed to patch redisbench status")
+		}
+		if archiving {
+			return intctrlutil.RequeueAfter(intctrlutil.RequeueDuration)
+		}
+		// collect the jobs once the ttl expired
+		requeueAfter, err := utils.CollectFinished(r.Client, ctx, &redisbench, &redisbench.Spec.BenchCommon, redisbench.Status.CompletionTimestamp)
+		if err != nil {
+			return intctrlutil.RequeueWithError(err, l, "failed to collect the finished redisbench")
+		}
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+
+	if len(redisbench.Spec.Targets) > 0 {
+		phase, succeeded, err := utils.ReconcileTargets(r.Client, ctx, r.Scheme, &redisbench, redisbench.Spec.Targets, redisbench.Spec.TargetsPolicy, &redisbench.Status.Targets)
+		if err != nil {
+			l.Error(err, "failed to reconcile the targets", "redisbench", redisbench.Name)
+			return intctrlutil.RequeueWithError(err, l, "failed to reconcile the targets")
+		}
+		redisbench.Status.Phase = phase
+		redisbench.Status.Succeeded = succeeded
+		redisbench.Status.Total = len(redisbench.Spec.Targets)
+		redisbench.Status.Completions = fmt.Sprintf("%d/%d", redisbench.Status.Succeeded, redisbench.Status.Total)
+		if phase == benchmarkv1alpha1.Completed || phase == benchmarkv1alpha1.Failed {
+			redisbench.Status.CompletionTimestamp = &metav1.Time{Time: time.Now()}
+		}
+		utils.RecordPhaseTransition(r.Recorder, &redisbench, old.Status.Phase, redisbench.Status.Phase)
+		if err := r.Status().Patch(ctx, &redisbench, client.MergeFrom(old)); err != nil {
+			l.Error(err, "failed to patch redisbench status")
+			return intctrlutil.RequeueWithError(err, l, "failed to patch redisbench status")
+		}
+		return intctrlutil.RequeueAfter(intctrlutil.RequeueDuration)
+	}
+
+	resolved, err := utils.ResolveTarget(r.Client, ctx, redisbench.Namespace, redisbench.Spec.TargetRef, redisbench.Status.Target, &redisbench.Spec.Target)
+	if err != nil {
+		l.Error(err, "failed to resolve the target", "redisbench", redisbench.Name)
+		return intctrlutil.RequeueWithError(err, l, "failed to resolve the target")
 	}
+	redisbench.Status.Target = resolved
 
-	jobs := NewRedisBenchJobs(&redisbench)
+	jobs := utils.SetJobDeadlines(NewRedisBenchJobs(&redisbench), redisbench.Spec.Timeouts)
 
 	if redisbench.Status.Phase == "" {
 		l.Info("start redisbench", "redisbench", redisbench.Name)
@@ -79,6 +129,10 @@ func (r *RedisbenchReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	if redisbench.Status.Succeeded >= redisbench.Status.Total {
 		l.Info("redisbench complete", "redisbench", redisbench.Name)
 		redisbench.Status.Phase = benchmarkv1alpha1.Completed
+		if redisbench.Status.Reason != "" {
+			// a job failed and the afterAll hooks ran after it
+			redisbench.Status.Phase = benchmarkv1alpha1.Failed
+		}
 		redisbench.Status.CompletionTimestamp = &metav1.Time{Time: time.Now()}
 	} else {
 		job := jobs[redisbench.Status.Succeeded]
@@ -102,6 +156,7 @@ func (r *RedisbenchReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 
 			// wait for the job to be created
 			l.Info("created job", "job", job.Name)
+			utils.RecordJobCreated(r.Recorder, &redisbench, job, redisbench.Spec.Target.Password)
 			return intctrlutil.RequeueAfter(intctrlutil.RequeueDuration)
 		}
 
@@ -111,26 +166,66 @@ func (r *RedisbenchReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 			l.Error(err, "failed to get job status", "job", job.Name)
 			return intctrlutil.RequeueWithError(err, l, "failed to get job status")
 		}
+		// the nodes cordoned by the faults are schedulable again once the run job finished
+		if err := utils.UncordonNodes(r.Client, ctx, redisbench.Spec.Faults, job, status); err != nil {
+			return intctrlutil.RequeueWithError(err, l, "unable to uncordon the nodes")
+		}
 
 		if status.Succeeded > 0 {
 			l.Info("job completed", "job", job.Name)
+			utils.RecordJobFinished(r.Client, ctx, r.Recorder, &redisbench, job, true)
+			utils.ObserveJobFinished(r.Client, ctx, constants.RedisBenchType, jobs, redisbench.Status.Succeeded, true)
 			redisbench.Status.Succeeded++
 			// record the result
-			if err := utils.LogJobPodToCond(r.Client, r.RestConfig, ctx, job.Name, redisbench.Namespace, &redisbench.Status.Conditions, ParseRedisBench); err != nil {
+			if err := utils.RecordJobSummary(r.Client, r.RestConfig, ctx, job.Name, redisbench.Namespace, &redisbench.Status.Conditions, constants.RedisBenchType); err != nil {
 				return intctrlutil.RequeueWithError(err, l, "unable to record the log")
 			}
+			if err := utils.ArchiveJobLogs(r.Client, r.RestConfig, ctx, r.Scheme, &redisbench, redisbench.Spec.LogArchive, job.Name, &redisbench.Status.Logs); err != nil {
+				return intctrlutil.RequeueWithError(err, l, "unable to archive the log")
+			}
+		} else if step := utils.TimedOutStep(&redisbench, redisbench.Spec.Timeouts, job, status); step != "" {
+			if redisbench.Status.TimedOutStep != "" {
+				step = redisbench.Status.TimedOutStep
+			}
+			redisbench.Status.TimedOutStep = step
+			done, err := utils.StopTimedOutJob(r.Client, ctx, r.Recorder, &redisbench, constants.RedisBenchType, job, step)
+			if err != nil {
+				return intctrlutil.RequeueWithError(err, l, "unable to stop the job")
+			}
+			if done {
+				l.Info("job timed out", "job", job.Name, "step", step)
+				redisbench.Status.Phase = utils.FailJob(jobs, &redisbench.Status.Succeeded)
+				redisbench.Status.Reason = utils.TimedOutReason
+				if err := utils.LogJobPodToCond(r.Client, r.RestConfig, ctx, job.Name, redisbench.Namespace, &redisbench.Status.Conditions, nil); err != nil {
+					return intctrlutil.RequeueWithError(err, l, "unable to record the log")
+				}
+				if err := utils.ArchiveJobLogs(r.Client, r.RestConfig, ctx, r.Scheme, &redisbench, redisbench.Spec.LogArchive, job.Name, &redisbench.Status.Logs); err != nil {
+					return intctrlutil.RequeueWithError(err, l, "unable to archive the log")
+				}
+			}
 		} else if status.Failed > 0 {
 			l.Info("job failed", "job", job.Name)
-			redisbench.Status.Phase = benchmarkv1alpha1.Failed
+			utils.RecordJobFinished(r.Client, ctx, r.Recorder, &redisbench, job, false)
+			utils.ObserveJobFinished(r.Client, ctx, constants.RedisBenchType, jobs, redisbench.Status.Succeeded, false)
+			if redisbench.Status.Reason == "" {
+				redisbench.Status.Reason = utils.JobFailedReason
+			}
+			redisbench.Status.Phase = utils.FailJob(jobs, &redisbench.Status.Succeeded)
 			if err := utils.LogJobPodToCond(r.Client, r.RestConfig, ctx, job.Name, redisbench.Namespace, &redisbench.Status.Conditions, nil); err != nil {
 				return intctrlutil.RequeueWithError(err, l, "unable to record the log")
 			}
+			if err := utils.ArchiveJobLogs(r.Client, r.RestConfig, ctx, r.Scheme, &redisbench, redisbench.Spec.LogArchive, job.Name, &redisbench.Status.Logs); err != nil {
+				return intctrlutil.RequeueWithError(err, l, "unable to archive the log")
+			}
 		} else {
 			l.Info("job running", "job", job.Name)
+			utils.RecordJobStarted(r.Client, ctx, r.Recorder, &redisbench, job.Name, job.Namespace)
+			utils.InjectFaults(r.Client, ctx, r.Scheme, &redisbench, redisbench.Spec.Faults, job, status, &redisbench.Status.Faults)
 		}
 	}
 
 	redisbench.Status.Completions = fmt.Sprintf("%d/%d", redisbench.Status.Succeeded, redisbench.Status.Total)
+	utils.RecordPhaseTransition(r.Recorder, &redisbench, old.Status.Phase, redisbench.Status.Phase)
 	if err := r.Status().Patch(ctx, &redisbench, client.MergeFrom(old)); err != nil {
 		l.Error(err, "failed to patch redisbench status")
 		return intctrlutil.RequeueWithError(err, l, "failed to patch redisbench status")
@@ -145,19 +240,3 @@ func (r *RedisbenchReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		For(&benchmarkv1alpha1.RedisBench{}).
 		Complete(r)
 }
-
-func ParseRedisBench(msg string) string {
-	result := ""
-
-	lines := strings.Split(msg, "\r")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		// save the result query/sev value
-		if strings.Contains(line, "per second") {
-			result += fmt.Sprintf("%s\n", line)
-		}
-	}
-
-	return strings.TrimSpace(result)
-}
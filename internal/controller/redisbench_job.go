@@ -17,7 +17,10 @@ func NewRedisBenchJobs(cr *v1alpha1.RedisBench) []*batchv1.Job {
 
 	// add pre-check job
 	jobs = append(jobs, utils.NewPreCheckJob(cr.Name, cr.Namespace, constants.RedisDriver, &cr.Spec.Target))
-	jobs = append(jobs, NewRedisBenchRunJobs(cr)...)
+	jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.BeforeRunHook, cr.Spec.Hooks, &cr.Spec.Target)...)
+	jobs = append(jobs, utils.MarkRunJobs(NewRedisBenchRunJobs(cr))...)
+	jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.AfterRunHook, cr.Spec.Hooks, &cr.Spec.Target)...)
+	jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.AfterAllHook, cr.Spec.Hooks, &cr.Spec.Target)...)
 
 	// set tolerations for all jobs
 	utils.AddTolerationToJobs(jobs, cr.Spec.Tolerations)
@@ -0,0 +1,29 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/apecloud/kubebench/internal/utils"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+func TestNewSQLExecJobMountsScripts(t *testing.T) {
+	target := newVerifyTestTarget(constants.PostgreSqlDriver)
+	job := utils.NewSQLExecJob("pgbench-analyze", "default", &target, "bench", "analyze-sql", "--transaction", "file")
+
+	container := job.Spec.Template.Spec.Containers[0]
+	if !containsAll(container.Args, []string{"sql", "exec", "--driver", "postgresql", "--database", "bench", "--dir", utils.SQLScriptMountPath, "--transaction", "file"}) {
+		t.Fatalf("unexpected sql exec args: %#v", container.Args)
+	}
+	if !hasVolumeMount(container.VolumeMounts, "sql", utils.SQLScriptMountPath) {
+		t.Fatalf("expected sql scripts mount, got %#v", container.VolumeMounts)
+	}
+	if !hasVolume(job, "sql") {
+		t.Fatalf("expected sql scripts volume, got %#v", job.Spec.Template.Spec.Volumes)
+	}
+
+	target.Driver = constants.MongoDbDriver
+	if job := utils.NewSQLExecJob("ycsb-sql", "default", &target, "bench", "sql"); job != nil {
+		t.Fatalf("expected no sql job for mongodb, got %s", job.Name)
+	}
+}
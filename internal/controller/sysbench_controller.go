@@ -19,12 +19,12 @@ package controller
 import (
 	"context"
 	"fmt"
-	"strings"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -33,6 +33,7 @@ import (
 	benchmarkv1alpha1 "github.com/apecloud/kubebench/api/v1alpha1"
 	intctrlutil "github.com/apecloud/kubebench/internal/controllerutil"
 	"github.com/apecloud/kubebench/internal/utils"
+	"github.com/apecloud/kubebench/pkg/constants"
 )
 
 // SysbenchReconciler reconciles a Sysbench object
@@ -40,6 +41,7 @@ type SysbenchReconciler struct {
 	client.Client
 	Scheme     *runtime.Scheme
 	RestConfig *rest.Config
+	Recorder   record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=benchmark.apecloud.io,resources=sysbenches,verbs=get;list;watch;create;update;patch;delete
@@ -49,6 +51,12 @@ type SysbenchReconciler struct {
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete;deletecollection
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;delete;deletecollection
 // +kubebuilder:rbac:groups=core,resources=pods/log,verbs=get;list
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;patch
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;patch
+// +kubebuilder:rbac:groups=core,resources=services;secrets,verbs=get
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;create;update
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=apps.kubeblocks.io,resources=clusters,verbs=get
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -61,6 +69,8 @@ type SysbenchReconciler struct {
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.1controllerutil.RequeueDuration.0/pkg/reconcile
 func (r *SysbenchReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	l := log.FromContext(ctx)
+	// the summary and the archive of a finished job share its logs
+	ctx = utils.WithPodLogs(ctx)
 
 	var sysbench benchmarkv1alpha1.Sysbench
 	if err := r.Get(ctx, req.NamespacedName, &sysbench); err != nil {
@@ -68,12 +78,58 @@ func (r *SysbenchReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	}
 	old := sysbench.DeepCopy()
 
+	// record the logs archived to a pvc once they are copied into the claim
+	archiving, err := utils.SyncArchivedLogs(r.Client, ctx, &sysbench, sysbench.Spec.LogArchive, &sysbench.Status.Logs)
+	if err != nil {
+		return intctrlutil.RequeueWithError(err, l, "unable to sync the archived logs")
+	}
+
 	// Run to one completion
 	if sysbench.Status.Phase == benchmarkv1alpha1.Completed || sysbench.Status.Phase == benchmarkv1alpha1.Failed {
-		return intctrlutil.Reconciled()
+		if err := r.Status().Patch(ctx, &sysbench, client.MergeFrom(old)); err != nil {
+			l.Error(err, "failed to patch sysbench status")
+			return intctrlutil.RequeueWithError(err, l, "failed to patch sysbench status")
+		}
+		if archiving {
+			return intctrlutil.RequeueAfter(intctrlutil.RequeueDuration)
+		}
+		// collect the jobs once the ttl expired
+		requeueAfter, err := utils.CollectFinished(r.Client, ctx, &sysbench, &sysbench.Spec.BenchCommon, sysbench.Status.CompletionTimestamp)
+		if err != nil {
+			return intctrlutil.RequeueWithError(err, l, "failed to collect the finished sysbench")
+		}
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
 	}
 
-	jobs := NewSysbenchJobs(&sysbench)
+	if len(sysbench.Spec.Targets) > 0 {
+		phase, succeeded, err := utils.ReconcileTargets(r.Client, ctx, r.Scheme, &sysbench, sysbench.Spec.Targets, sysbench.Spec.TargetsPolicy, &sysbench.Status.Targets)
+		if err != nil {
+			l.Error(err, "failed to reconcile the targets", "sysbench", sysbench.Name)
+			return intctrlutil.RequeueWithError(err, l, "failed to reconcile the targets")
+		}
+		sysbench.Status.Phase = phase
+		sysbench.Status.Succeeded = succeeded
+		sysbench.Status.Total = len(sysbench.Spec.Targets)
+		sysbench.Status.Completions = fmt.Sprintf("%d/%d", sysbench.Status.Succeeded, sysbench.Status.Total)
+		if phase == benchmarkv1alpha1.Completed || phase == benchmarkv1alpha1.Failed {
+			sysbench.Status.CompletionTimestamp = &metav1.Time{Time: time.Now()}
+		}
+		utils.RecordPhaseTransition(r.Recorder, &sysbench, old.Status.Phase, sysbench.Status.Phase)
+		if err := r.Status().Patch(ctx, &sysbench, client.MergeFrom(old)); err != nil {
+			l.Error(err, "failed to patch sysbench status")
+			return intctrlutil.RequeueWithError(err, l, "failed to patch sysbench status")
+		}
+		return intctrlutil.RequeueAfter(intctrlutil.RequeueDuration)
+	}
+
+	resolved, err := utils.ResolveTarget(r.Client, ctx, sysbench.Namespace, sysbench.Spec.TargetRef, sysbench.Status.Target, &sysbench.Spec.Target)
+	if err != nil {
+		l.Error(err, "failed to resolve the target", "sysbench", sysbench.Name)
+		return intctrlutil.RequeueWithError(err, l, "failed to resolve the target")
+	}
+	sysbench.Status.Target = resolved
+
+	jobs := utils.SetJobDeadlines(NewSysbenchJobs(&sysbench), sysbench.Spec.Timeouts)
 
 	if sysbench.Status.Phase == "" {
 		l.Info("start sysbench", "sysbench", sysbench.Name)
@@ -83,6 +139,10 @@ func (r *SysbenchReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 
 	if sysbench.Status.Succeeded >= sysbench.Status.Total {
 		sysbench.Status.Phase = benchmarkv1alpha1.Completed
+		if sysbench.Status.Reason != "" {
+			// a job failed and the afterAll hooks ran after it
+			sysbench.Status.Phase = benchmarkv1alpha1.Failed
+		}
 		sysbench.Status.CompletionTimestamp = &metav1.Time{Time: time.Now()}
 	} else {
 		job := jobs[sysbench.Status.Succeeded]
@@ -106,6 +166,7 @@ func (r *SysbenchReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 
 			// wait for the job to be created
 			l.Info("created job", "job", job.Name)
+			utils.RecordJobCreated(r.Recorder, &sysbench, job, sysbench.Spec.Target.Password)
 			return intctrlutil.RequeueAfter(intctrlutil.RequeueDuration)
 		}
 
@@ -115,26 +176,66 @@ func (r *SysbenchReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 			l.Error(err, "failed to get job status", "job", job.Name)
 			return intctrlutil.RequeueWithError(err, l, "failed to get job status")
 		}
+		// the nodes cordoned by the faults are schedulable again once the run job finished
+		if err := utils.UncordonNodes(r.Client, ctx, sysbench.Spec.Faults, job, status); err != nil {
+			return intctrlutil.RequeueWithError(err, l, "unable to uncordon the nodes")
+		}
 
 		if status.Succeeded > 0 {
 			l.Info("job completed", "job", job.Name)
+			utils.RecordJobFinished(r.Client, ctx, r.Recorder, &sysbench, job, true)
+			utils.ObserveJobFinished(r.Client, ctx, constants.SysbenchType, jobs, sysbench.Status.Succeeded, true)
 			sysbench.Status.Succeeded++
 			// record the result
-			if err := utils.LogJobPodToCond(r.Client, r.RestConfig, ctx, job.Name, sysbench.Namespace, &sysbench.Status.Conditions, ParseSysBench); err != nil {
+			if err := utils.RecordJobSummary(r.Client, r.RestConfig, ctx, job.Name, sysbench.Namespace, &sysbench.Status.Conditions, constants.SysbenchType); err != nil {
 				return intctrlutil.RequeueWithError(err, l, "unable to record the log")
 			}
+			if err := utils.ArchiveJobLogs(r.Client, r.RestConfig, ctx, r.Scheme, &sysbench, sysbench.Spec.LogArchive, job.Name, &sysbench.Status.Logs); err != nil {
+				return intctrlutil.RequeueWithError(err, l, "unable to archive the log")
+			}
+		} else if step := utils.TimedOutStep(&sysbench, sysbench.Spec.Timeouts, job, status); step != "" {
+			if sysbench.Status.TimedOutStep != "" {
+				step = sysbench.Status.TimedOutStep
+			}
+			sysbench.Status.TimedOutStep = step
+			done, err := utils.StopTimedOutJob(r.Client, ctx, r.Recorder, &sysbench, constants.SysbenchType, job, step)
+			if err != nil {
+				return intctrlutil.RequeueWithError(err, l, "unable to stop the job")
+			}
+			if done {
+				l.Info("job timed out", "job", job.Name, "step", step)
+				sysbench.Status.Phase = utils.FailJob(jobs, &sysbench.Status.Succeeded)
+				sysbench.Status.Reason = utils.TimedOutReason
+				if err := utils.LogJobPodToCond(r.Client, r.RestConfig, ctx, job.Name, sysbench.Namespace, &sysbench.Status.Conditions, nil); err != nil {
+					return intctrlutil.RequeueWithError(err, l, "unable to record the log")
+				}
+				if err := utils.ArchiveJobLogs(r.Client, r.RestConfig, ctx, r.Scheme, &sysbench, sysbench.Spec.LogArchive, job.Name, &sysbench.Status.Logs); err != nil {
+					return intctrlutil.RequeueWithError(err, l, "unable to archive the log")
+				}
+			}
 		} else if status.Failed > 0 {
 			l.Info("job failed", "job", job.Name)
-			sysbench.Status.Phase = benchmarkv1alpha1.Failed
+			utils.RecordJobFinished(r.Client, ctx, r.Recorder, &sysbench, job, false)
+			utils.ObserveJobFinished(r.Client, ctx, constants.SysbenchType, jobs, sysbench.Status.Succeeded, false)
+			if sysbench.Status.Reason == "" {
+				sysbench.Status.Reason = utils.JobFailedReason
+			}
+			sysbench.Status.Phase = utils.FailJob(jobs, &sysbench.Status.Succeeded)
 			if err := utils.LogJobPodToCond(r.Client, r.RestConfig, ctx, job.Name, sysbench.Namespace, &sysbench.Status.Conditions, nil); err != nil {
 				return intctrlutil.RequeueWithError(err, l, "unable to record the log")
 			}
+			if err := utils.ArchiveJobLogs(r.Client, r.RestConfig, ctx, r.Scheme, &sysbench, sysbench.Spec.LogArchive, job.Name, &sysbench.Status.Logs); err != nil {
+				return intctrlutil.RequeueWithError(err, l, "unable to archive the log")
+			}
 		} else {
 			l.Info("job is running", "job", job.Name)
+			utils.RecordJobStarted(r.Client, ctx, r.Recorder, &sysbench, job.Name, job.Namespace)
+			utils.InjectFaults(r.Client, ctx, r.Scheme, &sysbench, sysbench.Spec.Faults, job, status, &sysbench.Status.Faults)
 		}
 	}
 
 	sysbench.Status.Completions = fmt.Sprintf("%d/%d", sysbench.Status.Succeeded, sysbench.Status.Total)
+	utils.RecordPhaseTransition(r.Recorder, &sysbench, old.Status.Phase, sysbench.Status.Phase)
 	if err := r.Status().Patch(ctx, &sysbench, client.MergeFrom(old)); err != nil {
 		l.Error(err, "failed to patch sysbench status")
 		return intctrlutil.RequeueWithError(err, l, "failed to patch sysbench status")
@@ -149,27 +250,3 @@ func (r *SysbenchReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		For(&benchmarkv1alpha1.Sysbench{}).
 		Complete(r)
 }
-
-func ParseSysBench(msg string) string {
-	result := ""
-	lines := strings.Split(msg, "\n")
-	index := len(lines)
-
-	for i, l := range lines {
-		if strings.Contains(l, "SQL statistics") {
-			index = i
-			result += fmt.Sprintf("%s\n", l)
-			break
-		}
-	}
-
-	for i := index + 1; i < len(lines); i++ {
-		if lines[i] != "" {
-			// align the output
-			result += fmt.Sprintf("%*s\n", len(lines[i])+27, lines[i])
-		}
-	}
-
-	// delete the last \n
-	return strings.TrimSpace(result)
-}
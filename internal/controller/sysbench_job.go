@@ -2,6 +2,7 @@ package controller
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	batchv1 "k8s.io/api/batch/v1"
@@ -12,6 +13,15 @@ import (
 	"github.com/apecloud/kubebench/pkg/constants"
 )
 
+const (
+	sysbenchScriptsVolume = "scripts"
+	sysbenchScriptsPath   = "/etc/kubebench/sysbench"
+
+	// the dir the packaged sysbench of the image (/usr/bin/sysbench) looks
+	// up the tests in, infratest.py passes every type to it as the test name
+	sysbenchLuaDir = "/usr/share/sysbench"
+)
+
 func NewSysbenchJobs(cr *v1alpha1.Sysbench) []*batchv1.Job {
 	jobs := make([]*batchv1.Job, 0)
 
@@ -22,14 +32,20 @@ func NewSysbenchJobs(cr *v1alpha1.Sysbench) []*batchv1.Job {
 
 	step := cr.Spec.Step
 	if step == constants.CleanupStep || step == constants.AllStep {
-		jobs = append(jobs, NewSysbenchCleanupJobs(cr)...)
+		jobs = append(jobs, utils.MarkStepJobs(NewSysbenchCleanupJobs(cr), constants.CleanupStep)...)
 	}
 	if step == constants.PrepareStep || step == constants.AllStep {
-		jobs = append(jobs, NewSysbenchPrepareJobs(cr)...)
+		jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.BeforePrepareHook, cr.Spec.Hooks, &cr.Spec.Target)...)
+		jobs = append(jobs, utils.MarkStepJobs(NewSysbenchPrepareJobs(cr), constants.PrepareStep)...)
+		jobs = append(jobs, utils.MarkStepJobs(NewSysbenchVerifyJobs(cr), constants.PrepareStep)...)
+		jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.AfterPrepareHook, cr.Spec.Hooks, &cr.Spec.Target)...)
 	}
 	if step == constants.RunStep || step == constants.AllStep {
-		jobs = append(jobs, NewSysbenchRunJobs(cr)...)
+		jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.BeforeRunHook, cr.Spec.Hooks, &cr.Spec.Target)...)
+		jobs = append(jobs, utils.MarkRunJobs(NewSysbenchRunJobs(cr))...)
+		jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.AfterRunHook, cr.Spec.Hooks, &cr.Spec.Target)...)
 	}
+	jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.AfterAllHook, cr.Spec.Hooks, &cr.Spec.Target)...)
 
 	// set tolerations for all jobs
 	utils.AddTolerationToJobs(jobs, cr.Spec.Tolerations)
@@ -73,7 +89,7 @@ func NewSysbenchCleanupJobs(cr *v1alpha1.Sysbench) []*batchv1.Job {
 			Image:           constants.GetBenchmarkImage(constants.KubebenchEnvSysbench),
 			ImagePullPolicy: corev1.PullIfNotPresent,
 			Command:         []string{"/bin/sh", "-c"},
-			Args:            []string{"python3 -u infratest.py -t \"$TYPE\" -f \"${FLAG}\" -c \"${CONFIGS}\" -j \"${JSONS}\" 2>&1 | tee -a /var/log/sysbench.log"},
+			Args:            []string{sysbenchScriptsCommand(cr, "python3 -u infratest.py -t \"$TYPE\" -f \"${FLAG}\" -c \"${CONFIGS}\" -j \"${JSONS}\" 2>&1 | tee -a /var/log/sysbench.log")},
 			Env: []corev1.EnvVar{
 				{
 					Name:  "TYPE",
@@ -96,6 +112,7 @@ func NewSysbenchCleanupJobs(cr *v1alpha1.Sysbench) []*batchv1.Job {
 			},
 		},
 	)
+	addSysbenchScriptsVolume(cr, job)
 
 	return []*batchv1.Job{job}
 }
@@ -125,7 +142,7 @@ func NewSysbenchPrepareJobs(cr *v1alpha1.Sysbench) []*batchv1.Job {
 			Image:           constants.GetBenchmarkImage(constants.KubebenchEnvSysbench),
 			ImagePullPolicy: corev1.PullIfNotPresent,
 			Command:         []string{"/bin/sh", "-c"},
-			Args:            []string{"python3 -u infratest.py -t \"$TYPE\" -f \"${FLAG}\" -c \"${CONFIGS}\" -j \"${JSONS}\" | tee /var/log/sysbench.log"},
+			Args:            []string{sysbenchScriptsCommand(cr, "python3 -u infratest.py -t \"$TYPE\" -f \"${FLAG}\" -c \"${CONFIGS}\" -j \"${JSONS}\" | tee /var/log/sysbench.log")},
 			Env: []corev1.EnvVar{
 				{
 					Name:  "TYPE",
@@ -148,7 +165,37 @@ func NewSysbenchPrepareJobs(cr *v1alpha1.Sysbench) []*batchv1.Job {
 			},
 		},
 	)
+	addSysbenchScriptsVolume(cr, job)
+
+	// add init containers to create database for prepare job
+	if initContainer := SysbenchInitContainers(cr); initContainer != nil {
+		job.Spec.Template.Spec.InitContainers = append(job.Spec.Template.Spec.InitContainers, *initContainer)
+	}
+
+	return []*batchv1.Job{job}
+}
 
+// NewSysbenchVerifyJobs checks that prepare created every sbtest table with
+// the configured size, the prepare job may succeed with partial data. Only the
+// tests sharing the tables of oltp_common are verified, prepare runs the first
+// type and a custom Lua script or bulk_insert creates tables of its own.
+func NewSysbenchVerifyJobs(cr *v1alpha1.Sysbench) []*batchv1.Job {
+	if cr.Spec.Tables <= 0 || cr.Spec.Size <= 0 {
+		return nil
+	}
+	if len(cr.Spec.Types) == 0 || !sysbenchOltpCommonTest(cr.Spec.Types[0]) {
+		return nil
+	}
+
+	expect := make(map[string]int64, cr.Spec.Tables)
+	for i := 1; i <= cr.Spec.Tables; i++ {
+		expect[fmt.Sprintf("sbtest%d", i)] = int64(cr.Spec.Size)
+	}
+
+	job := utils.NewVerifyJob(cr.Name, cr.Namespace, cr.Spec.Target.Driver, &cr.Spec.Target, cr.Spec.Target.Database, expect)
+	if job == nil {
+		return nil
+	}
 	return []*batchv1.Job{job}
 }
 
@@ -162,18 +209,47 @@ func NewSysbenchRunJobs(cr *v1alpha1.Sysbench) []*batchv1.Job {
 	value = fmt.Sprintf("%s,db:%s", value, cr.Spec.Target.Database)
 	value = fmt.Sprintf("%s,tables:%d", value, cr.Spec.Tables)
 	value = fmt.Sprintf("%s,size:%d", value, cr.Spec.Size)
+	warmup := utils.WarmupSeconds(cr.Spec.Warmup, cr.Spec.Duration)
+	warmupValue := fmt.Sprintf("%s,times:%d", value, warmup)
 	value = fmt.Sprintf("%s,times:%d", value, cr.Spec.Duration)
 
+	// the measured pass prints the latency histogram for the exporter
+	others := append([]string{"--histogram=on", fmt.Sprintf("--percentile=%d", getSysbenchPercentile(cr.Spec.Percentile))}, cr.Spec.ExtraArgs...)
+
 	// TODO add func to parse extra args
-	value = fmt.Sprintf("%s,others:%s", value, strings.Join(cr.Spec.ExtraArgs, " "))
+	value = fmt.Sprintf("%s,others:%s", value, strings.Join(others, " "))
+	warmupValue = fmt.Sprintf("%s,others:%s", warmupValue, strings.Join(cr.Spec.ExtraArgs, " "))
 
 	jobs := make([]*batchv1.Job, 0)
 	for i := 0; i < len(cr.Spec.Threads)*len(cr.Spec.Types); i++ {
-		curValue := fmt.Sprintf("%s,threads:%d", value, cr.Spec.Threads[i/len(cr.Spec.Types)])
-		curValue = fmt.Sprintf("%s,type:%s", curValue, cr.Spec.Types[i%len(cr.Spec.Types)])
+		threadsAndType := fmt.Sprintf(",threads:%d,type:%s", cr.Spec.Threads[i/len(cr.Spec.Types)], cr.Spec.Types[i%len(cr.Spec.Types)])
+		curValue := value + threadsAndType
 		jobName := fmt.Sprintf("%s-run-%d", cr.Name, i)
 		curJob := utils.JobTemplate(jobName, cr.Namespace)
 
+		cmd := "python3 -u infratest.py -t \"$TYPE\" -f \"${FLAG}\" -c \"${CONFIGS}\" -j \"${JSONS}\" | tee /var/log/sysbench.log"
+		env := []corev1.EnvVar{
+			{
+				Name:  "TYPE",
+				Value: "2",
+			},
+			{
+				Name:  "FLAG",
+				Value: "0",
+			},
+			{
+				Name:  "CONFIGS",
+				Value: curValue,
+			},
+		}
+		if warmup > 0 {
+			// the warmup pass runs the same test for the warmup time first,
+			// the measured pass appends to its log
+			warmupCmd := "python3 -u infratest.py -t \"$TYPE\" -f \"${FLAG}\" -c \"${WARMUP_CONFIGS}\" -j \"${JSONS}\""
+			cmd = utils.WarmupCommand(warmupCmd, "/var/log/sysbench.log") + strings.Replace(cmd, "| tee ", "| tee -a ", 1)
+			env = append(env, corev1.EnvVar{Name: "WARMUP_CONFIGS", Value: warmupValue + threadsAndType})
+		}
+
 		curJob.Spec.Template.Spec.Containers = append(
 			curJob.Spec.Template.Spec.Containers,
 			corev1.Container{
@@ -181,21 +257,8 @@ func NewSysbenchRunJobs(cr *v1alpha1.Sysbench) []*batchv1.Job {
 				Image:           constants.GetBenchmarkImage(constants.KubebenchEnvSysbench),
 				ImagePullPolicy: corev1.PullIfNotPresent,
 				Command:         []string{"/bin/sh", "-c"},
-				Args:            []string{"python3 -u infratest.py -t \"$TYPE\" -f \"${FLAG}\" -c \"${CONFIGS}\" -j \"${JSONS}\" | tee /var/log/sysbench.log"},
-				Env: []corev1.EnvVar{
-					{
-						Name:  "TYPE",
-						Value: "2",
-					},
-					{
-						Name:  "FLAG",
-						Value: "0",
-					},
-					{
-						Name:  "CONFIGS",
-						Value: curValue,
-					},
-				},
+				Args:            []string{sysbenchScriptsCommand(cr, cmd)},
+				Env:             env,
 				VolumeMounts: []corev1.VolumeMount{
 					{
 						Name:      "log",
@@ -204,29 +267,16 @@ func NewSysbenchRunJobs(cr *v1alpha1.Sysbench) []*batchv1.Job {
 				},
 			},
 		)
+		addSysbenchScriptsVolume(cr, curJob)
 
+		exporterArgs := append(utils.ExporterArgs(cr.Labels, &cr.Spec.BenchCommon), utils.ExporterParamArgs(map[string]string{
+			"threads": strconv.Itoa(cr.Spec.Threads[i/len(cr.Spec.Types)]),
+			"type":    cr.Spec.Types[i%len(cr.Spec.Types)],
+		})...)
+		exporterArgs = append(exporterArgs, utils.ExporterDurationArgs(warmup+cr.Spec.Duration)...)
 		curJob.Spec.Template.Spec.Containers = append(
 			curJob.Spec.Template.Spec.Containers,
-			corev1.Container{
-				Name:            "metrics",
-				Image:           constants.GetBenchmarkImage(constants.KubebenchExporter),
-				ImagePullPolicy: corev1.PullIfNotPresent,
-				Ports: []corev1.ContainerPort{
-					{
-						ContainerPort: 9187,
-						Name:          "http-metrics",
-						Protocol:      corev1.ProtocolTCP,
-					},
-				},
-				Command: []string{"/exporter"},
-				Args:    []string{"-type", "sysbench", "-file", "/var/log/sysbench.log", "-bench", cr.Name, "-job", jobName},
-				VolumeMounts: []corev1.VolumeMount{
-					{
-						Name:      "log",
-						MountPath: "/var/log",
-					},
-				},
-			},
+			utils.NewExporterContainer(constants.SysbenchType, "/var/log/sysbench.log", cr.Name, jobName, exporterArgs...),
 		)
 
 		jobs = append(jobs, curJob)
@@ -235,6 +285,72 @@ func NewSysbenchRunJobs(cr *v1alpha1.Sysbench) []*batchv1.Job {
 	return jobs
 }
 
+// sysbenchScriptsCommand copies the Lua scripts of the ConfigMaps next to the
+// built-in tests before cmd, so the script <name>.lua runs as the type <name>
+func sysbenchScriptsCommand(cr *v1alpha1.Sysbench, cmd string) string {
+	if len(cr.Spec.Scripts) == 0 {
+		return cmd
+	}
+	return fmt.Sprintf("cp %s/*.lua %s/ && %s", sysbenchScriptsPath, sysbenchLuaDir, cmd)
+}
+
+// addSysbenchScriptsVolume mounts the ConfigMaps of the scripts into the
+// first container of the job, all of them in one directory
+func addSysbenchScriptsVolume(cr *v1alpha1.Sysbench, job *batchv1.Job) {
+	if len(cr.Spec.Scripts) == 0 {
+		return
+	}
+
+	sources := make([]corev1.VolumeProjection, 0, len(cr.Spec.Scripts))
+	for _, name := range cr.Spec.Scripts {
+		sources = append(sources, corev1.VolumeProjection{
+			ConfigMap: &corev1.ConfigMapProjection{
+				LocalObjectReference: corev1.LocalObjectReference{Name: name},
+			},
+		})
+	}
+	job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, corev1.Volume{
+		Name: sysbenchScriptsVolume,
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{Sources: sources},
+		},
+	})
+	container := &job.Spec.Template.Spec.Containers[0]
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      sysbenchScriptsVolume,
+		MountPath: sysbenchScriptsPath,
+		ReadOnly:  true,
+	})
+}
+
+// getSysbenchPercentile returns the latency percentile sysbench reports,
+// the benchmarks created before it was configurable report the 99th
+func getSysbenchPercentile(percentile int) int {
+	if percentile <= 0 {
+		return 99
+	}
+	return percentile
+}
+
+// SysbenchInitContainers returns the init containers for sysbench
+// sysbench will fail if database not exists, so we need to create database first.
+// sysbench only speaks mysql and pgsql, mssql, dameng and oceanbase-oracle
+// targets are not supported and get nothing
+func SysbenchInitContainers(cr *v1alpha1.Sysbench) *corev1.Container {
+	switch cr.Spec.Target.Driver {
+	case constants.MySqlDriver:
+		return utils.InitMysqlDatabaseContainer(cr.Spec.Target, cr.Spec.Target.Database)
+	case constants.PostgreSqlDriver:
+		return utils.InitPGDatabaseContainer(cr.Spec.Target, cr.Spec.Target.Database)
+	case constants.GaussDBDriver:
+		return utils.InitGaussdbDatabaseContainer(cr.Spec.Target, cr.Spec.Target.Database)
+	case constants.TidbDriver:
+		return utils.InitTidbDatabaseContainer(cr.Spec.Target, cr.Spec.Target.Database)
+	default:
+		return nil
+	}
+}
+
 // getSysbenchDriver returns the database type required by sysbench
 func getSysbenchDriver(driver string) string {
 	switch driver {
@@ -244,7 +360,16 @@ func getSysbenchDriver(driver string) string {
 		return "pgsql"
 	case constants.GaussDBDriver:
 		return "mysql"
+	case constants.TidbDriver:
+		// tidb is mysql compatible
+		return "mysql"
 	default:
 		return driver
 	}
 }
+
+// sysbenchOltpCommonTest reports whether the built-in test prepares the sbtest
+// tables of oltp_common
+func sysbenchOltpCommonTest(test string) bool {
+	return strings.HasPrefix(test, "oltp_") || strings.HasPrefix(test, "select_random_")
+}
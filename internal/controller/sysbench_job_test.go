@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	benchmarkv1alpha1 "github.com/apecloud/kubebench/api/v1alpha1"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+func TestNewSysbenchRunJobsHistogram(t *testing.T) {
+	cr := &benchmarkv1alpha1.Sysbench{
+		ObjectMeta: metav1.ObjectMeta{Name: "sb", Namespace: "default"},
+		Spec: benchmarkv1alpha1.SysbenchSpec{
+			Duration: 60,
+			Threads:  []int{4},
+			Types:    []string{"oltp_read_write"},
+			BenchCommon: benchmarkv1alpha1.BenchCommon{
+				ExtraArgs: []string{"--rand-type=uniform"},
+				Target:    newVerifyTestTarget(constants.MySqlDriver),
+			},
+		},
+	}
+
+	configs := envValue(NewSysbenchRunJobs(cr)[0], "CONFIGS")
+	if !strings.Contains(configs, "others:--histogram=on --percentile=99 --rand-type=uniform,") {
+		t.Fatalf("expected the histogram and the default percentile ahead of the extra args: %s", configs)
+	}
+
+	cr.Spec.Percentile = 95
+	configs = envValue(NewSysbenchRunJobs(cr)[0], "CONFIGS")
+	if !strings.Contains(configs, "--percentile=95") {
+		t.Fatalf("expected the configured percentile: %s", configs)
+	}
+
+	for _, job := range []*batchv1.Job{NewSysbenchCleanupJobs(cr)[0], NewSysbenchPrepareJobs(cr)[0]} {
+		if configs := envValue(job, "CONFIGS"); strings.Contains(configs, "--histogram") || strings.Contains(configs, "--percentile") {
+			t.Fatalf("expected the histogram only in the run job, got %s: %s", job.Name, configs)
+		}
+	}
+}
+
+func TestNewSysbenchJobsScripts(t *testing.T) {
+	cr := &benchmarkv1alpha1.Sysbench{
+		ObjectMeta: metav1.ObjectMeta{Name: "sb", Namespace: "default"},
+		Spec: benchmarkv1alpha1.SysbenchSpec{
+			Duration: 60,
+			Threads:  []int{4, 8},
+			Types:    []string{"app_checkout", "oltp_read_write"},
+			Scripts:  []string{"checkout-lua", "search-lua"},
+			BenchCommon: benchmarkv1alpha1.BenchCommon{
+				Target: newVerifyTestTarget(constants.MySqlDriver),
+			},
+		},
+	}
+
+	jobs := append(NewSysbenchPrepareJobs(cr), NewSysbenchRunJobs(cr)...)
+	if len(jobs) != 5 {
+		t.Fatalf("expected a prepare job and 4 run jobs, got %d", len(jobs))
+	}
+	for _, job := range jobs {
+		spec := job.Spec.Template.Spec
+		var sources []string
+		for _, volume := range spec.Volumes {
+			if volume.Name == sysbenchScriptsVolume && volume.Projected != nil {
+				for _, source := range volume.Projected.Sources {
+					sources = append(sources, source.ConfigMap.Name)
+				}
+			}
+		}
+		if strings.Join(sources, ",") != "checkout-lua,search-lua" {
+			t.Fatalf("%s: expected the ConfigMaps of the scripts in one volume, got %v", job.Name, sources)
+		}
+		mounted := false
+		for _, mount := range spec.Containers[0].VolumeMounts {
+			mounted = mounted || (mount.Name == sysbenchScriptsVolume && mount.MountPath == sysbenchScriptsPath && mount.ReadOnly)
+		}
+		if !mounted {
+			t.Fatalf("%s: scripts are not mounted: %#v", job.Name, spec.Containers[0].VolumeMounts)
+		}
+		if cmd := spec.Containers[0].Args[0]; !strings.HasPrefix(cmd, "cp /etc/kubebench/sysbench/*.lua /usr/share/sysbench/ && ") {
+			t.Fatalf("%s: expected the scripts to be copied first: %s", job.Name, cmd)
+		}
+	}
+
+	// the scripts are types of the threads x types matrix
+	if configs := envValue(jobs[1], "CONFIGS"); !strings.HasSuffix(configs, ",threads:4,type:app_checkout") {
+		t.Fatalf("expected the script as the type of the first run: %s", configs)
+	}
+	if got := metricsContainerArg(jobs[1], "-file"); got != "/var/log/sysbench.log" {
+		t.Fatalf("expected the sysbench exporter to parse the script output, got %s", got)
+	}
+
+	cr.Spec.Scripts = nil
+	job := NewSysbenchRunJobs(cr)[0]
+	if len(job.Spec.Template.Spec.Volumes) != 1 || strings.HasPrefix(job.Spec.Template.Spec.Containers[0].Args[0], "cp ") {
+		t.Fatalf("expected no scripts without ConfigMaps: %#v", job.Spec.Template.Spec.Volumes)
+	}
+}
@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	benchmarkv1alpha1 "github.com/apecloud/kubebench/api/v1alpha1"
+	"github.com/apecloud/kubebench/internal/utils"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+func TestSetJobDeadlines(t *testing.T) {
+	cr := &benchmarkv1alpha1.Sysbench{
+		ObjectMeta: metav1.ObjectMeta{Name: "sb", Namespace: "default"},
+		Spec: benchmarkv1alpha1.SysbenchSpec{
+			Threads: []int{4},
+			Types:   []string{"oltp_read_write"},
+			BenchCommon: benchmarkv1alpha1.BenchCommon{
+				Step:   constants.AllStep,
+				Target: newVerifyTestTarget(constants.MySqlDriver),
+			},
+		},
+	}
+	timeouts := &benchmarkv1alpha1.Timeouts{PreCheckSeconds: 30, PrepareSeconds: 600, RunSeconds: 300}
+
+	for _, job := range utils.SetJobDeadlines(NewSysbenchJobs(cr), timeouts) {
+		deadline := job.Spec.ActiveDeadlineSeconds
+		switch utils.JobStep(job) {
+		case constants.PreCheckStep, constants.PrepareStep, constants.RunStep:
+			if deadline == nil || int(*deadline) != utils.StepTimeout(timeouts, utils.JobStep(job)) {
+				t.Fatalf("unexpected deadline of %s: %v", job.Name, deadline)
+			}
+		default:
+			if deadline != nil {
+				t.Fatalf("expected no deadline for %s, got %d", job.Name, *deadline)
+			}
+		}
+	}
+}
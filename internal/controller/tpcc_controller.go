@@ -19,12 +19,12 @@ package controller
 import (
 	"context"
 	"fmt"
-	"strings"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -33,6 +33,7 @@ import (
 	benchmarkv1alpha1 "github.com/apecloud/kubebench/api/v1alpha1"
 	intctrlutil "github.com/apecloud/kubebench/internal/controllerutil"
 	"github.com/apecloud/kubebench/internal/utils"
+	"github.com/apecloud/kubebench/pkg/constants"
 )
 
 // TpccReconciler reconciles a Tpcc object
@@ -40,6 +41,7 @@ type TpccReconciler struct {
 	client.Client
 	Scheme     *runtime.Scheme
 	RestConfig *rest.Config
+	Recorder   record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=benchmark.apecloud.io,resources=tpccs,verbs=get;list;watch;create;update;patch;delete
@@ -57,6 +59,8 @@ type TpccReconciler struct {
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.15.0/pkg/reconcile
 func (r *TpccReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	l := log.FromContext(ctx)
+	// the summary and the archive of a finished job share its logs
+	ctx = utils.WithPodLogs(ctx)
 
 	var tpcc benchmarkv1alpha1.Tpcc
 	if err := r.Get(ctx, req.NamespacedName, &tpcc); err != nil {
@@ -64,11 +68,57 @@ func (r *TpccReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 	}
 	old := tpcc.DeepCopy()
 
+	// record the logs archived to a pvc once they are copied into the claim
+	archiving, err := utils.SyncArchivedLogs(r.Client, ctx, &tpcc, tpcc.Spec.LogArchive, &tpcc.Status.Logs)
+	if err != nil {
+		return intctrlutil.RequeueWithError(err, l, "unable to sync the archived logs")
+	}
+
 	if tpcc.Status.Phase == benchmarkv1alpha1.Completed || tpcc.Status.Phase == benchmarkv1alpha1.Failed {
-		return intctrlutil.Reconciled()
+		if err := r.Status().Patch(ctx, &tpcc, client.MergeFrom(old)); err != nil {
+			l.Error(err, "failed to patch tpcc status")
+			return intctrlutil.RequeueWithError(err, l, "failed to patch tpcc status")
+		}
+		if archiving {
+			return intctrlutil.RequeueAfter(intctrlutil.RequeueDuration)
+		}
+		// collect the jobs once the ttl expired
+		requeueAfter, err := utils.CollectFinished(r.Client, ctx, &tpcc, &tpcc.Spec.BenchCommon, tpcc.Status.CompletionTimestamp)
+		if err != nil {
+			return intctrlutil.RequeueWithError(err, l, "failed to collect the finished tpcc")
+		}
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
 	}
 
-	jobs := NewTpccJobs(&tpcc)
+	if len(tpcc.Spec.Targets) > 0 {
+		phase, succeeded, err := utils.ReconcileTargets(r.Client, ctx, r.Scheme, &tpcc, tpcc.Spec.Targets, tpcc.Spec.TargetsPolicy, &tpcc.Status.Targets)
+		if err != nil {
+			l.Error(err, "failed to reconcile the targets", "tpcc", tpcc.Name)
+			return intctrlutil.RequeueWithError(err, l, "failed to reconcile the targets")
+		}
+		tpcc.Status.Phase = phase
+		tpcc.Status.Succeeded = succeeded
+		tpcc.Status.Total = len(tpcc.Spec.Targets)
+		tpcc.Status.Completions = fmt.Sprintf("%d/%d", tpcc.Status.Succeeded, tpcc.Status.Total)
+		if phase == benchmarkv1alpha1.Completed || phase == benchmarkv1alpha1.Failed {
+			tpcc.Status.CompletionTimestamp = &metav1.Time{Time: time.Now()}
+		}
+		utils.RecordPhaseTransition(r.Recorder, &tpcc, old.Status.Phase, tpcc.Status.Phase)
+		if err := r.Status().Patch(ctx, &tpcc, client.MergeFrom(old)); err != nil {
+			l.Error(err, "failed to patch tpcc status")
+			return intctrlutil.RequeueWithError(err, l, "failed to patch tpcc status")
+		}
+		return intctrlutil.RequeueAfter(intctrlutil.RequeueDuration)
+	}
+
+	resolved, err := utils.ResolveTarget(r.Client, ctx, tpcc.Namespace, tpcc.Spec.TargetRef, tpcc.Status.Target, &tpcc.Spec.Target)
+	if err != nil {
+		l.Error(err, "failed to resolve the target", "tpcc", tpcc.Name)
+		return intctrlutil.RequeueWithError(err, l, "failed to resolve the target")
+	}
+	tpcc.Status.Target = resolved
+
+	jobs := utils.SetJobDeadlines(NewTpccJobs(&tpcc), tpcc.Spec.Timeouts)
 
 	if tpcc.Status.Phase == "" {
 		l.Info("start tpcc", "tpcc", tpcc.Name)
@@ -78,6 +128,10 @@ func (r *TpccReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 
 	if tpcc.Status.Succeeded >= tpcc.Status.Total {
 		tpcc.Status.Phase = benchmarkv1alpha1.Completed
+		if tpcc.Status.Reason != "" {
+			// a job failed and the afterAll hooks ran after it
+			tpcc.Status.Phase = benchmarkv1alpha1.Failed
+		}
 		tpcc.Status.CompletionTimestamp = &metav1.Time{Time: time.Now()}
 	} else {
 		job := jobs[tpcc.Status.Succeeded]
@@ -101,6 +155,7 @@ func (r *TpccReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 
 			// wait for the job to be created
 			l.Info("created job", "job", job.Name)
+			utils.RecordJobCreated(r.Recorder, &tpcc, job, tpcc.Spec.Target.Password)
 			return intctrlutil.RequeueAfter(intctrlutil.RequeueDuration)
 		}
 
@@ -110,26 +165,66 @@ func (r *TpccReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 			l.Error(err, "failed to get job status", "job", job.Name)
 			return intctrlutil.RequeueWithError(err, l, "failed to get job status")
 		}
+		// the nodes cordoned by the faults are schedulable again once the run job finished
+		if err := utils.UncordonNodes(r.Client, ctx, tpcc.Spec.Faults, job, status); err != nil {
+			return intctrlutil.RequeueWithError(err, l, "unable to uncordon the nodes")
+		}
 
 		if status.Succeeded > 0 {
 			l.Info("job completed", "job", job.Name)
+			utils.RecordJobFinished(r.Client, ctx, r.Recorder, &tpcc, job, true)
+			utils.ObserveJobFinished(r.Client, ctx, constants.TpccType, jobs, tpcc.Status.Succeeded, true)
 			tpcc.Status.Succeeded++
 			// record the result
-			if err := utils.LogJobPodToCond(r.Client, r.RestConfig, ctx, job.Name, tpcc.Namespace, &tpcc.Status.Conditions, ParseTPCC); err != nil {
+			if err := utils.RecordJobSummary(r.Client, r.RestConfig, ctx, job.Name, tpcc.Namespace, &tpcc.Status.Conditions, constants.TpccType); err != nil {
 				return intctrlutil.RequeueWithError(err, l, "unable to record the log")
 			}
+			if err := utils.ArchiveJobLogs(r.Client, r.RestConfig, ctx, r.Scheme, &tpcc, tpcc.Spec.LogArchive, job.Name, &tpcc.Status.Logs); err != nil {
+				return intctrlutil.RequeueWithError(err, l, "unable to archive the log")
+			}
+		} else if step := utils.TimedOutStep(&tpcc, tpcc.Spec.Timeouts, job, status); step != "" {
+			if tpcc.Status.TimedOutStep != "" {
+				step = tpcc.Status.TimedOutStep
+			}
+			tpcc.Status.TimedOutStep = step
+			done, err := utils.StopTimedOutJob(r.Client, ctx, r.Recorder, &tpcc, constants.TpccType, job, step)
+			if err != nil {
+				return intctrlutil.RequeueWithError(err, l, "unable to stop the job")
+			}
+			if done {
+				l.Info("job timed out", "job", job.Name, "step", step)
+				tpcc.Status.Phase = utils.FailJob(jobs, &tpcc.Status.Succeeded)
+				tpcc.Status.Reason = utils.TimedOutReason
+				if err := utils.LogJobPodToCond(r.Client, r.RestConfig, ctx, job.Name, tpcc.Namespace, &tpcc.Status.Conditions, nil); err != nil {
+					return intctrlutil.RequeueWithError(err, l, "unable to record the log")
+				}
+				if err := utils.ArchiveJobLogs(r.Client, r.RestConfig, ctx, r.Scheme, &tpcc, tpcc.Spec.LogArchive, job.Name, &tpcc.Status.Logs); err != nil {
+					return intctrlutil.RequeueWithError(err, l, "unable to archive the log")
+				}
+			}
 		} else if status.Failed > 0 {
 			l.Info("job failed", "job", job.Name)
-			tpcc.Status.Phase = benchmarkv1alpha1.Failed
+			utils.RecordJobFinished(r.Client, ctx, r.Recorder, &tpcc, job, false)
+			utils.ObserveJobFinished(r.Client, ctx, constants.TpccType, jobs, tpcc.Status.Succeeded, false)
+			if tpcc.Status.Reason == "" {
+				tpcc.Status.Reason = utils.JobFailedReason
+			}
+			tpcc.Status.Phase = utils.FailJob(jobs, &tpcc.Status.Succeeded)
 			if err := utils.LogJobPodToCond(r.Client, r.RestConfig, ctx, job.Name, tpcc.Namespace, &tpcc.Status.Conditions, nil); err != nil {
 				return intctrlutil.RequeueWithError(err, l, "unable to record the log")
 			}
+			if err := utils.ArchiveJobLogs(r.Client, r.RestConfig, ctx, r.Scheme, &tpcc, tpcc.Spec.LogArchive, job.Name, &tpcc.Status.Logs); err != nil {
+				return intctrlutil.RequeueWithError(err, l, "unable to archive the log")
+			}
 		} else {
 			l.Info("job is running", "job", job.Name)
+			utils.RecordJobStarted(r.Client, ctx, r.Recorder, &tpcc, job.Name, job.Namespace)
+			utils.InjectFaults(r.Client, ctx, r.Scheme, &tpcc, tpcc.Spec.Faults, job, status, &tpcc.Status.Faults)
 		}
 	}
 
 	tpcc.Status.Completions = fmt.Sprintf("%d/%d", tpcc.Status.Succeeded, tpcc.Status.Total)
+	utils.RecordPhaseTransition(r.Recorder, &tpcc, old.Status.Phase, tpcc.Status.Phase)
 	if err := r.Status().Patch(ctx, &tpcc, client.MergeFrom(old)); err != nil {
 		l.Error(err, "failed to patch tpcc status")
 		return intctrlutil.RequeueWithError(err, l, "failed to patch tpcc status")
@@ -144,27 +239,3 @@ func (r *TpccReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		For(&benchmarkv1alpha1.Tpcc{}).
 		Complete(r)
 }
-
-func ParseTPCC(msg string) string {
-	result := ""
-	lines := strings.Split(msg, "\n")
-	index := len(lines)
-
-	for i, l := range lines {
-		if strings.Contains(l, "Measured tpmC (NewOrders)") {
-			index = i
-			result += fmt.Sprintf("%s\n", l)
-			break
-		}
-	}
-
-	for i := index + 1; i < len(lines); i++ {
-		if lines[i] != "" {
-			// align the output
-			result += fmt.Sprintf("%*s\n", len(lines[i])+27, lines[i])
-		}
-	}
-
-	// delete the last \n
-	return strings.TrimSpace(result)
-}
@@ -23,14 +23,20 @@ func NewTpccJobs(cr *v1alpha1.Tpcc) []*batchv1.Job {
 
 	step := cr.Spec.Step
 	if step == constants.CleanupStep || step == constants.AllStep {
-		jobs = append(jobs, NewTpccCleanupJobs(cr)...)
+		jobs = append(jobs, utils.MarkStepJobs(NewTpccCleanupJobs(cr), constants.CleanupStep)...)
 	}
 	if step == constants.PrepareStep || step == constants.AllStep {
-		jobs = append(jobs, NewTpccPrepareJobs(cr)...)
+		jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.BeforePrepareHook, cr.Spec.Hooks, &cr.Spec.Target)...)
+		jobs = append(jobs, utils.MarkStepJobs(NewTpccPrepareJobs(cr), constants.PrepareStep)...)
+		jobs = append(jobs, utils.MarkStepJobs(NewTpccVerifyJobs(cr), constants.PrepareStep)...)
+		jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.AfterPrepareHook, cr.Spec.Hooks, &cr.Spec.Target)...)
 	}
 	if step == constants.RunStep || step == constants.AllStep {
-		jobs = append(jobs, NewTpccRunJobs(cr)...)
+		jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.BeforeRunHook, cr.Spec.Hooks, &cr.Spec.Target)...)
+		jobs = append(jobs, utils.MarkRunJobs(NewTpccRunJobs(cr))...)
+		jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.AfterRunHook, cr.Spec.Hooks, &cr.Spec.Target)...)
 	}
+	jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.AfterAllHook, cr.Spec.Hooks, &cr.Spec.Target)...)
 
 	// set tolerations for all jobs
 	utils.AddTolerationToJobs(jobs, cr.Spec.Tolerations)
@@ -101,6 +107,29 @@ func NewTpccPrepareJobs(cr *v1alpha1.Tpcc) []*batchv1.Job {
 	return []*batchv1.Job{job}
 }
 
+// NewTpccVerifyJobs checks the row counts of the tpcc tables whose size only
+// depends on the number of warehouses
+func NewTpccVerifyJobs(cr *v1alpha1.Tpcc) []*batchv1.Job {
+	if cr.Spec.WareHouses <= 0 {
+		return nil
+	}
+
+	warehouses := int64(cr.Spec.WareHouses)
+	expect := map[string]int64{
+		"bmsql_warehouse": warehouses,
+		"bmsql_district":  warehouses * 10,
+		"bmsql_customer":  warehouses * 30000,
+		"bmsql_stock":     warehouses * 100000,
+		"bmsql_item":      100000,
+	}
+
+	job := utils.NewVerifyJob(cr.Name, cr.Namespace, cr.Spec.Target.Driver, &cr.Spec.Target, cr.Spec.Target.Database, expect)
+	if job == nil {
+		return nil
+	}
+	return []*batchv1.Job{job}
+}
+
 func NewTpccRunJobs(cr *v1alpha1.Tpcc) []*batchv1.Job {
 	cmd := "python3 main.py"
 	cmd = fmt.Sprintf("%s --mode %s", cmd, "run")
@@ -217,6 +246,16 @@ func TpccInitContainers(cr *v1alpha1.Tpcc) *corev1.Container {
 		return utils.InitPGDatabaseContainer(cr.Spec.Target, cr.Spec.Target.Database)
 	case constants.GaussDBDriver:
 		return utils.InitGaussdbDatabaseContainer(cr.Spec.Target, cr.Spec.Target.Database)
+	case constants.TidbDriver:
+		return utils.InitTidbDatabaseContainer(cr.Spec.Target, cr.Spec.Target.Database)
+	case constants.MssqlDriver:
+		return utils.InitMssqlDatabaseContainer(cr.Spec.Target, cr.Spec.Target.Database)
+	case constants.OceanBaseOracleTenantDriver:
+		return utils.InitOceanBaseOracleSchemaContainer(cr.Spec.Target, cr.Spec.Target.Database)
+	case constants.DamengDriver:
+		// tpcc connects to dameng without a database, and the tools have no
+		// Dameng driver to create one with
+		return nil
 	default:
 		return nil
 	}
@@ -24,6 +24,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -32,6 +33,7 @@ import (
 	benchmarkv1alpha1 "github.com/apecloud/kubebench/api/v1alpha1"
 	intctrlutil "github.com/apecloud/kubebench/internal/controllerutil"
 	"github.com/apecloud/kubebench/internal/utils"
+	"github.com/apecloud/kubebench/pkg/constants"
 )
 
 // TpcdsReconciler reconciles a Tpcds object
@@ -39,6 +41,7 @@ type TpcdsReconciler struct {
 	client.Client
 	Scheme     *runtime.Scheme
 	RestConfig *rest.Config
+	Recorder   record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=benchmark.apecloud.io,resources=tpcds,verbs=get;list;watch;create;update;patch;delete
@@ -56,6 +59,8 @@ type TpcdsReconciler struct {
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.15.0/pkg/reconcile
 func (r *TpcdsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	l := log.FromContext(ctx)
+	// the summary and the archive of a finished job share its logs
+	ctx = utils.WithPodLogs(ctx)
 
 	var tpcds benchmarkv1alpha1.Tpcds
 	if err := r.Get(ctx, req.NamespacedName, &tpcds); err != nil {
@@ -64,12 +69,58 @@ func (r *TpcdsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	}
 	old := tpcds.DeepCopy()
 
+	// record the logs archived to a pvc once they are copied into the claim
+	archiving, err := utils.SyncArchivedLogs(r.Client, ctx, &tpcds, tpcds.Spec.LogArchive, &tpcds.Status.Logs)
+	if err != nil {
+		return intctrlutil.RequeueWithError(err, l, "unable to sync the archived logs")
+	}
+
 	// if tpcds completed or failed, do nothing
 	if tpcds.Status.Phase == benchmarkv1alpha1.Completed || tpcds.Status.Phase == benchmarkv1alpha1.Failed {
-		return intctrlutil.Reconciled()
+		if err := r.Status().Patch(ctx, &tpcds, client.MergeFrom(old)); err != nil {
+			l.Error(err, "failed to patch tpcds status")
+			return intctrlutil.RequeueWithError(err, l, "failed to patch tpcds status")
+		}
+		if archiving {
+			return intctrlutil.RequeueAfter(intctrlutil.RequeueDuration)
+		}
+		// collect the jobs once the ttl expired
+		requeueAfter, err := utils.CollectFinished(r.Client, ctx, &tpcds, &tpcds.Spec.BenchCommon, tpcds.Status.CompletionTimestamp)
+		if err != nil {
+			return intctrlutil.RequeueWithError(err, l, "failed to collect the finished tpcds")
+		}
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+
+	if len(tpcds.Spec.Targets) > 0 {
+		phase, succeeded, err := utils.ReconcileTargets(r.Client, ctx, r.Scheme, &tpcds, tpcds.Spec.Targets, tpcds.Spec.TargetsPolicy, &tpcds.Status.Targets)
+		if err != nil {
+			l.Error(err, "failed to reconcile the targets", "tpcds", tpcds.Name)
+			return intctrlutil.RequeueWithError(err, l, "failed to reconcile the targets")
+		}
+		tpcds.Status.Phase = phase
+		tpcds.Status.Succeeded = succeeded
+		tpcds.Status.Total = len(tpcds.Spec.Targets)
+		tpcds.Status.Completions = fmt.Sprintf("%d/%d", tpcds.Status.Succeeded, tpcds.Status.Total)
+		if phase == benchmarkv1alpha1.Completed || phase == benchmarkv1alpha1.Failed {
+			tpcds.Status.CompletionTimestamp = &metav1.Time{Time: time.Now()}
+		}
+		utils.RecordPhaseTransition(r.Recorder, &tpcds, old.Status.Phase, tpcds.Status.Phase)
+		if err := r.Status().Patch(ctx, &tpcds, client.MergeFrom(old)); err != nil {
+			l.Error(err, "failed to patch tpcds status")
+			return intctrlutil.RequeueWithError(err, l, "failed to patch tpcds status")
+		}
+		return intctrlutil.RequeueAfter(intctrlutil.RequeueDuration)
 	}
 
-	jobs := NewTpcdsJobs(tpcds)
+	resolved, err := utils.ResolveTarget(r.Client, ctx, tpcds.Namespace, tpcds.Spec.TargetRef, tpcds.Status.Target, &tpcds.Spec.Target)
+	if err != nil {
+		l.Error(err, "failed to resolve the target", "tpcds", tpcds.Name)
+		return intctrlutil.RequeueWithError(err, l, "failed to resolve the target")
+	}
+	tpcds.Status.Target = resolved
+
+	jobs := utils.SetJobDeadlines(NewTpcdsJobs(tpcds), tpcds.Spec.Timeouts)
 
 	if tpcds.Status.Phase == "" {
 		l.Info("start tpcds", "tpcds", tpcds.Name)
@@ -79,6 +130,10 @@ func (r *TpcdsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 
 	if tpcds.Status.Succeeded >= tpcds.Status.Total {
 		tpcds.Status.Phase = benchmarkv1alpha1.Completed
+		if tpcds.Status.Reason != "" {
+			// a job failed and the afterAll hooks ran after it
+			tpcds.Status.Phase = benchmarkv1alpha1.Failed
+		}
 		tpcds.Status.CompletionTimestamp = &metav1.Time{Time: time.Now()}
 	} else {
 		job := jobs[tpcds.Status.Succeeded]
@@ -102,6 +157,7 @@ func (r *TpcdsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 
 			// wait for the job to be created
 			l.Info("created job", "job", job.Name)
+			utils.RecordJobCreated(r.Recorder, &tpcds, job, tpcds.Spec.Target.Password)
 			return intctrlutil.RequeueAfter(intctrlutil.RequeueDuration)
 		}
 
@@ -111,26 +167,69 @@ func (r *TpcdsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 			l.Error(err, "failed to get job status", "job", job.Name)
 			return intctrlutil.RequeueWithError(err, l, "failed to get job status")
 		}
+		// the nodes cordoned by the faults are schedulable again once the run job finished
+		if err := utils.UncordonNodes(r.Client, ctx, tpcds.Spec.Faults, job, status); err != nil {
+			return intctrlutil.RequeueWithError(err, l, "unable to uncordon the nodes")
+		}
 
 		if status.Succeeded > 0 {
 			l.Info("job completed", "job", job.Name)
+			utils.RecordJobFinished(r.Client, ctx, r.Recorder, &tpcds, job, true)
+			utils.ObserveJobFinished(r.Client, ctx, constants.TpcdsType, jobs, tpcds.Status.Succeeded, true)
 			tpcds.Status.Succeeded++
 			// record the result
 			if err := utils.LogJobPodToCond(r.Client, r.RestConfig, ctx, job.Name, tpcds.Namespace, &tpcds.Status.Conditions, nil); err != nil {
 				return intctrlutil.RequeueWithError(err, l, "unable to record the log")
 			}
+			if err := recordQueryStats(r.Client, r.RestConfig, ctx, job, &tpcds.Status.QueryStats); err != nil {
+				return intctrlutil.RequeueWithError(err, l, "unable to record the query stats")
+			}
+			if err := utils.ArchiveJobLogs(r.Client, r.RestConfig, ctx, r.Scheme, &tpcds, tpcds.Spec.LogArchive, job.Name, &tpcds.Status.Logs); err != nil {
+				return intctrlutil.RequeueWithError(err, l, "unable to archive the log")
+			}
+		} else if step := utils.TimedOutStep(&tpcds, tpcds.Spec.Timeouts, job, status); step != "" {
+			if tpcds.Status.TimedOutStep != "" {
+				step = tpcds.Status.TimedOutStep
+			}
+			tpcds.Status.TimedOutStep = step
+			done, err := utils.StopTimedOutJob(r.Client, ctx, r.Recorder, &tpcds, constants.TpcdsType, job, step)
+			if err != nil {
+				return intctrlutil.RequeueWithError(err, l, "unable to stop the job")
+			}
+			if done {
+				l.Info("job timed out", "job", job.Name, "step", step)
+				tpcds.Status.Phase = utils.FailJob(jobs, &tpcds.Status.Succeeded)
+				tpcds.Status.Reason = utils.TimedOutReason
+				if err := utils.LogJobPodToCond(r.Client, r.RestConfig, ctx, job.Name, tpcds.Namespace, &tpcds.Status.Conditions, nil); err != nil {
+					return intctrlutil.RequeueWithError(err, l, "unable to record the log")
+				}
+				if err := utils.ArchiveJobLogs(r.Client, r.RestConfig, ctx, r.Scheme, &tpcds, tpcds.Spec.LogArchive, job.Name, &tpcds.Status.Logs); err != nil {
+					return intctrlutil.RequeueWithError(err, l, "unable to archive the log")
+				}
+			}
 		} else if status.Failed > 0 {
 			l.Info("job failed", "job", job.Name)
-			tpcds.Status.Phase = benchmarkv1alpha1.Failed
+			utils.RecordJobFinished(r.Client, ctx, r.Recorder, &tpcds, job, false)
+			utils.ObserveJobFinished(r.Client, ctx, constants.TpcdsType, jobs, tpcds.Status.Succeeded, false)
+			if tpcds.Status.Reason == "" {
+				tpcds.Status.Reason = utils.JobFailedReason
+			}
+			tpcds.Status.Phase = utils.FailJob(jobs, &tpcds.Status.Succeeded)
 			if err := utils.LogJobPodToCond(r.Client, r.RestConfig, ctx, job.Name, tpcds.Namespace, &tpcds.Status.Conditions, nil); err != nil {
 				return intctrlutil.RequeueWithError(err, l, "unable to record the log")
 			}
+			if err := utils.ArchiveJobLogs(r.Client, r.RestConfig, ctx, r.Scheme, &tpcds, tpcds.Spec.LogArchive, job.Name, &tpcds.Status.Logs); err != nil {
+				return intctrlutil.RequeueWithError(err, l, "unable to archive the log")
+			}
 		} else {
 			l.Info("job is running", "job", job.Name)
+			utils.RecordJobStarted(r.Client, ctx, r.Recorder, &tpcds, job.Name, job.Namespace)
+			utils.InjectFaults(r.Client, ctx, r.Scheme, &tpcds, tpcds.Spec.Faults, job, status, &tpcds.Status.Faults)
 		}
 	}
 
 	tpcds.Status.Completions = fmt.Sprintf("%d/%d", tpcds.Status.Succeeded, tpcds.Status.Total)
+	utils.RecordPhaseTransition(r.Recorder, &tpcds, old.Status.Phase, tpcds.Status.Phase)
 	if err := r.Status().Patch(ctx, &tpcds, client.MergeFrom(old)); err != nil {
 		l.Error(err, "failed to patch tpcds status")
 		return intctrlutil.RequeueWithError(err, l, "failed to patch tpcds status")
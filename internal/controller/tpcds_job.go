@@ -2,6 +2,7 @@ package controller
 
 import (
 	"fmt"
+	"strconv"
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -11,6 +12,11 @@ import (
 	"github.com/apecloud/kubebench/pkg/constants"
 )
 
+const (
+	tpcdsLogFile  = "/var/log/tpcds.log"
+	tpcdsExitFile = "/var/log/tpcds.exit"
+)
+
 func NewTpcdsJobs(cr v1alpha1.Tpcds) []*batchv1.Job {
 	jobs := make([]*batchv1.Job, 0)
 
@@ -21,14 +27,19 @@ func NewTpcdsJobs(cr v1alpha1.Tpcds) []*batchv1.Job {
 
 	step := cr.Spec.Step
 	if step == constants.CleanupStep || step == constants.AllStep {
-		jobs = append(jobs, NewTpcdsCleanupJobs(cr)...)
+		jobs = append(jobs, utils.MarkStepJobs(NewTpcdsCleanupJobs(cr), constants.CleanupStep)...)
 	}
 	if step == constants.PrepareStep || step == constants.AllStep {
-		jobs = append(jobs, NewTpcdsPrepareJobs(cr)...)
+		jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.BeforePrepareHook, cr.Spec.Hooks, &cr.Spec.Target)...)
+		jobs = append(jobs, utils.MarkStepJobs(NewTpcdsPrepareJobs(cr), constants.PrepareStep)...)
+		jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.AfterPrepareHook, cr.Spec.Hooks, &cr.Spec.Target)...)
 	}
 	if step == constants.RunStep || step == constants.AllStep {
-		jobs = append(jobs, NewTpcdsRunJobs(cr)...)
+		jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.BeforeRunHook, cr.Spec.Hooks, &cr.Spec.Target)...)
+		jobs = append(jobs, utils.MarkRunJobs(NewTpcdsRunJobs(cr))...)
+		jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.AfterRunHook, cr.Spec.Hooks, &cr.Spec.Target)...)
 	}
+	jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.AfterAllHook, cr.Spec.Hooks, &cr.Spec.Target)...)
 
 	// set tolerations for all jobs
 	utils.AddTolerationToJobs(jobs, cr.Spec.Tolerations)
@@ -120,7 +131,13 @@ func NewTpcdsRunJobs(cr v1alpha1.Tpcds) []*batchv1.Job {
 	cmd = fmt.Sprintf("%s --database %s", cmd, cr.Spec.Target.Database)
 	cmd = fmt.Sprintf("%s --step %s", cmd, "run")
 
-	job := utils.JobTemplate(fmt.Sprintf("%s-run", cr.Name), cr.Namespace)
+	// the log starts with the scale and ends with the end marker for the
+	// exporter, the job keeps the exit code of the runner
+	cmd = fmt.Sprintf("echo \"scale factor: %d\" > %s; (%s; echo $? > %s) 2>&1 | tee -a %s; echo \"%s\" >> %s; exit $(cat %s)",
+		cr.Spec.Size, tpcdsLogFile, cmd, tpcdsExitFile, tpcdsLogFile, constants.RunEndMarker, tpcdsLogFile, tpcdsExitFile)
+
+	jobName := fmt.Sprintf("%s-run", cr.Name)
+	job := utils.JobTemplate(jobName, cr.Namespace)
 	job.Spec.Template.Spec.Containers = append(
 		job.Spec.Template.Spec.Containers,
 		corev1.Container{
@@ -128,7 +145,15 @@ func NewTpcdsRunJobs(cr v1alpha1.Tpcds) []*batchv1.Job {
 			Image:           constants.GetBenchmarkImage(constants.KubebenchEnvTpcds),
 			ImagePullPolicy: corev1.PullIfNotPresent,
 			Command:         []string{"/bin/sh", "-c", cmd},
+			VolumeMounts: []corev1.VolumeMount{
+				{
+					Name:      "log",
+					MountPath: "/var/log",
+				},
+			},
 		},
+		utils.NewExporterContainer(constants.TpcdsType, tpcdsLogFile, cr.Name, jobName, append(utils.ExporterArgs(cr.Labels, &cr.Spec.BenchCommon),
+			utils.ExporterParamArgs(map[string]string{"scale": strconv.Itoa(cr.Spec.Size)})...)...),
 	)
 
 	return []*batchv1.Job{job}
@@ -19,12 +19,13 @@ package controller
 import (
 	"context"
 	"fmt"
-	"strings"
 	"time"
 
+	batchv1 "k8s.io/api/batch/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -32,7 +33,9 @@ import (
 
 	benchmarkv1alpha1 "github.com/apecloud/kubebench/api/v1alpha1"
 	intctrlutil "github.com/apecloud/kubebench/internal/controllerutil"
+	"github.com/apecloud/kubebench/internal/exporter"
 	"github.com/apecloud/kubebench/internal/utils"
+	"github.com/apecloud/kubebench/pkg/constants"
 )
 
 // TpchReconciler reconciles a Tpch object
@@ -40,6 +43,7 @@ type TpchReconciler struct {
 	client.Client
 	Scheme     *runtime.Scheme
 	RestConfig *rest.Config
+	Recorder   record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=benchmark.apecloud.io,resources=tpches,verbs=get;list;watch;create;update;patch;delete
@@ -57,6 +61,8 @@ type TpchReconciler struct {
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.15.0/pkg/reconcile
 func (r *TpchReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	l := log.FromContext(ctx)
+	// the summary and the archive of a finished job share its logs
+	ctx = utils.WithPodLogs(ctx)
 
 	// TODO(user): your logic here
 	var tpch benchmarkv1alpha1.Tpch
@@ -65,12 +71,58 @@ func (r *TpchReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 	}
 	old := tpch.DeepCopy()
 
+	// record the logs archived to a pvc once they are copied into the claim
+	archiving, err := utils.SyncArchivedLogs(r.Client, ctx, &tpch, tpch.Spec.LogArchive, &tpch.Status.Logs)
+	if err != nil {
+		return intctrlutil.RequeueWithError(err, l, "unable to sync the archived logs")
+	}
+
 	// Run to one completion
 	if tpch.Status.Phase == benchmarkv1alpha1.Completed || tpch.Status.Phase == benchmarkv1alpha1.Failed {
-		return intctrlutil.Reconciled()
+		if err := r.Status().Patch(ctx, &tpch, client.MergeFrom(old)); err != nil {
+			l.Error(err, "failed to patch tpch status")
+			return intctrlutil.RequeueWithError(err, l, "failed to patch tpch status")
+		}
+		if archiving {
+			return intctrlutil.RequeueAfter(intctrlutil.RequeueDuration)
+		}
+		// collect the jobs once the ttl expired
+		requeueAfter, err := utils.CollectFinished(r.Client, ctx, &tpch, &tpch.Spec.BenchCommon, tpch.Status.CompletionTimestamp)
+		if err != nil {
+			return intctrlutil.RequeueWithError(err, l, "failed to collect the finished tpch")
+		}
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+
+	if len(tpch.Spec.Targets) > 0 {
+		phase, succeeded, err := utils.ReconcileTargets(r.Client, ctx, r.Scheme, &tpch, tpch.Spec.Targets, tpch.Spec.TargetsPolicy, &tpch.Status.Targets)
+		if err != nil {
+			l.Error(err, "failed to reconcile the targets", "tpch", tpch.Name)
+			return intctrlutil.RequeueWithError(err, l, "failed to reconcile the targets")
+		}
+		tpch.Status.Phase = phase
+		tpch.Status.Succeeded = succeeded
+		tpch.Status.Total = len(tpch.Spec.Targets)
+		tpch.Status.Completions = fmt.Sprintf("%d/%d", tpch.Status.Succeeded, tpch.Status.Total)
+		if phase == benchmarkv1alpha1.Completed || phase == benchmarkv1alpha1.Failed {
+			tpch.Status.CompletionTimestamp = &metav1.Time{Time: time.Now()}
+		}
+		utils.RecordPhaseTransition(r.Recorder, &tpch, old.Status.Phase, tpch.Status.Phase)
+		if err := r.Status().Patch(ctx, &tpch, client.MergeFrom(old)); err != nil {
+			l.Error(err, "failed to patch tpch status")
+			return intctrlutil.RequeueWithError(err, l, "failed to patch tpch status")
+		}
+		return intctrlutil.RequeueAfter(intctrlutil.RequeueDuration)
+	}
+
+	resolved, err := utils.ResolveTarget(r.Client, ctx, tpch.Namespace, tpch.Spec.TargetRef, tpch.Status.Target, &tpch.Spec.Target)
+	if err != nil {
+		l.Error(err, "failed to resolve the target", "tpch", tpch.Name)
+		return intctrlutil.RequeueWithError(err, l, "failed to resolve the target")
 	}
+	tpch.Status.Target = resolved
 
-	jobs := NewTpchJobs(&tpch)
+	jobs := utils.SetJobDeadlines(NewTpchJobs(&tpch), tpch.Spec.Timeouts)
 
 	if tpch.Status.Phase == "" {
 		l.Info("start tpch", "tpch", tpch.Name)
@@ -80,6 +132,10 @@ func (r *TpchReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 
 	if tpch.Status.Succeeded >= tpch.Status.Total {
 		tpch.Status.Phase = benchmarkv1alpha1.Completed
+		if tpch.Status.Reason != "" {
+			// a job failed and the afterAll hooks ran after it
+			tpch.Status.Phase = benchmarkv1alpha1.Failed
+		}
 		tpch.Status.CompletionTimestamp = &metav1.Time{Time: time.Now()}
 	} else {
 		job := jobs[tpch.Status.Succeeded]
@@ -103,6 +159,7 @@ func (r *TpchReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 
 			// wait for the job to be created
 			l.Info("created job", "job", job.Name)
+			utils.RecordJobCreated(r.Recorder, &tpch, job, tpch.Spec.Target.Password)
 			return intctrlutil.RequeueAfter(intctrlutil.RequeueDuration)
 		}
 
@@ -112,26 +169,69 @@ func (r *TpchReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 			l.Error(err, "failed to get job status", "job", job.Name)
 			return intctrlutil.RequeueWithError(err, l, "failed to get job status")
 		}
+		// the nodes cordoned by the faults are schedulable again once the run job finished
+		if err := utils.UncordonNodes(r.Client, ctx, tpch.Spec.Faults, job, status); err != nil {
+			return intctrlutil.RequeueWithError(err, l, "unable to uncordon the nodes")
+		}
 
 		if status.Succeeded > 0 {
 			l.Info("job completed", "job", job.Name)
+			utils.RecordJobFinished(r.Client, ctx, r.Recorder, &tpch, job, true)
+			utils.ObserveJobFinished(r.Client, ctx, constants.TpchType, jobs, tpch.Status.Succeeded, true)
 			tpch.Status.Succeeded++
 			// record the result
-			if err := utils.LogJobPodToCond(r.Client, r.RestConfig, ctx, job.Name, tpch.Namespace, &tpch.Status.Conditions, ParseTpch); err != nil {
+			if err := utils.RecordJobSummary(r.Client, r.RestConfig, ctx, job.Name, tpch.Namespace, &tpch.Status.Conditions, constants.TpchType); err != nil {
 				return intctrlutil.RequeueWithError(err, l, "unable to record the log")
 			}
+			if err := recordQueryStats(r.Client, r.RestConfig, ctx, job, &tpch.Status.QueryStats); err != nil {
+				return intctrlutil.RequeueWithError(err, l, "unable to record the query stats")
+			}
+			if err := utils.ArchiveJobLogs(r.Client, r.RestConfig, ctx, r.Scheme, &tpch, tpch.Spec.LogArchive, job.Name, &tpch.Status.Logs); err != nil {
+				return intctrlutil.RequeueWithError(err, l, "unable to archive the log")
+			}
+		} else if step := utils.TimedOutStep(&tpch, tpch.Spec.Timeouts, job, status); step != "" {
+			if tpch.Status.TimedOutStep != "" {
+				step = tpch.Status.TimedOutStep
+			}
+			tpch.Status.TimedOutStep = step
+			done, err := utils.StopTimedOutJob(r.Client, ctx, r.Recorder, &tpch, constants.TpchType, job, step)
+			if err != nil {
+				return intctrlutil.RequeueWithError(err, l, "unable to stop the job")
+			}
+			if done {
+				l.Info("job timed out", "job", job.Name, "step", step)
+				tpch.Status.Phase = utils.FailJob(jobs, &tpch.Status.Succeeded)
+				tpch.Status.Reason = utils.TimedOutReason
+				if err := utils.LogJobPodToCond(r.Client, r.RestConfig, ctx, job.Name, tpch.Namespace, &tpch.Status.Conditions, nil); err != nil {
+					return intctrlutil.RequeueWithError(err, l, "unable to record the log")
+				}
+				if err := utils.ArchiveJobLogs(r.Client, r.RestConfig, ctx, r.Scheme, &tpch, tpch.Spec.LogArchive, job.Name, &tpch.Status.Logs); err != nil {
+					return intctrlutil.RequeueWithError(err, l, "unable to archive the log")
+				}
+			}
 		} else if status.Failed > 0 {
 			l.Info("job failed", "job", job.Name)
-			tpch.Status.Phase = benchmarkv1alpha1.Failed
+			utils.RecordJobFinished(r.Client, ctx, r.Recorder, &tpch, job, false)
+			utils.ObserveJobFinished(r.Client, ctx, constants.TpchType, jobs, tpch.Status.Succeeded, false)
+			if tpch.Status.Reason == "" {
+				tpch.Status.Reason = utils.JobFailedReason
+			}
+			tpch.Status.Phase = utils.FailJob(jobs, &tpch.Status.Succeeded)
 			if err := utils.LogJobPodToCond(r.Client, r.RestConfig, ctx, job.Name, tpch.Namespace, &tpch.Status.Conditions, nil); err != nil {
 				return intctrlutil.RequeueWithError(err, l, "unable to record the log")
 			}
+			if err := utils.ArchiveJobLogs(r.Client, r.RestConfig, ctx, r.Scheme, &tpch, tpch.Spec.LogArchive, job.Name, &tpch.Status.Logs); err != nil {
+				return intctrlutil.RequeueWithError(err, l, "unable to archive the log")
+			}
 		} else {
 			l.Info("job is running", "job", job.Name)
+			utils.RecordJobStarted(r.Client, ctx, r.Recorder, &tpch, job.Name, job.Namespace)
+			utils.InjectFaults(r.Client, ctx, r.Scheme, &tpch, tpch.Spec.Faults, job, status, &tpch.Status.Faults)
 		}
 	}
 
 	tpch.Status.Completions = fmt.Sprintf("%d/%d", tpch.Status.Succeeded, tpch.Status.Total)
+	utils.RecordPhaseTransition(r.Recorder, &tpch, old.Status.Phase, tpch.Status.Phase)
 	if err := r.Status().Patch(ctx, &tpch, client.MergeFrom(old)); err != nil {
 		return intctrlutil.RequeueWithError(err, l, "unable to update tpch status")
 	}
@@ -139,28 +239,40 @@ func (r *TpchReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 	return intctrlutil.RequeueAfter(intctrlutil.RequeueDuration)
 }
 
-func ParseTpch(msg string) string {
-	result := ""
-	lines := strings.Split(msg, "\n")
-	index := len(lines)
-
-	for i, l := range lines {
-		if strings.Contains(l, "run tpch query") {
-			index = i
-			result += fmt.Sprintf("%s\n", l)
-			break
-		}
+// recordQueryStats records the results of the queries that the exporter
+// printed for the run job of TPC-H or TPC-DS
+func recordQueryStats(cli client.Client, restConfig *rest.Config, ctx context.Context, job *batchv1.Job, stats **benchmarkv1alpha1.QueryStats) error {
+	if !utils.IsRunJob(job) {
+		return nil
 	}
 
-	for i := index + 1; i < len(lines); i++ {
-		if lines[i] != "" {
-			// align the output
-			result += fmt.Sprintf("%*s\n", len(lines[i])+27, lines[i])
-		}
+	result := &exporter.QueryRunResult{}
+	ok, err := utils.ReadResultReport(cli, restConfig, ctx, job.Name, job.Namespace, result)
+	if err != nil || !ok {
+		return err
 	}
+	*stats = NewQueryStats(job.Name, result)
+	return nil
+}
 
-	// delete the last \n
-	return strings.TrimSpace(result)
+// NewQueryStats converts the results of the queries of the job to its status
+func NewQueryStats(jobName string, result *exporter.QueryRunResult) *benchmarkv1alpha1.QueryStats {
+	stats := &benchmarkv1alpha1.QueryStats{
+		Job:        jobName,
+		Duration:   formatLatency(result.Duration),
+		Failed:     result.Failed,
+		Power:      formatLatency(result.Power),
+		Throughput: formatLatency(result.Throughput),
+	}
+	for _, query := range result.Queries {
+		stats.Queries = append(stats.Queries, benchmarkv1alpha1.QueryResult{
+			Query:    query.Query,
+			Duration: formatLatency(query.Duration),
+			Rows:     query.Rows,
+			Failed:   query.Failed,
+		})
+	}
+	return stats
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"fmt"
+	"strconv"
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -11,6 +12,8 @@ import (
 	"github.com/apecloud/kubebench/pkg/constants"
 )
 
+const tpchLogFile = "/var/log/tpch.log"
+
 func NewTpchJobs(cr *v1alpha1.Tpch) []*batchv1.Job {
 	jobs := make([]*batchv1.Job, 0)
 
@@ -22,11 +25,21 @@ func NewTpchJobs(cr *v1alpha1.Tpch) []*batchv1.Job {
 	step := cr.Spec.Step
 
 	if step == constants.AllStep {
-		jobs = append(jobs, NewTpchAllJobs(cr)...)
+		// prepare and run share a single job, so the before hooks of both
+		// steps run ahead of it and the afterRun hooks follow it. There is
+		// no point between prepare and run for afterPrepare hooks, the API
+		// rejects them
+		jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.BeforePrepareHook, cr.Spec.Hooks, &cr.Spec.Target)...)
+		jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.BeforeRunHook, cr.Spec.Hooks, &cr.Spec.Target)...)
+		jobs = append(jobs, utils.MarkRunJobs(NewTpchAllJobs(cr))...)
+		jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.AfterRunHook, cr.Spec.Hooks, &cr.Spec.Target)...)
 	}
 	if step == constants.RunStep {
-		jobs = append(jobs, NewTpchRunJobs(cr)...)
+		jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.BeforeRunHook, cr.Spec.Hooks, &cr.Spec.Target)...)
+		jobs = append(jobs, utils.MarkRunJobs(NewTpchRunJobs(cr))...)
+		jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.AfterRunHook, cr.Spec.Hooks, &cr.Spec.Target)...)
 	}
+	jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.AfterAllHook, cr.Spec.Hooks, &cr.Spec.Target)...)
 
 	// set tolerations for all jobs
 	utils.AddTolerationToJobs(jobs, cr.Spec.Tolerations)
@@ -63,7 +76,8 @@ func NewTpchRunJobs(cr *v1alpha1.Tpch) []*batchv1.Job {
 	value = fmt.Sprintf("%s,local:%s", value, "True")
 	value = fmt.Sprintf("%s,size:%d", value, cr.Spec.Size)
 
-	job := utils.JobTemplate(fmt.Sprintf("%s-run", cr.Name), cr.Namespace)
+	jobName := fmt.Sprintf("%s-run", cr.Name)
+	job := utils.JobTemplate(jobName, cr.Namespace)
 	job.Spec.Template.Spec.Containers = append(
 		job.Spec.Template.Spec.Containers,
 		corev1.Container{
@@ -71,7 +85,7 @@ func NewTpchRunJobs(cr *v1alpha1.Tpch) []*batchv1.Job {
 			Image:           constants.GetBenchmarkImage(constants.KubebenchEnvTpch),
 			ImagePullPolicy: corev1.PullIfNotPresent,
 			Command:         []string{"/bin/sh", "-c"},
-			Args:            []string{"python3 -u infratest.py -t \"$TYPE\" -f \"${FLAG}\" -c \"${CONFIGS}\" -j \"${JSONS}\" | tee /var/log/tpch.log"},
+			Args:            []string{tpchQueryCommand(cr)},
 			Env: []corev1.EnvVar{
 				{
 					Name:  "TYPE",
@@ -93,6 +107,8 @@ func NewTpchRunJobs(cr *v1alpha1.Tpch) []*batchv1.Job {
 				},
 			},
 		},
+		utils.NewExporterContainer(constants.TpchType, tpchLogFile, cr.Name, jobName, append(utils.ExporterArgs(cr.Labels, &cr.Spec.BenchCommon),
+			utils.ExporterParamArgs(map[string]string{"scale": strconv.Itoa(cr.Spec.Size)})...)...),
 	)
 
 	return []*batchv1.Job{job}
@@ -107,7 +123,8 @@ func NewTpchAllJobs(cr *v1alpha1.Tpch) []*batchv1.Job {
 	value = fmt.Sprintf("%s,local:%s", value, "True")
 	value = fmt.Sprintf("%s,size:%d", value, cr.Spec.Size)
 
-	job := utils.JobTemplate(fmt.Sprintf("%s-all", cr.Name), cr.Namespace)
+	jobName := fmt.Sprintf("%s-all", cr.Name)
+	job := utils.JobTemplate(jobName, cr.Namespace)
 	job.Spec.Template.Spec.Containers = append(
 		job.Spec.Template.Spec.Containers,
 		corev1.Container{
@@ -115,7 +132,7 @@ func NewTpchAllJobs(cr *v1alpha1.Tpch) []*batchv1.Job {
 			Image:           constants.GetBenchmarkImage(constants.KubebenchEnvTpch),
 			ImagePullPolicy: corev1.PullIfNotPresent,
 			Command:         []string{"/bin/sh", "-c"},
-			Args:            []string{"python3 -u infratest.py -t \"$TYPE\" -f \"${FLAG}\" -c \"${CONFIGS}\" -j \"${JSONS}\" | tee /var/log/tpch.log"},
+			Args:            []string{tpchQueryCommand(cr)},
 			Env: []corev1.EnvVar{
 				{
 					Name:  "TYPE",
@@ -137,7 +154,17 @@ func NewTpchAllJobs(cr *v1alpha1.Tpch) []*batchv1.Job {
 				},
 			},
 		},
+		utils.NewExporterContainer(constants.TpchType, tpchLogFile, cr.Name, jobName, append(utils.ExporterArgs(cr.Labels, &cr.Spec.BenchCommon),
+			utils.ExporterParamArgs(map[string]string{"scale": strconv.Itoa(cr.Spec.Size)})...)...),
 	)
 
 	return []*batchv1.Job{job}
 }
+
+// tpchQueryCommand runs the queries, the log starts with the scale and ends
+// with the end marker for the exporter
+func tpchQueryCommand(cr *v1alpha1.Tpch) string {
+	cmd := fmt.Sprintf("echo \"scale factor: %d\" > %s; ", cr.Spec.Size, tpchLogFile)
+	cmd += fmt.Sprintf("python3 -u infratest.py -t \"$TYPE\" -f \"${FLAG}\" -c \"${CONFIGS}\" -j \"${JSONS}\" | tee -a %s; ", tpchLogFile)
+	return cmd + fmt.Sprintf("echo \"%s\" >> %s", constants.RunEndMarker, tpchLogFile)
+}
@@ -0,0 +1,241 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	benchmarkv1alpha1 "github.com/apecloud/kubebench/api/v1alpha1"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+func TestNewSysbenchJobsVerifiesPreparedTables(t *testing.T) {
+	cr := &benchmarkv1alpha1.Sysbench{
+		ObjectMeta: metav1.ObjectMeta{Name: "sb", Namespace: "default"},
+		Spec: benchmarkv1alpha1.SysbenchSpec{
+			Tables:  2,
+			Size:    1000,
+			Threads: []int{4},
+			Types:   []string{"oltp_read_write"},
+			BenchCommon: benchmarkv1alpha1.BenchCommon{
+				Step:   constants.AllStep,
+				Target: newVerifyTestTarget(constants.MySqlDriver),
+			},
+		},
+	}
+
+	jobs := NewSysbenchJobs(cr)
+	want := []string{"sb-precheck", "sb-cleanup", "sb-prepare", "sb-verify", "sb-run-0"}
+	if got := jobNames(jobs); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected jobs %v, got %v", want, got)
+	}
+
+	args := jobs[3].Spec.Template.Spec.Containers[0].Args
+	if !containsAll(args, []string{"mysql", "verify", "--database", "kubebench", "--expect", "sbtest1=1000", "sbtest2=1000"}) {
+		t.Fatalf("unexpected verify args: %#v", args)
+	}
+}
+
+func TestNewSysbenchVerifyJobsSkipsUnknownExpectations(t *testing.T) {
+	cr := &benchmarkv1alpha1.Sysbench{
+		ObjectMeta: metav1.ObjectMeta{Name: "sb", Namespace: "default"},
+		Spec: benchmarkv1alpha1.SysbenchSpec{
+			BenchCommon: benchmarkv1alpha1.BenchCommon{Target: newVerifyTestTarget(constants.MySqlDriver)},
+		},
+	}
+	if jobs := NewSysbenchVerifyJobs(cr); len(jobs) != 0 {
+		t.Fatalf("expected no verify job without tables and size, got %v", jobNames(jobs))
+	}
+
+	cr.Spec.Tables = 1
+	cr.Spec.Size = 10
+	cr.Spec.Types = []string{"oltp_read_only"}
+	if jobs := NewSysbenchVerifyJobs(cr); len(jobs) != 1 {
+		t.Fatalf("expected a verify job for a built-in test, got %v", jobNames(jobs))
+	}
+	for _, test := range []string{"app_checkout", "bulk_insert"} {
+		cr.Spec.Types = []string{test, "oltp_read_only"}
+		cr.Spec.Scripts = []string{"checkout-lua"}
+		if jobs := NewSysbenchVerifyJobs(cr); len(jobs) != 0 {
+			t.Fatalf("expected no verify job when prepare runs %s, got %v", test, jobNames(jobs))
+		}
+	}
+
+	cr.Spec.Types = []string{"oltp_read_only"}
+	cr.Spec.Target.Driver = constants.RedisDriver
+	if jobs := NewSysbenchVerifyJobs(cr); len(jobs) != 0 {
+		t.Fatalf("expected no verify job for unsupported driver, got %v", jobNames(jobs))
+	}
+}
+
+func TestNewTpccVerifyJobsScalesWithWarehouses(t *testing.T) {
+	cr := &benchmarkv1alpha1.Tpcc{
+		ObjectMeta: metav1.ObjectMeta{Name: "tpcc", Namespace: "default"},
+		Spec: benchmarkv1alpha1.TpccSpec{
+			WareHouses:  2,
+			BenchCommon: benchmarkv1alpha1.BenchCommon{Target: newVerifyTestTarget(constants.PostgreSqlDriver)},
+		},
+	}
+
+	jobs := NewTpccVerifyJobs(cr)
+	if len(jobs) != 1 {
+		t.Fatalf("expected one verify job, got %d", len(jobs))
+	}
+	args := jobs[0].Spec.Template.Spec.Containers[0].Args
+	for _, want := range []string{"bmsql_warehouse=2", "bmsql_district=20", "bmsql_customer=60000", "bmsql_stock=200000", "bmsql_item=100000"} {
+		if !containsAll(args, []string{want}) {
+			t.Fatalf("expected verify args to contain %s, got %#v", want, args)
+		}
+	}
+	if args[0] != "postgresql" {
+		t.Fatalf("expected postgresql tools command, got %s", args[0])
+	}
+}
+
+func TestNewPgbenchVerifyJobsScalesWithScale(t *testing.T) {
+	cr := &benchmarkv1alpha1.Pgbench{
+		ObjectMeta: metav1.ObjectMeta{Name: "pgbench", Namespace: "default"},
+		Spec: benchmarkv1alpha1.PgbenchSpec{
+			Scale:       3,
+			BenchCommon: benchmarkv1alpha1.BenchCommon{Target: newVerifyTestTarget("")},
+		},
+	}
+
+	jobs := NewPgbenchVerifyJobs(cr)
+	if len(jobs) != 1 {
+		t.Fatalf("expected one verify job, got %d", len(jobs))
+	}
+	args := jobs[0].Spec.Template.Spec.Containers[0].Args
+	if !containsAll(args, []string{"postgresql", "verify", "pgbench_branches=3", "pgbench_tellers=30", "pgbench_accounts=300000"}) {
+		t.Fatalf("unexpected verify args: %#v", args)
+	}
+}
+
+func TestNewYcsbVerifyJobsUsesYcsbDatabaseForMongodb(t *testing.T) {
+	cr := &benchmarkv1alpha1.Ycsb{
+		ObjectMeta: metav1.ObjectMeta{Name: "ycsb", Namespace: "default"},
+		Spec: benchmarkv1alpha1.YcsbSpec{
+			RecordCount: 5000,
+			BenchCommon: benchmarkv1alpha1.BenchCommon{Target: newVerifyTestTarget(constants.MongoDbDriver)},
+		},
+	}
+
+	jobs := NewYcsbVerifyJobs(cr)
+	if len(jobs) != 1 {
+		t.Fatalf("expected one verify job, got %d", len(jobs))
+	}
+	args := jobs[0].Spec.Template.Spec.Containers[0].Args
+	if !containsAll(args, []string{"mongodb", "verify", "--database", "ycsb", "usertable=5000"}) {
+		t.Fatalf("unexpected verify args: %#v", args)
+	}
+
+	cr.Spec.Target.Driver = constants.MinioDriver
+	if jobs := NewYcsbVerifyJobs(cr); len(jobs) != 0 {
+		t.Fatalf("expected no verify job for minio, got %v", jobNames(jobs))
+	}
+}
+
+func newVerifyTestTarget(driver string) benchmarkv1alpha1.Target {
+	return benchmarkv1alpha1.Target{
+		Driver:   driver,
+		Host:     "db.default.svc",
+		Port:     3306,
+		User:     "root",
+		Password: "secret",
+		Database: "kubebench",
+	}
+}
+
+func TestNewTpccJobsPrecheckNewDrivers(t *testing.T) {
+	for _, tc := range []struct {
+		driver   string
+		init     bool
+		verified bool
+	}{
+		{driver: constants.TidbDriver, init: true, verified: true},
+		{driver: constants.MssqlDriver, init: true, verified: true},
+		{driver: constants.OceanBaseOracleTenantDriver, init: true},
+	} {
+		cr := &benchmarkv1alpha1.Tpcc{
+			ObjectMeta: metav1.ObjectMeta{Name: "tpcc", Namespace: "default"},
+			Spec: benchmarkv1alpha1.TpccSpec{
+				WareHouses: 1,
+				Threads:    []int{1},
+				BenchCommon: benchmarkv1alpha1.BenchCommon{
+					Step:   constants.AllStep,
+					Target: newVerifyTestTarget(tc.driver),
+				},
+			},
+		}
+
+		jobs := NewTpccJobs(cr)
+		if len(jobs) == 0 || jobs[0].Name != "tpcc-precheck" {
+			t.Fatalf("%s: expected precheck job first, got %v", tc.driver, jobNames(jobs))
+		}
+		if args := jobs[0].Spec.Template.Spec.Containers[0].Args; !containsAll(args, []string{tc.driver, "ping", "--host", "db.default.svc", "--user", "root", "--password", "secret"}) {
+			t.Fatalf("%s: unexpected precheck args: %#v", tc.driver, args)
+		}
+
+		names := jobNames(jobs)
+		if got := containsAll(names, []string{"tpcc-verify"}); got != tc.verified {
+			t.Fatalf("%s: expected verify job %v, got %v", tc.driver, tc.verified, names)
+		}
+		for _, job := range jobs {
+			if job.Name != "tpcc-prepare" {
+				continue
+			}
+			initContainers := job.Spec.Template.Spec.InitContainers
+			if got := len(initContainers) == 1; got != tc.init {
+				t.Fatalf("%s: expected init container %v, got %d", tc.driver, tc.init, len(initContainers))
+			}
+			if tc.init && !containsAll(initContainers[0].Args, []string{tc.driver, "create", "kubebench"}) {
+				t.Fatalf("%s: unexpected init args: %#v", tc.driver, initContainers[0].Args)
+			}
+		}
+	}
+
+	// dameng can not be logged in to, it gets no precheck
+	cr := &benchmarkv1alpha1.Tpcc{
+		ObjectMeta: metav1.ObjectMeta{Name: "tpcc", Namespace: "default"},
+		Spec: benchmarkv1alpha1.TpccSpec{
+			WareHouses: 1,
+			Threads:    []int{1},
+			BenchCommon: benchmarkv1alpha1.BenchCommon{
+				Step:   constants.AllStep,
+				Target: newVerifyTestTarget(constants.DamengDriver),
+			},
+		},
+	}
+	if names := jobNames(NewTpccJobs(cr)); containsAll(names, []string{"tpcc-precheck"}) || containsAll(names, []string{"tpcc-verify"}) {
+		t.Fatalf("expected neither a precheck nor a verify job for dameng, got %v", names)
+	}
+}
+
+func TestNewSysbenchJobsTreatsTidbAsMysql(t *testing.T) {
+	cr := &benchmarkv1alpha1.Sysbench{
+		ObjectMeta: metav1.ObjectMeta{Name: "sb", Namespace: "default"},
+		Spec: benchmarkv1alpha1.SysbenchSpec{
+			Tables:  1,
+			Size:    100,
+			Threads: []int{4},
+			Types:   []string{"oltp_read_write"},
+			BenchCommon: benchmarkv1alpha1.BenchCommon{
+				Step:   constants.AllStep,
+				Target: newVerifyTestTarget(constants.TidbDriver),
+			},
+		},
+	}
+
+	jobs := NewSysbenchJobs(cr)
+	want := []string{"sb-precheck", "sb-cleanup", "sb-prepare", "sb-verify", "sb-run-0"}
+	if got := jobNames(jobs); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected jobs %v, got %v", want, got)
+	}
+	if configs := envValue(jobs[2], "CONFIGS"); !strings.Contains(configs, "driver:mysql") {
+		t.Fatalf("expected tidb to run with the mysql driver, got %s", configs)
+	}
+	if init := jobs[2].Spec.Template.Spec.InitContainers; len(init) != 1 || !containsAll(init[0].Args, []string{"tidb", "create", "kubebench"}) {
+		t.Fatalf("expected the prepare job to create the database, got %#v", init)
+	}
+}
@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	benchmarkv1alpha1 "github.com/apecloud/kubebench/api/v1alpha1"
+	"github.com/apecloud/kubebench/internal/utils"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+func TestParseWarmup(t *testing.T) {
+	tests := []struct {
+		warmup   string
+		duration time.Duration
+		fraction float64
+		seconds  int
+	}{
+		{warmup: "", seconds: 0},
+		{warmup: "30s", duration: 30 * time.Second, seconds: 30},
+		{warmup: "1m30s", duration: 90 * time.Second, seconds: 90},
+		{warmup: "10%", fraction: 0.1, seconds: 6},
+		{warmup: "100%", seconds: 0},
+		{warmup: "soon", seconds: 0},
+	}
+	for _, tt := range tests {
+		d, fraction := utils.ParseWarmup(tt.warmup)
+		if d != tt.duration || fraction != tt.fraction {
+			t.Fatalf("ParseWarmup(%q) = %v, %v, want %v, %v", tt.warmup, d, fraction, tt.duration, tt.fraction)
+		}
+		if got := utils.WarmupSeconds(tt.warmup, 60); got != tt.seconds {
+			t.Fatalf("WarmupSeconds(%q, 60) = %d, want %d", tt.warmup, got, tt.seconds)
+		}
+	}
+}
+
+func TestNewSysbenchRunJobsWarmup(t *testing.T) {
+	cr := &benchmarkv1alpha1.Sysbench{
+		ObjectMeta: metav1.ObjectMeta{Name: "sb", Namespace: "default"},
+		Spec: benchmarkv1alpha1.SysbenchSpec{
+			Duration: 120,
+			Threads:  []int{4},
+			Types:    []string{"oltp_read_write"},
+			BenchCommon: benchmarkv1alpha1.BenchCommon{
+				Target: newVerifyTestTarget(constants.MySqlDriver),
+			},
+		},
+	}
+
+	cmd := NewSysbenchRunJobs(cr)[0].Spec.Template.Spec.Containers[0].Args[0]
+	if strings.Contains(cmd, constants.WarmupStartMarker) {
+		t.Fatalf("did not expect a warmup pass: %s", cmd)
+	}
+
+	cr.Spec.Warmup = "25%"
+	job := NewSysbenchRunJobs(cr)[0]
+	cmd = job.Spec.Template.Spec.Containers[0].Args[0]
+	start := strings.Index(cmd, constants.WarmupStartMarker)
+	end := strings.Index(cmd, constants.WarmupEndMarker)
+	measured := strings.Index(cmd, `-c "${CONFIGS}"`)
+	if start < 0 || start > end || end > measured || !strings.Contains(cmd, `-c "${WARMUP_CONFIGS}"`) {
+		t.Fatalf("expected the warmup pass ahead of the measured pass: %s", cmd)
+	}
+	if !strings.HasSuffix(cmd, "| tee -a /var/log/sysbench.log") {
+		t.Fatalf("expected the measured pass to append to the log: %s", cmd)
+	}
+	if warmup := envValue(job, "WARMUP_CONFIGS"); !strings.Contains(warmup, "times:30,") || !strings.Contains(warmup, "threads:4,type:oltp_read_write") {
+		t.Fatalf("unexpected warmup configs: %s", warmup)
+	}
+	if configs := envValue(job, "CONFIGS"); !strings.Contains(configs, "times:120,") {
+		t.Fatalf("unexpected configs: %s", configs)
+	}
+}
+
+func TestNewPgbenchRunJobsWarmup(t *testing.T) {
+	cr := &benchmarkv1alpha1.Pgbench{
+		ObjectMeta: metav1.ObjectMeta{Name: "pgbench", Namespace: "default"},
+		Spec: benchmarkv1alpha1.PgbenchSpec{
+			Threads:      1,
+			Clients:      []int{8},
+			Transactions: 1000,
+			SelectOnly:   true,
+			Warmup:       "10%",
+			BenchCommon:  benchmarkv1alpha1.BenchCommon{Target: newVerifyTestTarget(constants.PostgreSqlDriver)},
+		},
+	}
+
+	cmd := NewPgbenchRunJobs(cr)[0].Spec.Template.Spec.Containers[0].Args[0]
+	warmup, measured, ok := strings.Cut(cmd, constants.WarmupEndMarker)
+	if !ok || !strings.Contains(warmup, "pgbench -P 1 -j 1 -t 100 -S") || !strings.Contains(warmup, "-c 8 2>&1 | tee -a /var/log/pgbench.log") {
+		t.Fatalf("unexpected warmup pass: %s", cmd)
+	}
+	if !strings.Contains(measured, "pgbench -P 1 -j 1 -t 1000 -S") {
+		t.Fatalf("unexpected measured pass: %s", cmd)
+	}
+
+	cr.Spec.Transactions = 0
+	cr.Spec.Duration = 300
+	cr.Spec.Warmup = "1m"
+	cmd = NewPgbenchRunJobs(cr)[0].Spec.Template.Spec.Containers[0].Args[0]
+	if !strings.Contains(cmd, "pgbench -P 1 -j 1 -T 60 -S") || !strings.Contains(cmd, "pgbench -P 1 -j 1 -T 300 -S") {
+		t.Fatalf("unexpected passes: %s", cmd)
+	}
+}
+
+func TestNewYcsbRunJobsWarmup(t *testing.T) {
+	cr := &benchmarkv1alpha1.Ycsb{
+		ObjectMeta: metav1.ObjectMeta{Name: "ycsb", Namespace: "default"},
+		Spec: benchmarkv1alpha1.YcsbSpec{
+			RecordCount:    1000,
+			OperationCount: 5000,
+			ReadProportion: 100,
+			Threads:        []int{4},
+			Warmup:         "20%",
+			BenchCommon:    benchmarkv1alpha1.BenchCommon{Target: newVerifyTestTarget(constants.MySqlDriver)},
+		},
+	}
+
+	cmd := NewYcsbRunJobs(cr)[0].Spec.Template.Spec.Containers[0].Command[2]
+	warmup, measured, ok := strings.Cut(cmd, constants.WarmupEndMarker)
+	if !ok || !strings.HasPrefix(cmd, "echo '"+constants.WarmupStartMarker) || !strings.Contains(warmup, "-p threadcount=4 -p operationcount=1000") {
+		t.Fatalf("unexpected warmup pass: %s", cmd)
+	}
+	if strings.Contains(measured, "operationcount=1000") {
+		t.Fatalf("unexpected measured pass: %s", measured)
+	}
+
+	cr.Spec.Warmup = "45s"
+	cmd = NewYcsbRunJobs(cr)[0].Spec.Template.Spec.Containers[0].Command[2]
+	if !strings.Contains(cmd, "-p threadcount=4 -p maxexecutiontime=45") {
+		t.Fatalf("unexpected warmup pass: %s", cmd)
+	}
+
+	cr.Spec.Warmup = "20%"
+	cr.Spec.OperationCount = 0
+	cmd = NewYcsbRunJobs(cr)[0].Spec.Template.Spec.Containers[0].Command[2]
+	if strings.Contains(cmd, constants.WarmupStartMarker) {
+		t.Fatalf("expected no warmup without an operation count: %s", cmd)
+	}
+}
@@ -19,12 +19,12 @@ package controller
 import (
 	"context"
 	"fmt"
-	"strings"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -33,6 +33,7 @@ import (
 	benchmarkv1alpha1 "github.com/apecloud/kubebench/api/v1alpha1"
 	intctrlutil "github.com/apecloud/kubebench/internal/controllerutil"
 	"github.com/apecloud/kubebench/internal/utils"
+	"github.com/apecloud/kubebench/pkg/constants"
 )
 
 // YcsbReconciler reconciles a Ycsb object
@@ -40,6 +41,7 @@ type YcsbReconciler struct {
 	client.Client
 	Scheme     *runtime.Scheme
 	RestConfig *rest.Config
+	Recorder   record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=benchmark.apecloud.io,resources=ycsbs,verbs=get;list;watch;create;update;patch;delete
@@ -57,6 +59,8 @@ type YcsbReconciler struct {
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.15.0/pkg/reconcile
 func (r *YcsbReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	l := log.FromContext(ctx)
+	// the summary and the archive of a finished job share its logs
+	ctx = utils.WithPodLogs(ctx)
 
 	var ycsb benchmarkv1alpha1.Ycsb
 	if err := r.Get(ctx, req.NamespacedName, &ycsb); err != nil {
@@ -64,12 +68,58 @@ func (r *YcsbReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 	}
 	old := ycsb.DeepCopy()
 
+	// record the logs archived to a pvc once they are copied into the claim
+	archiving, err := utils.SyncArchivedLogs(r.Client, ctx, &ycsb, ycsb.Spec.LogArchive, &ycsb.Status.Logs)
+	if err != nil {
+		return intctrlutil.RequeueWithError(err, l, "unable to sync the archived logs")
+	}
+
 	// run if bench completion
 	if ycsb.Status.Phase == benchmarkv1alpha1.Completed || ycsb.Status.Phase == benchmarkv1alpha1.Failed {
-		return intctrlutil.Reconciled()
+		if err := r.Status().Patch(ctx, &ycsb, client.MergeFrom(old)); err != nil {
+			l.Error(err, "failed to patch ycsb status")
+			return intctrlutil.RequeueWithError(err, l, "failed to patch ycsb status")
+		}
+		if archiving {
+			return intctrlutil.RequeueAfter(intctrlutil.RequeueDuration)
+		}
+		// collect the jobs once the ttl expired
+		requeueAfter, err := utils.CollectFinished(r.Client, ctx, &ycsb, &ycsb.Spec.BenchCommon, ycsb.Status.CompletionTimestamp)
+		if err != nil {
+			return intctrlutil.RequeueWithError(err, l, "failed to collect the finished ycsb")
+		}
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
 	}
 
-	jobs := NewYcsbJobs(&ycsb)
+	if len(ycsb.Spec.Targets) > 0 {
+		phase, succeeded, err := utils.ReconcileTargets(r.Client, ctx, r.Scheme, &ycsb, ycsb.Spec.Targets, ycsb.Spec.TargetsPolicy, &ycsb.Status.Targets)
+		if err != nil {
+			l.Error(err, "failed to reconcile the targets", "ycsb", ycsb.Name)
+			return intctrlutil.RequeueWithError(err, l, "failed to reconcile the targets")
+		}
+		ycsb.Status.Phase = phase
+		ycsb.Status.Succeeded = succeeded
+		ycsb.Status.Total = len(ycsb.Spec.Targets)
+		ycsb.Status.Completions = fmt.Sprintf("%d/%d", ycsb.Status.Succeeded, ycsb.Status.Total)
+		if phase == benchmarkv1alpha1.Completed || phase == benchmarkv1alpha1.Failed {
+			ycsb.Status.CompletionTimestamp = &metav1.Time{Time: time.Now()}
+		}
+		utils.RecordPhaseTransition(r.Recorder, &ycsb, old.Status.Phase, ycsb.Status.Phase)
+		if err := r.Status().Patch(ctx, &ycsb, client.MergeFrom(old)); err != nil {
+			l.Error(err, "failed to patch ycsb status")
+			return intctrlutil.RequeueWithError(err, l, "failed to patch ycsb status")
+		}
+		return intctrlutil.RequeueAfter(intctrlutil.RequeueDuration)
+	}
+
+	resolved, err := utils.ResolveTarget(r.Client, ctx, ycsb.Namespace, ycsb.Spec.TargetRef, ycsb.Status.Target, &ycsb.Spec.Target)
+	if err != nil {
+		l.Error(err, "failed to resolve the target", "ycsb", ycsb.Name)
+		return intctrlutil.RequeueWithError(err, l, "failed to resolve the target")
+	}
+	ycsb.Status.Target = resolved
+
+	jobs := utils.SetJobDeadlines(NewYcsbJobs(&ycsb), ycsb.Spec.Timeouts)
 
 	if ycsb.Status.Phase == "" {
 		l.Info("start ycsb", "ycsb", ycsb.Name)
@@ -79,6 +129,10 @@ func (r *YcsbReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 
 	if ycsb.Status.Succeeded >= ycsb.Status.Total {
 		ycsb.Status.Phase = benchmarkv1alpha1.Completed
+		if ycsb.Status.Reason != "" {
+			// a job failed and the afterAll hooks ran after it
+			ycsb.Status.Phase = benchmarkv1alpha1.Failed
+		}
 		ycsb.Status.CompletionTimestamp = &metav1.Time{Time: time.Now()}
 	} else {
 		job := jobs[ycsb.Status.Succeeded]
@@ -102,6 +156,7 @@ func (r *YcsbReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 
 			// wait for the job to be created
 			l.Info("created job", "job", job.Name)
+			utils.RecordJobCreated(r.Recorder, &ycsb, job, ycsb.Spec.Target.Password, ycsb.Spec.RedisSentinelPassword)
 			return intctrlutil.RequeueAfter(intctrlutil.RequeueDuration)
 		}
 
@@ -111,26 +166,66 @@ func (r *YcsbReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 			l.Error(err, "failed to get job status", "job", job.Name)
 			return intctrlutil.RequeueWithError(err, l, "failed to get job status")
 		}
+		// the nodes cordoned by the faults are schedulable again once the run job finished
+		if err := utils.UncordonNodes(r.Client, ctx, ycsb.Spec.Faults, job, status); err != nil {
+			return intctrlutil.RequeueWithError(err, l, "unable to uncordon the nodes")
+		}
 
 		if status.Succeeded > 0 {
 			l.Info("job completed", "job", job.Name)
+			utils.RecordJobFinished(r.Client, ctx, r.Recorder, &ycsb, job, true)
+			utils.ObserveJobFinished(r.Client, ctx, constants.YcsbType, jobs, ycsb.Status.Succeeded, true)
 			ycsb.Status.Succeeded++
 			// record the result
-			if err := utils.LogJobPodToCond(r.Client, r.RestConfig, ctx, job.Name, ycsb.Namespace, &ycsb.Status.Conditions, ParseYcsb); err != nil {
+			if err := utils.RecordJobSummary(r.Client, r.RestConfig, ctx, job.Name, ycsb.Namespace, &ycsb.Status.Conditions, constants.YcsbType); err != nil {
 				return intctrlutil.RequeueWithError(err, l, "unable to record the log")
 			}
+			if err := utils.ArchiveJobLogs(r.Client, r.RestConfig, ctx, r.Scheme, &ycsb, ycsb.Spec.LogArchive, job.Name, &ycsb.Status.Logs); err != nil {
+				return intctrlutil.RequeueWithError(err, l, "unable to archive the log")
+			}
+		} else if step := utils.TimedOutStep(&ycsb, ycsb.Spec.Timeouts, job, status); step != "" {
+			if ycsb.Status.TimedOutStep != "" {
+				step = ycsb.Status.TimedOutStep
+			}
+			ycsb.Status.TimedOutStep = step
+			done, err := utils.StopTimedOutJob(r.Client, ctx, r.Recorder, &ycsb, constants.YcsbType, job, step)
+			if err != nil {
+				return intctrlutil.RequeueWithError(err, l, "unable to stop the job")
+			}
+			if done {
+				l.Info("job timed out", "job", job.Name, "step", step)
+				ycsb.Status.Phase = utils.FailJob(jobs, &ycsb.Status.Succeeded)
+				ycsb.Status.Reason = utils.TimedOutReason
+				if err := utils.LogJobPodToCond(r.Client, r.RestConfig, ctx, job.Name, ycsb.Namespace, &ycsb.Status.Conditions, nil); err != nil {
+					return intctrlutil.RequeueWithError(err, l, "unable to record the log")
+				}
+				if err := utils.ArchiveJobLogs(r.Client, r.RestConfig, ctx, r.Scheme, &ycsb, ycsb.Spec.LogArchive, job.Name, &ycsb.Status.Logs); err != nil {
+					return intctrlutil.RequeueWithError(err, l, "unable to archive the log")
+				}
+			}
 		} else if status.Failed > 0 {
 			l.Info("job failed", "job", job.Name)
-			ycsb.Status.Phase = benchmarkv1alpha1.Failed
+			utils.RecordJobFinished(r.Client, ctx, r.Recorder, &ycsb, job, false)
+			utils.ObserveJobFinished(r.Client, ctx, constants.YcsbType, jobs, ycsb.Status.Succeeded, false)
+			if ycsb.Status.Reason == "" {
+				ycsb.Status.Reason = utils.JobFailedReason
+			}
+			ycsb.Status.Phase = utils.FailJob(jobs, &ycsb.Status.Succeeded)
 			if err := utils.LogJobPodToCond(r.Client, r.RestConfig, ctx, job.Name, ycsb.Namespace, &ycsb.Status.Conditions, nil); err != nil {
 				return intctrlutil.RequeueWithError(err, l, "unable to record the log")
 			}
+			if err := utils.ArchiveJobLogs(r.Client, r.RestConfig, ctx, r.Scheme, &ycsb, ycsb.Spec.LogArchive, job.Name, &ycsb.Status.Logs); err != nil {
+				return intctrlutil.RequeueWithError(err, l, "unable to archive the log")
+			}
 		} else {
 			l.Info("job is running", "job", job.Name)
+			utils.RecordJobStarted(r.Client, ctx, r.Recorder, &ycsb, job.Name, job.Namespace)
+			utils.InjectFaults(r.Client, ctx, r.Scheme, &ycsb, ycsb.Spec.Faults, job, status, &ycsb.Status.Faults)
 		}
 	}
 
 	ycsb.Status.Completions = fmt.Sprintf("%d/%d", ycsb.Status.Succeeded, ycsb.Status.Total)
+	utils.RecordPhaseTransition(r.Recorder, &ycsb, old.Status.Phase, ycsb.Status.Phase)
 	if err := r.Status().Patch(ctx, &ycsb, client.MergeFrom(old)); err != nil {
 		return intctrlutil.RequeueWithError(err, l, "unable to update ycsb status")
 	}
@@ -144,27 +239,3 @@ func (r *YcsbReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		For(&benchmarkv1alpha1.Ycsb{}).
 		Complete(r)
 }
-
-func ParseYcsb(msg string) string {
-	result := ""
-	lines := strings.Split(msg, "\n")
-	index := len(lines)
-
-	for i, l := range lines {
-		if strings.Contains(l, "Run finished, takes") {
-			index = i
-			result += fmt.Sprintf("%s\n", l)
-			break
-		}
-	}
-
-	for i := index + 1; i < len(lines); i++ {
-		if lines[i] != "" {
-			// align the output
-			result += fmt.Sprintf("%*s\n", len(lines[i])+27, lines[i])
-		}
-	}
-
-	// delete the last \n
-	return strings.TrimSpace(result)
-}
@@ -13,6 +13,9 @@ import (
 	"github.com/apecloud/kubebench/pkg/constants"
 )
 
+// ycsbDefaultTable is the table go-ycsb loads records into
+const ycsbDefaultTable = "usertable"
+
 func NewYcsbJobs(cr *v1alpha1.Ycsb) []*batchv1.Job {
 	jobs := make([]*batchv1.Job, 0)
 
@@ -23,14 +26,20 @@ func NewYcsbJobs(cr *v1alpha1.Ycsb) []*batchv1.Job {
 
 	step := cr.Spec.Step
 	if step == constants.CleanupStep || step == constants.AllStep {
-		jobs = append(jobs, NewYcsbCleanupJobs(cr)...)
+		jobs = append(jobs, utils.MarkStepJobs(NewYcsbCleanupJobs(cr), constants.CleanupStep)...)
 	}
 	if step == constants.PrepareStep || step == constants.AllStep {
-		jobs = append(jobs, NewYcsbPrepareJobs(cr)...)
+		jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.BeforePrepareHook, cr.Spec.Hooks, &cr.Spec.Target)...)
+		jobs = append(jobs, utils.MarkStepJobs(NewYcsbPrepareJobs(cr), constants.PrepareStep)...)
+		jobs = append(jobs, utils.MarkStepJobs(NewYcsbVerifyJobs(cr), constants.PrepareStep)...)
+		jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.AfterPrepareHook, cr.Spec.Hooks, &cr.Spec.Target)...)
 	}
 	if step == constants.RunStep || step == constants.AllStep {
-		jobs = append(jobs, NewYcsbRunJobs(cr)...)
+		jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.BeforeRunHook, cr.Spec.Hooks, &cr.Spec.Target)...)
+		jobs = append(jobs, utils.MarkRunJobs(NewYcsbRunJobs(cr))...)
+		jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.AfterRunHook, cr.Spec.Hooks, &cr.Spec.Target)...)
 	}
+	jobs = append(jobs, utils.NewHookJobs(cr.Name, cr.Namespace, constants.AfterAllHook, cr.Spec.Hooks, &cr.Spec.Target)...)
 
 	// set tolerations for all jobs
 	utils.AddTolerationToJobs(jobs, cr.Spec.Tolerations)
@@ -112,6 +121,28 @@ func NewYcsbPrepareJobs(cr *v1alpha1.Ycsb) []*batchv1.Job {
 	return []*batchv1.Job{job}
 }
 
+// NewYcsbVerifyJobs checks that the load phase inserted every record
+func NewYcsbVerifyJobs(cr *v1alpha1.Ycsb) []*batchv1.Job {
+	if cr.Spec.RecordCount <= 0 {
+		return nil
+	}
+
+	database := cr.Spec.Target.Database
+	if cr.Spec.Target.Driver == constants.MongoDbDriver {
+		// 'ycsb' is the default database name when running ycsb on mongodb
+		database = "ycsb"
+	}
+	expect := map[string]int64{
+		ycsbDefaultTable: int64(cr.Spec.RecordCount),
+	}
+
+	job := utils.NewVerifyJob(cr.Name, cr.Namespace, cr.Spec.Target.Driver, &cr.Spec.Target, database, expect)
+	if job == nil {
+		return nil
+	}
+	return []*batchv1.Job{job}
+}
+
 func NewYcsbRunJobs(cr *v1alpha1.Ycsb) []*batchv1.Job {
 	cmd := "/go-ycsb"
 	cmd = fmt.Sprintf("%s run %s --interval 1", cmd, getYcsbDriver(cr.Spec.Target.Driver))
@@ -143,6 +174,10 @@ func NewYcsbRunJobs(cr *v1alpha1.Ycsb) []*batchv1.Job {
 		jobName := fmt.Sprintf("%s-run-%d", cr.Name, i)
 		curJob := utils.JobTemplate(jobName, cr.Namespace)
 		curCmd := fmt.Sprintf("%s -p threadcount=%d", cmd, thread)
+		if warmup := ycsbWarmupParams(cr); warmup != "" {
+			// later properties override earlier ones
+			curCmd = utils.WarmupCommand(fmt.Sprintf("%s %s", curCmd, warmup), "") + curCmd
+		}
 		curJob.Spec.Template.Spec.Containers = append(
 			curJob.Spec.Template.Spec.Containers,
 			corev1.Container{
@@ -158,6 +193,22 @@ func NewYcsbRunJobs(cr *v1alpha1.Ycsb) []*batchv1.Job {
 	return jobs
 }
 
+// ycsbWarmupParams limits the warmup pass, a percentage is taken of the
+// operation count, a duration stops the pass after that many seconds. An empty
+// string is returned without warmup or for a percentage without an operation
+// count, operationcount=0 would run the warmup without limit.
+func ycsbWarmupParams(cr *v1alpha1.Ycsb) string {
+	d, fraction := utils.ParseWarmup(cr.Spec.Warmup)
+	switch {
+	case fraction > 0 && cr.Spec.OperationCount > 0:
+		return fmt.Sprintf("-p operationcount=%d", utils.WarmupCount(fraction, cr.Spec.OperationCount))
+	case d > 0:
+		return fmt.Sprintf("-p maxexecutiontime=%d", utils.WarmupSeconds(cr.Spec.Warmup, 0))
+	default:
+		return ""
+	}
+}
+
 func NewYcsbWorkloadParams(cr *v1alpha1.Ycsb) string {
 	switch cr.Spec.Target.Driver {
 	case constants.MySqlDriver:
@@ -0,0 +1,95 @@
+package exporter
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Progress is the progress of the running scrape, the percent complete is
+// only known if the duration of the run is
+type Progress struct {
+	Kind      string `json:"kind"`
+	Benchmark string `json:"benchmark"`
+	Job       string `json:"job"`
+
+	ElapsedSeconds  float64 `json:"elapsedSeconds"`
+	DurationSeconds float64 `json:"durationSeconds,omitempty"`
+	PercentComplete float64 `json:"percentComplete,omitempty"`
+
+	// Complete is true once the final report was parsed
+	Complete bool `json:"complete"`
+
+	// LastInterval holds the samples of the last interval report
+	LastInterval *Interval `json:"lastInterval,omitempty"`
+}
+
+// CurrentProgress returns the progress of the running scrape, false if no
+// scrape started. duration is the configured duration of the run, 0 if it
+// is unknown.
+func CurrentProgress(duration time.Duration) (Progress, bool) {
+	resultMu.Lock()
+	defer resultMu.Unlock()
+	if current == nil {
+		return Progress{}, false
+	}
+
+	progress := Progress{
+		Kind:           current.Kind,
+		Benchmark:      current.Benchmark,
+		Job:            current.Job,
+		ElapsedSeconds: float64(time.Now().Unix() - current.Start),
+		Complete:       current.Complete,
+	}
+	if n := len(current.Intervals); n > 0 {
+		last := current.Intervals[n-1]
+		progress.LastInterval = &last
+	}
+	if duration > 0 {
+		progress.DurationSeconds = duration.Seconds()
+		progress.PercentComplete = math.Min(100, math.Round(progress.ElapsedSeconds/duration.Seconds()*100))
+	}
+	if progress.Complete {
+		progress.PercentComplete = 100
+	}
+	return progress, true
+}
+
+// CurrentResult returns the result of the scrape without the intervals,
+// false until the final report was parsed
+func CurrentResult() (Result, bool) {
+	resultMu.Lock()
+	defer resultMu.Unlock()
+	if current == nil || !current.Complete {
+		return Result{}, false
+	}
+	result := *current
+	result.Intervals = nil
+	return result, true
+}
+
+// RegisterAPI adds the endpoints of the health, the progress and the result
+// of the scrape to the router
+func RegisterAPI(r gin.IRoutes, duration time.Duration) {
+	r.GET("/healthz", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	r.GET("/progress", func(c *gin.Context) {
+		progress, ok := CurrentProgress(duration)
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "scraping did not start"})
+			return
+		}
+		c.JSON(http.StatusOK, progress)
+	})
+	r.GET("/result", func(c *gin.Context) {
+		result, ok := CurrentResult()
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "no final report yet"})
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	})
+}
@@ -0,0 +1,62 @@
+package exporter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	RegisterAPI(r, 100*time.Second)
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	setCurrent(nil)
+	if w := get("/healthz"); w.Code != http.StatusOK {
+		t.Fatalf("expected healthz to be ok, got %d", w.Code)
+	}
+	if w := get("/progress"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected no progress before scraping, got %d", w.Code)
+	}
+
+	result := newResult(Sysbench, "sb", "sb-run-0")
+	result.Start = time.Now().Add(-50 * time.Second).Unix()
+	setCurrent(result)
+	result.addInterval([]Sample{{Name: SysbenchTpsSecondName, Value: 563.4}})
+
+	w := get("/progress")
+	progress := Progress{}
+	if err := json.Unmarshal(w.Body.Bytes(), &progress); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("unexpected progress %d %s", w.Code, w.Body)
+	}
+	// the start is in whole seconds
+	if progress.ElapsedSeconds < 50 || progress.ElapsedSeconds > 51 || progress.PercentComplete != progress.ElapsedSeconds ||
+		progress.Complete || progress.LastInterval.Samples[0].Value != 563.4 {
+		t.Fatalf("unexpected progress: %+v", progress)
+	}
+	if w := get("/result"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected no result before the final report, got %d", w.Code)
+	}
+
+	result.setSummary([]Sample{{Name: SysbenchTransactionsName, Value: 45267}})
+	w = get("/result")
+	final := Result{}
+	if err := json.Unmarshal(w.Body.Bytes(), &final); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("unexpected result %d %s", w.Code, w.Body)
+	}
+	if !final.Complete || len(final.Summary) != 1 || len(final.Intervals) != 0 {
+		t.Fatalf("expected the summary without intervals: %+v", final)
+	}
+	if progress, _ := CurrentProgress(100 * time.Second); progress.PercentComplete != 100 {
+		t.Fatalf("expected a complete progress: %+v", progress)
+	}
+}
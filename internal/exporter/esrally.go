@@ -2,15 +2,13 @@ package exporter
 
 import (
 	"encoding/csv"
+	"encoding/json"
 	"fmt"
-	"os"
 	"sort"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"k8s.io/klog/v2"
 )
 
 const (
@@ -18,6 +16,7 @@ const (
 	EsrallyMetricValueHelp   = "Numeric Elastic Rally summary report value"
 	esrallyUnavailablePrefix = "kubebench metrics unavailable:"
 	esrallySummaryFallback   = "No numeric Rally CSV summary was found. Inspect the Esrally pod logs and configured report file for full output."
+	esrallyJSONMarker        = "Rally JSON report:"
 )
 
 var (
@@ -32,14 +31,53 @@ type EsrallyMetric struct {
 	Value  float64
 }
 
-func InitEsrally() {
-	EsrallyGaugeMap[EsrallyMetricValueName] = NewGauge(EsrallyMetricValueName, EsrallyMetricValueHelp, EsrallyLabels)
+func init() {
+	RegisterParser(Esrally, esrallyParser{}, &EsrallyGaugeMap)
 }
 
-func RegisterEsrallyMetrics() {
-	for _, gauge := range EsrallyGaugeMap {
-		prometheus.MustRegister(gauge)
+// esrallyParser parses the csv report Rally writes when the race finished, or
+// the json results of the race if the run wrote those instead
+type esrallyParser struct{}
+
+func (esrallyParser) Metrics() []MetricDesc {
+	return []MetricDesc{{Name: EsrallyMetricValueName, Help: EsrallyMetricValueHelp, Labels: EsrallyLabels[2:]}}
+}
+
+func (esrallyParser) Streaming() bool {
+	return false
+}
+
+func (esrallyParser) ParseInterval(string) ([]Sample, bool) {
+	return nil, false
+}
+
+func (esrallyParser) ParseSummary(output string) ([]Sample, bool) {
+	metrics := ParseEsrallyReport(output)
+	if len(metrics) == 0 {
+		return nil, false
+	}
+	samples := make([]Sample, 0, len(metrics))
+	for _, metric := range metrics {
+		samples = append(samples, Sample{
+			Name:   EsrallyMetricValueName,
+			Labels: []string{metric.Metric, metric.Task, metric.Unit},
+			Value:  metric.Value,
+		})
+	}
+	return samples, true
+}
+
+func (esrallyParser) Summarize(output string) string {
+	return SummarizeEsrallyReport(output, 12)
+}
+
+// ParseEsrallyReport parses the json results of the race if msg has them,
+// the csv report otherwise
+func ParseEsrallyReport(msg string) []EsrallyMetric {
+	if metrics := ParseEsrallyJSON(msg); len(metrics) > 0 {
+		return metrics
 	}
+	return ParseEsrallyCSV(msg)
 }
 
 func ParseEsrallyCSV(msg string) []EsrallyMetric {
@@ -124,7 +162,16 @@ func extractEsrallyCSV(msg string) string {
 }
 
 func SummarizeEsrallyCSV(msg string, limit int) string {
-	metrics := ParseEsrallyCSV(msg)
+	return summarizeEsrallyMetrics(ParseEsrallyCSV(msg), msg, limit)
+}
+
+// SummarizeEsrallyReport summarizes the json results of the race if msg has
+// them, the csv report otherwise
+func SummarizeEsrallyReport(msg string, limit int) string {
+	return summarizeEsrallyMetrics(ParseEsrallyReport(msg), msg, limit)
+}
+
+func summarizeEsrallyMetrics(metrics []EsrallyMetric, msg string, limit int) string {
 	if len(metrics) == 0 {
 		return strings.Join(append([]string{esrallySummaryFallback}, esrallyMetricsUnavailableMessages(msg)...), "\n")
 	}
@@ -212,7 +259,7 @@ func isKnownEsrallyUnavailableMessage(line string) bool {
 
 	switch line {
 	case "kubebench metrics unavailable: spec.metrics is false",
-		"kubebench metrics unavailable: the exporter only supports reportFormat csv",
+		"kubebench metrics unavailable: the exporter only supports reportFormat csv or json",
 		"kubebench metrics unavailable: reportFile must be under /var/log for the exporter shared volume":
 		return true
 	default:
@@ -220,39 +267,137 @@ func isKnownEsrallyUnavailableMessage(line string) bool {
 	}
 }
 
-func ScrapeEsrally(file, doneFile, benchName, jobName string) {
-	klog.Infof("read esrally report file %s", file)
-	ticker := time.NewTicker(time.Second)
-	defer ticker.Stop()
+// esrallyOpMetrics are the results of a task in the json results of the race
+type esrallyOpMetrics struct {
+	Task           string                 `json:"task"`
+	Throughput     map[string]interface{} `json:"throughput"`
+	Latency        map[string]interface{} `json:"latency"`
+	ServiceTime    map[string]interface{} `json:"service_time"`
+	ProcessingTime map[string]interface{} `json:"processing_time"`
+	ErrorRate      *float64               `json:"error_rate"`
+}
 
-	for {
-		if content, err := os.ReadFile(file); err == nil && len(content) > 0 {
-			if UpdateEsrallyMetrics(benchName, jobName, string(content)) {
-				return
-			}
+// ParseEsrallyJSON parses the results of the race.json of Rally, either the
+// whole race or only its results. Every task reports the min, mean, median
+// and max throughput, the percentiles of the latency, the service time and
+// the processing time and the error rate, named like the rows of the csv
+// report. The numeric totals of the race are kept by their json name.
+func ParseEsrallyJSON(msg string) []EsrallyMetric {
+	text := strings.TrimSpace(msg)
+	if idx := strings.Index(text, esrallyJSONMarker); idx >= 0 {
+		text = strings.TrimSpace(text[idx+len(esrallyJSONMarker):])
+	}
+	if !strings.HasPrefix(text, "{") {
+		return nil
+	}
+
+	var results map[string]json.RawMessage
+	if err := json.NewDecoder(strings.NewReader(text)).Decode(&results); err != nil {
+		return nil
+	}
+	if race, ok := results["results"]; ok {
+		results = nil
+		if err := json.Unmarshal(race, &results); err != nil {
+			return nil
 		}
-		if doneFile != "" {
-			if _, err := os.Stat(doneFile); err == nil {
-				klog.Infof("esrally done marker found before report metrics were parsed: %s", doneFile)
-				return
+	}
+
+	metrics := make([]EsrallyMetric, 0)
+	var ops []esrallyOpMetrics
+	if err := json.Unmarshal(results["op_metrics"], &ops); err == nil {
+		for _, op := range ops {
+			metrics = append(metrics, esrallyThroughputMetrics(op.Task, op.Throughput)...)
+			metrics = append(metrics, esrallyPercentileMetrics(op.Task, "latency", op.Latency)...)
+			metrics = append(metrics, esrallyPercentileMetrics(op.Task, "service time", op.ServiceTime)...)
+			metrics = append(metrics, esrallyPercentileMetrics(op.Task, "processing time", op.ProcessingTime)...)
+			if op.ErrorRate != nil {
+				metrics = append(metrics, EsrallyMetric{Metric: "error rate", Task: op.Task, Unit: "%", Value: *op.ErrorRate * 100})
 			}
 		}
-		<-ticker.C
 	}
+
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var value float64
+		if err := json.Unmarshal(results[name], &value); err != nil {
+			continue
+		}
+		metrics = append(metrics, EsrallyMetric{Metric: name, Unit: esrallyTotalUnit(name), Value: value})
+	}
+	return metrics
 }
 
-func UpdateEsrallyMetrics(benchName, jobName, msg string) bool {
-	metrics := ParseEsrallyCSV(msg)
-	if len(metrics) == 0 {
-		return false
+func esrallyThroughputMetrics(task string, stats map[string]interface{}) []EsrallyMetric {
+	unit, _ := stats["unit"].(string)
+	metrics := make([]EsrallyMetric, 0, 4)
+	for _, stat := range []string{"min", "mean", "median", "max"} {
+		if value, ok := stats[stat].(float64); ok {
+			metrics = append(metrics, EsrallyMetric{
+				Metric: fmt.Sprintf("%s%s Throughput", strings.ToUpper(stat[:1]), stat[1:]),
+				Task:   task,
+				Unit:   unit,
+				Value:  value,
+			})
+		}
 	}
+	return metrics
+}
 
-	CommonCounterInc(benchName, jobName, Esrally)
-	for _, metric := range metrics {
-		EsrallyGaugeMap[EsrallyMetricValueName].
-			WithLabelValues(benchName, jobName, metric.Metric, metric.Task, metric.Unit).
-			Set(metric.Value)
+// esrallyPercentileMetrics returns the percentiles of the stats in increasing
+// order, Rally names the 99.9th percentile 99_9
+func esrallyPercentileMetrics(task, name string, stats map[string]interface{}) []EsrallyMetric {
+	unit, _ := stats["unit"].(string)
+	type percentile struct {
+		name  string
+		rank  float64
+		value float64
+	}
+	percentiles := make([]percentile, 0, len(stats))
+	for key, v := range stats {
+		value, ok := v.(float64)
+		if !ok {
+			continue
+		}
+		p := strings.TrimSuffix(key, "_0")
+		p = strings.ReplaceAll(p, "_", ".")
+		rank, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			continue
+		}
+		percentiles = append(percentiles, percentile{name: p, rank: rank, value: value})
+	}
+	sort.Slice(percentiles, func(i, j int) bool {
+		return percentiles[i].rank < percentiles[j].rank
+	})
+
+	metrics := make([]EsrallyMetric, 0, len(percentiles)+1)
+	if mean, ok := stats["mean"].(float64); ok {
+		metrics = append(metrics, EsrallyMetric{Metric: "Mean " + name, Task: task, Unit: unit, Value: mean})
+	}
+	for _, p := range percentiles {
+		metrics = append(metrics, EsrallyMetric{
+			Metric: fmt.Sprintf("%sth percentile %s", p.name, name),
+			Task:   task,
+			Unit:   unit,
+			Value:  p.value,
+		})
+	}
+	return metrics
+}
+
+// esrallyTotalUnit returns the unit of a total of the race, Rally reports the
+// times in ms and the sizes in bytes
+func esrallyTotalUnit(name string) string {
+	switch {
+	case strings.HasSuffix(name, "_time"):
+		return "ms"
+	case strings.HasSuffix(name, "_size"):
+		return "bytes"
+	default:
+		return ""
 	}
-	klog.Info("update esrally metrics")
-	return true
 }
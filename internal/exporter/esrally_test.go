@@ -145,7 +145,7 @@ func TestSummarizeEsrallyCSVIgnoresMarkdownReport(t *testing.T) {
 |   Metric |   Task |   Value |   Unit |
 |---------:|-------:|--------:|-------:|
 |      Min Throughput | index-append | 1000 | docs/s |
-kubebench metrics unavailable: the exporter only supports reportFormat csv`
+kubebench metrics unavailable: the exporter only supports reportFormat csv or json`
 
 	result := ParseEsrallyCSV(msg)
 	if len(result) != 0 {
@@ -156,7 +156,7 @@ kubebench metrics unavailable: the exporter only supports reportFormat csv`
 	if !strings.Contains(summary, "No numeric Rally CSV summary was found.") {
 		t.Fatalf("summary should explain the unsupported report summary: %s", summary)
 	}
-	if !strings.Contains(summary, "kubebench metrics unavailable: the exporter only supports reportFormat csv") {
+	if !strings.Contains(summary, "kubebench metrics unavailable: the exporter only supports reportFormat csv or json") {
 		t.Fatalf("summary should keep explicit metrics unavailable message: %s", summary)
 	}
 }
@@ -195,8 +195,8 @@ kubebench metrics unavailable: clientOptions basic_auth_password:'super-secret'`
 func TestUpdateEsrallyMetricsSetsExpectedLabels(t *testing.T) {
 	resetEsrallyTestMetrics()
 
-	if !UpdateEsrallyMetrics("bench-a", "rally-run", `Metric,Task,Value,Unit
-Mean Throughput,index-append,1200.5,docs/s`) {
+	if _, ok := UpdateSummary(Esrally, "bench-a", "rally-run", `Metric,Task,Value,Unit
+Mean Throughput,index-append,1200.5,docs/s`); !ok {
 		t.Fatal("expected metrics to be updated")
 	}
 
@@ -214,9 +214,9 @@ Mean Throughput,index-append,1200.5,docs/s`) {
 func TestUpdateEsrallyMetricsReturnsFalseWithoutNumericRows(t *testing.T) {
 	resetEsrallyTestMetrics()
 
-	if UpdateEsrallyMetrics("bench-a", "rally-run", `Metric,Task,Value,Unit
+	if _, ok := UpdateSummary(Esrally, "bench-a", "rally-run", `Metric,Task,Value,Unit
 Service Time,default,N/A,ms
-Warnings,default,,count`) {
+Warnings,default,,count`); ok {
 		t.Fatal("expected no update for csv without numeric rows")
 	}
 }
@@ -234,7 +234,7 @@ func TestScrapeEsrallyStopsWhenDoneFileExistsWithoutMetrics(t *testing.T) {
 	}
 
 	start := time.Now()
-	ScrapeEsrally(reportFile, doneFile, "bench-a", "rally-run")
+	Scrape(Esrally, reportFile, "bench-a", "rally-run", doneFile, make(chan struct{}, 1))
 	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
 		t.Fatalf("expected done-file scrape to return without waiting for ticker, took %s", elapsed)
 	}
@@ -251,7 +251,7 @@ func TestScrapeEsrallyUpdatesMetricsFromExistingReport(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	ScrapeEsrally(reportFile, "", "bench-a", "rally-run")
+	Scrape(Esrally, reportFile, "bench-a", "rally-run", "", make(chan struct{}, 1))
 
 	gauge := EsrallyGaugeMap[EsrallyMetricValueName].WithLabelValues("bench-a", "rally-run", "Mean Throughput", "index-append", "docs/s")
 	if got := testutil.ToFloat64(gauge); got != 99.9 {
@@ -259,8 +259,94 @@ func TestScrapeEsrallyUpdatesMetricsFromExistingReport(t *testing.T) {
 	}
 }
 
+func TestParseEsrallyJSON(t *testing.T) {
+	msg, err := os.ReadFile("testdata/esrally.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := ParseEsrallyJSON(string(msg))
+	if len(result) != 39 {
+		t.Fatalf("expected 39 numeric metrics, got %d: %#v", len(result), result)
+	}
+
+	want := map[EsrallyMetric]bool{
+		{Metric: "Max Throughput", Task: "index-append", Unit: "docs/s", Value: 1410}:            true,
+		{Metric: "Mean latency", Task: "index-append", Unit: "ms", Value: 14.5}:                  true,
+		{Metric: "99th percentile latency", Task: "index-append", Unit: "ms", Value: 40.25}:      true,
+		{Metric: "100th percentile service time", Task: "index-append", Unit: "ms", Value: 52.5}: true,
+		{Metric: "50th percentile processing time", Task: "index-append", Unit: "ms", Value: 12}: true,
+		{Metric: "99.9th percentile latency", Task: "search", Unit: "ms", Value: 25.5}:           true,
+		{Metric: "error rate", Task: "search", Unit: "%", Value: 2}:                              true,
+		{Metric: "young_gc_time", Unit: "ms", Value: 3400}:                                       true,
+		{Metric: "store_size", Unit: "bytes", Value: 2147483648}:                                 true,
+	}
+	for _, metric := range result {
+		delete(want, metric)
+	}
+	if len(want) != 0 {
+		t.Fatalf("missing metrics %#v in %#v", want, result)
+	}
+	if result[0].Metric != "Min Throughput" || result[4].Metric != "Mean latency" || result[5].Metric != "50th percentile latency" {
+		t.Fatalf("unexpected order of the metrics: %#v", result[:6])
+	}
+}
+
+func TestParseEsrallyJSONRace(t *testing.T) {
+	msg, err := os.ReadFile("testdata/esrally.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	race := `{"rally-version": "2.12.0", "race-id": "abc", "results": ` + string(msg) + `}`
+	if got := ParseEsrallyJSON(race); len(got) != 39 {
+		t.Fatalf("expected the results of the race, got %d metrics", len(got))
+	}
+
+	logged := "race id: abc\nRally JSON report:\n" + string(msg) + "\n[INFO] SUCCESS (took 95 seconds)\n"
+	if got := ParseEsrallyReport(logged); len(got) != 39 {
+		t.Fatalf("expected the json report of the log, got %d metrics", len(got))
+	}
+
+	summary := SummarizeEsrallyReport(logged, 2)
+	if !strings.HasPrefix(summary, "Min Throughput [index-append]: 1000 docs/s\nMean Throughput [index-append]: 1200.5 docs/s") {
+		t.Fatalf("unexpected summary: %s", summary)
+	}
+}
+
+func TestParseEsrallyReportFallsBackToCSV(t *testing.T) {
+	for _, msg := range []string{
+		"Metric,Task,Value,Unit\nMean Throughput,index-append,42.5,docs/s",
+		"Rally JSON report:\n{not json\nRally CSV report:\nMetric,Task,Value,Unit\nMean Throughput,index-append,42.5,docs/s",
+	} {
+		got := ParseEsrallyReport(msg)
+		if len(got) != 1 || got[0].Metric != "Mean Throughput" || got[0].Value != 42.5 {
+			t.Fatalf("expected the csv report of %q, got %#v", msg, got)
+		}
+	}
+}
+
+func TestScrapeEsrallyUpdatesMetricsFromJSONReport(t *testing.T) {
+	resetEsrallyTestMetrics()
+	msg, err := os.ReadFile("testdata/esrally.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reportFile := t.TempDir() + "/esrally-report.json"
+	if err := os.WriteFile(reportFile, msg, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	Scrape(Esrally, reportFile, "bench-a", "rally-run", "", make(chan struct{}, 1))
+
+	gauge := EsrallyGaugeMap[EsrallyMetricValueName].WithLabelValues("bench-a", "rally-run", "90th percentile service time", "search", "ms")
+	if got := testutil.ToFloat64(gauge); got != 11.5 {
+		t.Fatalf("expected gauge value 11.5, got %f", got)
+	}
+}
+
 func resetEsrallyTestMetrics() {
 	KubebenchCounter = NewCounter(KubebenchTotalName, KubebenchTotalHelp, KubebenchTotalLabels)
 	EsrallyGaugeMap = map[string]*prometheus.GaugeVec{}
-	InitEsrally()
+	initGauges(Esrally)
 }
@@ -0,0 +1,123 @@
+package exporter
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// HistogramRow is a row of the histogram a benchmark reports, Count values
+// fell into the bucket of Value
+type HistogramRow struct {
+	Value float64 `json:"value"`
+	Count uint64  `json:"count"`
+}
+
+// HistogramSample is a histogram of the final report, Buckets maps the upper
+// bounds to the cumulative counts
+type HistogramSample struct {
+	Name    string
+	Labels  []string
+	Count   uint64
+	Sum     float64
+	Buckets map[float64]uint64
+}
+
+// HistogramParser is implemented by the parsers of benchmarks that report a
+// histogram with their final report
+type HistogramParser interface {
+	// Histograms describes the histograms of the parser
+	Histograms() []MetricDesc
+
+	// ParseHistograms parses the histograms of the final report
+	ParseHistograms(output string) []HistogramSample
+}
+
+// NewHistogramSample sorts the rows into the buckets, the sum is estimated
+// from the values of the rows
+func NewHistogramSample(name string, labels []string, buckets []float64, rows []HistogramRow) HistogramSample {
+	sample := HistogramSample{Name: name, Labels: labels, Buckets: make(map[float64]uint64, len(buckets))}
+	for _, row := range rows {
+		sample.Count += row.Count
+		sample.Sum += row.Value * float64(row.Count)
+		for _, bound := range buckets {
+			if row.Value <= bound {
+				sample.Buckets[bound] += row.Count
+			}
+		}
+	}
+	for _, bound := range buckets {
+		if _, ok := sample.Buckets[bound]; !ok {
+			sample.Buckets[bound] = 0
+		}
+	}
+	return sample
+}
+
+// HistogramPercentile returns the value of the row at the percentile of the
+// counts of the rows
+func HistogramPercentile(rows []HistogramRow, percentile float64) float64 {
+	sorted := append([]HistogramRow(nil), rows...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Value < sorted[j].Value
+	})
+
+	var total uint64
+	for _, row := range sorted {
+		total += row.Count
+	}
+	if total == 0 {
+		return 0
+	}
+
+	rank := uint64(math.Ceil(float64(total) * percentile / 100))
+	var count uint64
+	for _, row := range sorted {
+		count += row.Count
+		if count >= rank {
+			return row.Value
+		}
+	}
+	return sorted[len(sorted)-1].Value
+}
+
+// histogramCollector exports the histograms of the final reports, the
+// benchmark reports them already bucketed so they are constant metrics
+type histogramCollector struct {
+	desc *prometheus.Desc
+
+	mu      sync.Mutex
+	samples map[string]HistogramSample
+}
+
+func newHistogramCollector(desc MetricDesc) *histogramCollector {
+	labels := append([]string{"benchmark", "name"}, desc.Labels...)
+	return &histogramCollector{
+		desc:    prometheus.NewDesc(desc.Name, desc.Help, labels, nil),
+		samples: map[string]HistogramSample{},
+	}
+}
+
+func (c *histogramCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *histogramCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, sample := range c.samples {
+		ch <- prometheus.MustNewConstHistogram(c.desc, sample.Count, sample.Sum, sample.Buckets, strings.Split(key, "\xff")...)
+	}
+}
+
+// set replaces the histogram of the label values
+func (c *histogramCollector) set(labels []string, sample HistogramSample) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.samples[strings.Join(labels, "\xff")] = sample
+}
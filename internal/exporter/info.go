@@ -0,0 +1,50 @@
+package exporter
+
+import (
+	"regexp"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	KubebenchRunInfoName = "kubebench_run_info"
+	KubebenchRunInfoHelp = "The parameters of the run as labels, join it to the metrics of the run on benchmark and name"
+
+	// LabelParamPrefix is the prefix of the params of the labels of the
+	// benchmark
+	LabelParamPrefix = "label_"
+)
+
+var invalidLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// ParamName turns name into a valid label name, e.g. the label
+// app.kubernetes.io/name into app_kubernetes_io_name
+func ParamName(name string) string {
+	name = invalidLabelChars.ReplaceAllString(name, "_")
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+// NewRunInfo returns the info metric of the run, it is always 1 and has the
+// params as labels besides benchmark, name and kind
+func NewRunInfo(benchName, jobName, kind string, params map[string]string) prometheus.Gauge {
+	labels := prometheus.Labels{}
+	for name, value := range params {
+		if name = ParamName(name); name != "" {
+			labels[name] = value
+		}
+	}
+	labels["benchmark"] = benchName
+	labels["name"] = jobName
+	labels["kind"] = kind
+
+	info := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:        KubebenchRunInfoName,
+		Help:        KubebenchRunInfoHelp,
+		ConstLabels: labels,
+	})
+	info.Set(1)
+	return info
+}
@@ -0,0 +1,38 @@
+package exporter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestNewRunInfo(t *testing.T) {
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(NewRunInfo("sb", "sb-run-0", Sysbench, map[string]string{
+		"threads": "8",
+		"type":    "oltp_read_write",
+		LabelParamPrefix + "app.kubernetes.io/name": "sysbench",
+	}))
+
+	expected := `# HELP kubebench_run_info The parameters of the run as labels, join it to the metrics of the run on benchmark and name
+# TYPE kubebench_run_info gauge
+kubebench_run_info{benchmark="sb",kind="sysbench",label_app_kubernetes_io_name="sysbench",name="sb-run-0",threads="8",type="oltp_read_write"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), KubebenchRunInfoName); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParamName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"target_host":                "target_host",
+		"kubebench.apecloud.io/name": "kubebench_apecloud_io_name",
+		"1st":                        "_1st",
+	} {
+		if got := ParamName(name); got != expected {
+			t.Errorf("expected %s for %s, got %s", expected, name, got)
+		}
+	}
+}
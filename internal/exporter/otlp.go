@@ -0,0 +1,185 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+const (
+	OTLPProtocolGRPC = "grpc"
+	OTLPProtocolHTTP = "http"
+)
+
+// OTLPConfig configures pushing the metrics to an OTLP endpoint, Endpoint is
+// host:port without scheme
+type OTLPConfig struct {
+	Endpoint string
+	Protocol string
+	Insecure bool
+	Interval time.Duration
+}
+
+// OTLPResource describes the benchmark the metrics belong to, it becomes the
+// resource of the pushed metrics. The params of the run are added as
+// kubebench.param.<name>.
+type OTLPResource struct {
+	Benchmark string
+	Job       string
+	Kind      string
+	Target    string
+	Params    map[string]string
+}
+
+func (r OTLPResource) attributes() []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.String("service.name", "kubebench-exporter"),
+		attribute.String("kubebench.benchmark", r.Benchmark),
+		attribute.String("kubebench.job", r.Job),
+		attribute.String("kubebench.kind", r.Kind),
+	}
+	if r.Target != "" {
+		attrs = append(attrs, attribute.String("kubebench.target", r.Target))
+	}
+	for name, value := range r.Params {
+		attrs = append(attrs, attribute.String("kubebench.param."+ParamName(name), value))
+	}
+	return attrs
+}
+
+// StartOTLP pushes the kubebench metrics of the gatherer to the endpoint
+// every interval, the returned function pushes them a last time and stops
+func StartOTLP(ctx context.Context, config OTLPConfig, res OTLPResource, gatherer prometheus.Gatherer) (func(context.Context) error, error) {
+	exporter, err := newOTLPExporter(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []sdkmetric.PeriodicReaderOption{sdkmetric.WithProducer(&gathererProducer{gatherer: gatherer, start: time.Now()})}
+	if config.Interval > 0 {
+		options = append(options, sdkmetric.WithInterval(config.Interval))
+	}
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithResource(resource.NewSchemaless(res.attributes()...)),
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, options...)),
+	)
+	return provider.Shutdown, nil
+}
+
+func newOTLPExporter(ctx context.Context, config OTLPConfig) (sdkmetric.Exporter, error) {
+	switch config.Protocol {
+	case OTLPProtocolGRPC, "":
+		options := []otlpmetricgrpc.Option{otlpmetricgrpc.WithEndpoint(config.Endpoint)}
+		if config.Insecure {
+			options = append(options, otlpmetricgrpc.WithInsecure())
+		}
+		return otlpmetricgrpc.New(ctx, options...)
+	case OTLPProtocolHTTP:
+		options := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(config.Endpoint)}
+		if config.Insecure {
+			options = append(options, otlpmetrichttp.WithInsecure())
+		}
+		return otlpmetrichttp.New(ctx, options...)
+	default:
+		return nil, fmt.Errorf("unknown otlp protocol %s", config.Protocol)
+	}
+}
+
+// gathererProducer turns the kubebench metrics of the gatherer into OTLP
+// metrics, the gauges stay gauges, the counters become cumulative sums
+type gathererProducer struct {
+	gatherer prometheus.Gatherer
+	start    time.Time
+}
+
+func (p *gathererProducer) Produce(context.Context) ([]metricdata.ScopeMetrics, error) {
+	families, err := p.gatherer.Gather()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	scope := metricdata.ScopeMetrics{Scope: instrumentation.Scope{Name: "github.com/apecloud/kubebench/internal/exporter"}}
+	for _, family := range families {
+		if !strings.HasPrefix(family.GetName(), "kubebench_") {
+			continue
+		}
+		metric := metricdata.Metrics{Name: family.GetName(), Description: family.GetHelp()}
+		switch family.GetType() {
+		case dto.MetricType_GAUGE:
+			metric.Data = metricdata.Gauge[float64]{DataPoints: p.points(family, now, func(m *dto.Metric) float64 { return m.GetGauge().GetValue() })}
+		case dto.MetricType_COUNTER:
+			metric.Data = metricdata.Sum[float64]{
+				DataPoints:  p.points(family, now, func(m *dto.Metric) float64 { return m.GetCounter().GetValue() }),
+				Temporality: metricdata.CumulativeTemporality,
+				IsMonotonic: true,
+			}
+		case dto.MetricType_HISTOGRAM:
+			metric.Data = metricdata.Histogram[float64]{
+				DataPoints:  p.histogramPoints(family, now),
+				Temporality: metricdata.CumulativeTemporality,
+			}
+		default:
+			continue
+		}
+		scope.Metrics = append(scope.Metrics, metric)
+	}
+	return []metricdata.ScopeMetrics{scope}, nil
+}
+
+func (p *gathererProducer) points(family *dto.MetricFamily, now time.Time, value func(*dto.Metric) float64) []metricdata.DataPoint[float64] {
+	points := make([]metricdata.DataPoint[float64], 0, len(family.GetMetric()))
+	for _, m := range family.GetMetric() {
+		points = append(points, metricdata.DataPoint[float64]{
+			Attributes: labelSet(m),
+			StartTime:  p.start,
+			Time:       now,
+			Value:      value(m),
+		})
+	}
+	return points
+}
+
+// histogramPoints turns the cumulative buckets of prometheus into the counts
+// of the buckets between the bounds, the last count is above all bounds
+func (p *gathererProducer) histogramPoints(family *dto.MetricFamily, now time.Time) []metricdata.HistogramDataPoint[float64] {
+	points := make([]metricdata.HistogramDataPoint[float64], 0, len(family.GetMetric()))
+	for _, m := range family.GetMetric() {
+		histogram := m.GetHistogram()
+		point := metricdata.HistogramDataPoint[float64]{
+			Attributes: labelSet(m),
+			StartTime:  p.start,
+			Time:       now,
+			Count:      histogram.GetSampleCount(),
+			Sum:        histogram.GetSampleSum(),
+		}
+		previous := uint64(0)
+		for _, bucket := range histogram.GetBucket() {
+			point.Bounds = append(point.Bounds, bucket.GetUpperBound())
+			point.BucketCounts = append(point.BucketCounts, bucket.GetCumulativeCount()-previous)
+			previous = bucket.GetCumulativeCount()
+		}
+		point.BucketCounts = append(point.BucketCounts, point.Count-previous)
+		points = append(points, point)
+	}
+	return points
+}
+
+func labelSet(m *dto.Metric) attribute.Set {
+	attrs := make([]attribute.KeyValue, 0, len(m.GetLabel()))
+	for _, label := range m.GetLabel() {
+		attrs = append(attrs, attribute.String(label.GetName(), label.GetValue()))
+	}
+	return attribute.NewSet(attrs...)
+}
@@ -0,0 +1,63 @@
+package exporter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestGathererProducer(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "kubebench_sysbench_tps", Help: "tps"}, []string{"benchmark", "name"})
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "kubebench_total", Help: "total"})
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "kubebench_latency", Help: "latency", Buckets: []float64{1, 10}})
+	other := prometheus.NewGauge(prometheus.GaugeOpts{Name: "go_goroutines_test", Help: "other"})
+	registry.MustRegister(gauge, counter, histogram, other)
+
+	gauge.WithLabelValues("sb", "sb-run-0").Set(563.4)
+	counter.Add(2)
+	for _, value := range []float64{0.5, 5, 5, 50} {
+		histogram.Observe(value)
+	}
+
+	scopes, err := (&gathererProducer{gatherer: registry, start: time.Now()}).Produce(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics := map[string]metricdata.Metrics{}
+	for _, metric := range scopes[0].Metrics {
+		metrics[metric.Name] = metric
+	}
+	if len(metrics) != 3 {
+		t.Fatalf("expected the kubebench metrics only, got %d", len(metrics))
+	}
+
+	points := metrics["kubebench_sysbench_tps"].Data.(metricdata.Gauge[float64]).DataPoints
+	if len(points) != 1 || points[0].Value != 563.4 {
+		t.Fatalf("unexpected gauge points: %+v", points)
+	}
+	if name, _ := points[0].Attributes.Value(attribute.Key("name")); name.AsString() != "sb-run-0" {
+		t.Fatalf("expected the labels as attributes, got %v", points[0].Attributes)
+	}
+
+	sum := metrics["kubebench_total"].Data.(metricdata.Sum[float64])
+	if !sum.IsMonotonic || sum.DataPoints[0].Value != 2 {
+		t.Fatalf("unexpected sum: %+v", sum)
+	}
+
+	point := metrics["kubebench_latency"].Data.(metricdata.Histogram[float64]).DataPoints[0]
+	if point.Count != 4 || len(point.Bounds) != 2 || point.BucketCounts[0] != 1 || point.BucketCounts[1] != 2 || point.BucketCounts[2] != 1 {
+		t.Fatalf("unexpected histogram: %+v", point)
+	}
+}
+
+func TestStartOTLPUnknownProtocol(t *testing.T) {
+	config := OTLPConfig{Endpoint: "localhost:4317", Protocol: "udp"}
+	if _, err := StartOTLP(context.Background(), config, OTLPResource{}, prometheus.NewRegistry()); err == nil {
+		t.Fatal("expected an unknown protocol to fail")
+	}
+}
@@ -0,0 +1,194 @@
+package exporter
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MetricDesc describes a gauge of a benchmark, every gauge is labelled with
+// the benchmark and the job name, followed by its own labels
+type MetricDesc struct {
+	Name   string
+	Help   string
+	Labels []string
+}
+
+// Sample is a value of the gauge Name, Labels are the values of the labels
+// of the gauge after benchmark and name
+type Sample struct {
+	Name   string   `json:"name"`
+	Labels []string `json:"labels,omitempty"`
+	Value  float64  `json:"value"`
+}
+
+// ResultParser parses the output of a kind of benchmark. The exporter turns
+// the samples into metrics, the controller keeps the summary in the status.
+type ResultParser interface {
+	// Metrics describes the gauges of the samples of the parser
+	Metrics() []MetricDesc
+
+	// Streaming reports whether the benchmark streams its output to the
+	// file line by line, otherwise it writes a report once it finished and
+	// the file is read as a whole
+	Streaming() bool
+
+	// ParseInterval parses a line the benchmark reports while it runs, ok
+	// is false if the line is no interval report
+	ParseInterval(line string) (samples []Sample, ok bool)
+
+	// ParseSummary parses the final report of the benchmark, ok is false
+	// until the output holds a complete report
+	ParseSummary(output string) (samples []Sample, ok bool)
+
+	// Summarize returns the part of the output kept in the status of the
+	// benchmark
+	Summarize(output string) string
+}
+
+// ResultFileParser is implemented by the parsers of benchmarks that write
+// result files next to their output, they are parsed once the output holds
+// the final report
+type ResultFileParser interface {
+	// ParseResultFiles parses the result files in dir, the report is
+	// printed for the controller. ok is false if there are no result files.
+	ParseResultFiles(dir string) (samples []Sample, histograms []HistogramSample, report any, ok bool)
+}
+
+type parserEntry struct {
+	parser ResultParser
+	gauges *map[string]*prometheus.GaugeVec
+}
+
+var (
+	parsers = map[string]parserEntry{}
+
+	// histograms holds the collectors of the histograms of all parsers
+	histograms = map[string]*histogramCollector{}
+)
+
+// RegisterParser registers the parser of the kind of benchmark, gauges holds
+// the gauges of its metrics once they are initialized
+func RegisterParser(kind string, parser ResultParser, gauges *map[string]*prometheus.GaugeVec) {
+	if _, ok := parsers[kind]; ok {
+		panic(fmt.Sprintf("parser of %s registered twice", kind))
+	}
+	parsers[kind] = parserEntry{parser: parser, gauges: gauges}
+}
+
+// GetParser returns the parser of the kind of benchmark
+func GetParser(kind string) (ResultParser, bool) {
+	entry, ok := parsers[kind]
+	return entry.parser, ok
+}
+
+// Kinds returns the kinds of benchmark with a parser
+func Kinds() []string {
+	kinds := make([]string, 0, len(parsers))
+	for kind := range parsers {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// SummaryFunc returns the summary function of the parser of the kind, nil
+// if the kind has no parser. The output of the warmup pass is dropped before
+// summarizing, the summary is the one of the measured pass.
+func SummaryFunc(kind string) func(string) string {
+	parser, ok := GetParser(kind)
+	if !ok {
+		return nil
+	}
+	return func(output string) string {
+		return parser.Summarize(stripWarmup(output))
+	}
+}
+
+// initGauges creates the gauges and histograms of the metrics of the kind
+func initGauges(kind string) {
+	entry := parsers[kind]
+	if parser, ok := entry.parser.(HistogramParser); ok {
+		for _, desc := range parser.Histograms() {
+			histograms[desc.Name] = newHistogramCollector(desc)
+		}
+	}
+	if entry.gauges == nil {
+		return
+	}
+	if *entry.gauges == nil {
+		*entry.gauges = map[string]*prometheus.GaugeVec{}
+	}
+	for _, desc := range entry.parser.Metrics() {
+		labels := append([]string{"benchmark", "name"}, desc.Labels...)
+		(*entry.gauges)[desc.Name] = NewGauge(desc.Name, desc.Help, labels)
+	}
+}
+
+// UpdateMetrics sets the gauges of the kind to the samples
+func UpdateMetrics(kind, benchName, jobName string, samples []Sample) {
+	entry, ok := parsers[kind]
+	if !ok || entry.gauges == nil {
+		return
+	}
+
+	CommonCounterInc(benchName, jobName, kind)
+	for _, sample := range samples {
+		gauge, ok := (*entry.gauges)[sample.Name]
+		if !ok {
+			continue
+		}
+		gauge.WithLabelValues(append([]string{benchName, jobName}, sample.Labels...)...).Set(sample.Value)
+	}
+}
+
+// UpdateSummary parses the final report of the benchmark and sets the gauges
+// of the kind to its samples, it returns false if the output holds no
+// complete report
+func UpdateSummary(kind, benchName, jobName, output string) ([]Sample, bool) {
+	parser, ok := GetParser(kind)
+	if !ok {
+		return nil, false
+	}
+	samples, ok := parser.ParseSummary(output)
+	if !ok {
+		return nil, false
+	}
+	UpdateMetrics(kind, benchName, jobName, samples)
+
+	if parser, ok := parser.(HistogramParser); ok {
+		setHistograms(benchName, jobName, parser.ParseHistograms(output))
+	}
+	return samples, true
+}
+
+// UpdateResultFiles parses the result files of the kind in dir and sets the
+// metrics to them, it returns the report of the files or nil if there are
+// none
+func UpdateResultFiles(kind, benchName, jobName, dir string) any {
+	parser, ok := GetParser(kind)
+	if !ok {
+		return nil
+	}
+	fileParser, ok := parser.(ResultFileParser)
+	if !ok {
+		return nil
+	}
+	samples, histograms, report, ok := fileParser.ParseResultFiles(dir)
+	if !ok {
+		return nil
+	}
+	UpdateMetrics(kind, benchName, jobName, samples)
+	setHistograms(benchName, jobName, histograms)
+	return report
+}
+
+// setHistograms sets the histograms of the samples
+func setHistograms(benchName, jobName string, samples []HistogramSample) {
+	for _, sample := range samples {
+		if collector, ok := histograms[sample.Name]; ok {
+			collector.set(append([]string{benchName, jobName}, sample.Labels...), sample)
+		}
+	}
+}
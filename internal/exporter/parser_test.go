@@ -0,0 +1,113 @@
+package exporter
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestParsersParseTestdata(t *testing.T) {
+	testcase := []struct {
+		kind    string
+		path    string
+		samples int
+		summary string
+	}{
+		{kind: Sysbench, path: "testdata/sysbench.txt", samples: 19, summary: "SQL statistics:"},
+		{kind: Sysbench, path: "testdata/sysbench_histogram.txt", samples: 24, summary: "SQL statistics:"},
+		{kind: Pgbench, path: "testdata/pgbench.txt", samples: 11, summary: "transaction type: <builtin: TPC-B (sort of)>"},
+		{kind: Esrally, path: "testdata/esrally.csv", samples: 8, summary: "Min Throughput [index-append]: 1000 docs/s"},
+	}
+
+	for _, tc := range testcase {
+		t.Run(tc.kind, func(t *testing.T) {
+			parser, ok := GetParser(tc.kind)
+			if !ok {
+				t.Fatalf("no parser registered for %s", tc.kind)
+			}
+			msg, err := os.ReadFile(tc.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			samples, ok := parser.ParseSummary(string(msg))
+			if !ok || len(samples) != tc.samples {
+				t.Fatalf("expected %d samples, got %d", tc.samples, len(samples))
+			}
+			described := make(map[string]int)
+			for _, desc := range parser.Metrics() {
+				described[desc.Name] = len(desc.Labels)
+			}
+			for _, sample := range samples {
+				labels, ok := described[sample.Name]
+				if !ok || labels != len(sample.Labels) {
+					t.Fatalf("sample %s does not match the metrics of the parser", sample.Name)
+				}
+			}
+
+			if summary := parser.Summarize(string(msg)); !strings.HasPrefix(summary, tc.summary) {
+				t.Fatalf("unexpected summary: %q", summary)
+			}
+		})
+	}
+}
+
+func TestParsersParseInterval(t *testing.T) {
+	testcase := []struct {
+		kind    string
+		line    string
+		samples int
+	}{
+		{kind: Sysbench, line: "[ 1s ] thds: 4 tps: 563.40 qps: 11319.87 (r/w/o: 7931.50/2257.58/1130.79) lat (ms,99%): 70.55 err/s: 0.00 reconn/s: 0.00", samples: 9},
+		{kind: Pgbench, line: "progress: 1.0 s, 610.0 tps, lat 3.043 ms stddev 8.900, 0 failed", samples: 4},
+		{kind: Sysbench, line: "SQL statistics:"},
+		{kind: Esrally, line: "Mean Throughput,index-append,1200.5,docs/s"},
+	}
+
+	for _, tc := range testcase {
+		parser, _ := GetParser(tc.kind)
+		samples, ok := parser.ParseInterval(tc.line)
+		if ok != (tc.samples > 0) || len(samples) != tc.samples {
+			t.Fatalf("%s: expected %d samples from %q, got %d", tc.kind, tc.samples, tc.line, len(samples))
+		}
+	}
+}
+
+func TestSummaryFunc(t *testing.T) {
+	for _, kind := range []string{Tpcc, Tpch, Ycsb, RedisBench} {
+		if SummaryFunc(kind) == nil {
+			t.Fatalf("expected a summary for %s", kind)
+		}
+	}
+	if SummaryFunc("unknown") != nil {
+		t.Fatal("expected no summary for unknown kinds")
+	}
+
+	summary := SummaryFunc(RedisBench)("SET: rps=0.0\rSET: 84388.19 requests per second\r\nGET: 88339.22 requests per second\r\n")
+	if summary != "SET: 84388.19 requests per second\nGET: 88339.22 requests per second" {
+		t.Fatalf("unexpected redis-benchmark summary: %q", summary)
+	}
+}
+
+func TestUpdateSummaryHistogram(t *testing.T) {
+	initGauges(Sysbench)
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(histograms[SysbenchLatencyHistogramName])
+
+	msg, err := os.ReadFile("testdata/sysbench_histogram.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := UpdateSummary(Sysbench, "bench-a", "sysbench-run-0", string(msg)); !ok {
+		t.Fatal("expected a complete report")
+	}
+	if count := testutil.CollectAndCount(registry, SysbenchLatencyHistogramName); count != 1 {
+		t.Fatalf("expected one histogram, got %d", count)
+	}
+	if value := testutil.ToFloat64(SysbenchGaugeMap[SysbenchLatencyPercentileName].WithLabelValues("bench-a", "sysbench-run-0", "99.9")); value != 184.54 {
+		t.Fatalf("unexpected p99.9 %f", value)
+	}
+}
@@ -4,11 +4,10 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
-	"time"
 
-	"github.com/hpcloud/tail"
 	"github.com/prometheus/client_golang/prometheus"
-	"k8s.io/klog/v2"
+
+	"github.com/apecloud/kubebench/pkg/constants"
 )
 
 var (
@@ -48,6 +47,15 @@ var (
 	// match "tps = 0.000000 (without initial connection time)"
 	tpsRegex = regexp.MustCompile(`tps = (\d+\.\d+) \(without initial connection time\)`)
 
+	// match "SQL script 1: <builtin: TPC-B (sort of)>"
+	scriptRegex = regexp.MustCompile(`^SQL script (\d+): (.*)$`)
+
+	// match " - weight: 2 (targets 66.7% of total)"
+	scriptWeightRegex = regexp.MustCompile(`^ - weight: (\d+)`)
+
+	// match " - 15057 transactions (66.7% of total, tps = 502.898613)"
+	scriptTransactionsRegex = regexp.MustCompile(`^ - (\d+) transactions \(.*tps = (\d+\.\d+)\)`)
+
 	// matc "progress: 1.0 s, 610.0 tps, lat 3.043 ms stddev 8.900, 0 failed"
 	pgbenchSecondRegex = regexp.MustCompile(`progress: (\d+\.\d+) s, (\d+\.\d+) tps, lat (\d+\.\d+) ms stddev (\d+\.\d+), (\d+) failed`)
 )
@@ -97,6 +105,19 @@ const (
 
 	PgbenchTransactionsFailedSecondName = "kubebench_pgbench_transactions_failed_second"
 	PgbenchTransactionsFailedSecondHelp = "The transactions failed of pgbench per second"
+
+	PgbenchScriptWeightName = "kubebench_pgbench_script_weight"
+	PgbenchScriptWeightHelp = "The weight of every pgbench script"
+
+	PgbenchScriptTpsName = "kubebench_pgbench_script_tps"
+	PgbenchScriptTpsHelp = "The tps of every pgbench script"
+
+	PgbenchScriptStdLatencyName = "kubebench_pgbench_script_std_latency"
+	PgbenchScriptStdLatencyHelp = "The std latency of every pgbench script"
+
+	// multipleScriptsType is the transaction type of a run of several
+	// scripts, their statistics follow the report
+	multipleScriptsType = "transaction type: multiple scripts"
 )
 
 var (
@@ -104,31 +125,117 @@ var (
 	PgbenchGaugeMap = map[string]*prometheus.GaugeVec{}
 )
 
-// InitPgbench init the pgbench metrics
-func InitPgbench() {
-	// Init Gauge
-	PgbenchGaugeMap[PgbenchScaleName] = NewGauge(PgbenchScaleName, PgbenchScaleHelp, PgbenchLabels)
-	PgbenchGaugeMap[PgbenchClientsName] = NewGauge(PgbenchClientsName, PgbenchClientsHelp, PgbenchLabels)
-	PgbenchGaugeMap[PgbenchThreadsName] = NewGauge(PgbenchThreadsName, PgbenchThreadsHelp, PgbenchLabels)
-	PgbenchGaugeMap[PgbenchMaximumTryName] = NewGauge(PgbenchMaximumTryName, PgbenchMaximumTryHelp, PgbenchLabels)
-	PgbenchGaugeMap[PgbenchTransactionsPerClientName] = NewGauge(PgbenchTransactionsPerClientName, PgbenchTransactionsPerClientHelp, PgbenchLabels)
-	PgbenchGaugeMap[PgbenchTransactionsProcessedName] = NewGauge(PgbenchTransactionsProcessedName, PgbenchTransactionsProcessedHelp, PgbenchLabels)
-	PgbenchGaugeMap[PgbenchTransactionsFailedName] = NewGauge(PgbenchTransactionsFailedName, PgbenchTransactionsFailedHelp, PgbenchLabels)
-	PgbenchGaugeMap[PgbenchAvgLatencyName] = NewGauge(PgbenchAvgLatencyName, PgbenchAvgLatencyHelp, PgbenchLabels)
-	PgbenchGaugeMap[PgbenchStdLatencyName] = NewGauge(PgbenchStdLatencyName, PgbenchStdLatencyHelp, PgbenchLabels)
-	PgbenchGaugeMap[PgbenchInitialConnectionsTimeName] = NewGauge(PgbenchInitialConnectionsTimeName, PgbenchInitialConnectionsTimeHelp, PgbenchLabels)
-	PgbenchGaugeMap[PgbenchTpsName] = NewGauge(PgbenchTpsName, PgbenchTpsHelp, PgbenchLabels)
-	PgbenchGaugeMap[PgbenchTpsSecondName] = NewGauge(PgbenchTpsSecondName, PgbenchTpsSecondHelp, PgbenchLabels)
-	PgbenchGaugeMap[PgbenchAvgLatencySecondName] = NewGauge(PgbenchAvgLatencySecondName, PgbenchAvgLatencySecondHelp, PgbenchLabels)
-	PgbenchGaugeMap[PgbenchStdLatencySecondName] = NewGauge(PgbenchStdLatencySecondName, PgbenchStdLatencySecondHelp, PgbenchLabels)
-	PgbenchGaugeMap[PgbenchTransactionsFailedSecondName] = NewGauge(PgbenchTransactionsFailedSecondName, PgbenchTransactionsFailedSecondHelp, PgbenchLabels)
+var pgbenchMetrics = []MetricDesc{
+	{Name: PgbenchScaleName, Help: PgbenchScaleHelp},
+	{Name: PgbenchClientsName, Help: PgbenchClientsHelp},
+	{Name: PgbenchThreadsName, Help: PgbenchThreadsHelp},
+	{Name: PgbenchMaximumTryName, Help: PgbenchMaximumTryHelp},
+	{Name: PgbenchTransactionsPerClientName, Help: PgbenchTransactionsPerClientHelp},
+	{Name: PgbenchTransactionsProcessedName, Help: PgbenchTransactionsProcessedHelp},
+	{Name: PgbenchTransactionsFailedName, Help: PgbenchTransactionsFailedHelp},
+	{Name: PgbenchAvgLatencyName, Help: PgbenchAvgLatencyHelp},
+	{Name: PgbenchStdLatencyName, Help: PgbenchStdLatencyHelp},
+	{Name: PgbenchInitialConnectionsTimeName, Help: PgbenchInitialConnectionsTimeHelp},
+	{Name: PgbenchTpsName, Help: PgbenchTpsHelp},
+	{Name: PgbenchTpsSecondName, Help: PgbenchTpsSecondHelp},
+	{Name: PgbenchAvgLatencySecondName, Help: PgbenchAvgLatencySecondHelp},
+	{Name: PgbenchStdLatencySecondName, Help: PgbenchStdLatencySecondHelp},
+	{Name: PgbenchTransactionsFailedSecondName, Help: PgbenchTransactionsFailedSecondHelp},
+	{Name: PgbenchScriptWeightName, Help: PgbenchScriptWeightHelp, Labels: []string{"script"}},
+	{Name: PgbenchScriptTpsName, Help: PgbenchScriptTpsHelp, Labels: []string{"script"}},
+	{Name: PgbenchScriptStdLatencyName, Help: PgbenchScriptStdLatencyHelp, Labels: []string{"script"}},
+}
+
+func init() {
+	RegisterParser(Pgbench, pgbenchParser{}, &PgbenchGaugeMap)
+}
+
+// pgbenchParser parses the progress pgbench reports every interval and the
+// report it prints when it finished
+type pgbenchParser struct{}
+
+func (pgbenchParser) Metrics() []MetricDesc {
+	return append(pgbenchMetrics, pgbenchLogMetrics...)
+}
+
+func (pgbenchParser) Histograms() []MetricDesc {
+	return []MetricDesc{
+		{Name: PgbenchIntervalLatencyHistogramName, Help: PgbenchIntervalLatencyHistogramHelp, Labels: []string{"interval"}},
+	}
+}
+
+// ParseHistograms returns no histograms, the histograms of the intervals are
+// parsed from the transaction logs
+func (pgbenchParser) ParseHistograms(string) []HistogramSample {
+	return nil
+}
+
+// ParseResultFiles analyzes the transaction logs pgbench writes with --log
+func (pgbenchParser) ParseResultFiles(dir string) ([]Sample, []HistogramSample, any, bool) {
+	result, err := ParsePgbenchLogs(dir)
+	if err != nil || result == nil {
+		return nil, nil, nil, false
+	}
+	samples, histograms := pgbenchLogSamples(result)
+	return samples, histograms, result, true
+}
+
+func (pgbenchParser) Streaming() bool {
+	return true
+}
+
+func (pgbenchParser) ParseInterval(line string) ([]Sample, bool) {
+	if !pgbenchSecondRegex.MatchString(line) {
+		return nil, false
+	}
+	result := ParsePgbenchSecondResult(line)
+	return []Sample{
+		{Name: PgbenchTpsSecondName, Value: result.TPS},
+		{Name: PgbenchAvgLatencySecondName, Value: result.AvgLatency},
+		{Name: PgbenchStdLatencySecondName, Value: result.StdLatency},
+		{Name: PgbenchTransactionsFailedSecondName, Value: float64(result.FailedTransactionsSum)},
+	}, true
 }
 
-// RegisterPgbenchMetrics registers the pgbench metrics
-func RegisterPgbenchMetrics() {
-	for _, gague := range PgbenchGaugeMap {
-		prometheus.MustRegister(gague)
+func (pgbenchParser) ParseSummary(output string) ([]Sample, bool) {
+	// the tps is the last line of the report, the statistics of several
+	// scripts follow until the end of the run
+	if !tpsRegex.MatchString(output) {
+		return nil, false
+	}
+	if strings.Contains(output, multipleScriptsType) && !strings.Contains(output, constants.RunEndMarker) {
+		return nil, false
+	}
+	result := ParsePgbenchResult(output)
+	samples := []Sample{
+		{Name: PgbenchScaleName, Value: float64(result.Scale)},
+		{Name: PgbenchClientsName, Value: float64(result.Clients)},
+		{Name: PgbenchThreadsName, Value: float64(result.Threads)},
+		{Name: PgbenchMaximumTryName, Value: float64(result.MaximumTry)},
+		{Name: PgbenchTransactionsPerClientName, Value: float64(result.TransactionsPerClient)},
+		{Name: PgbenchTransactionsProcessedName, Value: float64(result.TransactionsProcessed)},
+		{Name: PgbenchTransactionsFailedName, Value: float64(result.TransactionsFailed)},
+		{Name: PgbenchAvgLatencyName, Value: result.AvgLatency},
+		{Name: PgbenchStdLatencyName, Value: result.StdLatency},
+		{Name: PgbenchInitialConnectionsTimeName, Value: result.InitialConnectionsTime},
+		{Name: PgbenchTpsName, Value: result.TPS},
+	}
+	for _, script := range result.Scripts {
+		labels := []string{strconv.Itoa(script.Script)}
+		samples = append(samples,
+			Sample{Name: PgbenchScriptWeightName, Labels: labels, Value: float64(script.Weight)},
+			Sample{Name: PgbenchScriptTransactionsName, Labels: labels, Value: float64(script.Transactions)},
+			Sample{Name: PgbenchScriptFailedName, Labels: labels, Value: float64(script.Failed)},
+			Sample{Name: PgbenchScriptTpsName, Labels: labels, Value: script.TPS},
+			Sample{Name: PgbenchScriptAvgLatencyName, Labels: labels, Value: script.AvgLatency},
+			Sample{Name: PgbenchScriptStdLatencyName, Labels: labels, Value: script.StdLatency},
+		)
 	}
+	return samples, true
+}
+
+func (pgbenchParser) Summarize(output string) string {
+	return summarizeFrom(strings.ReplaceAll(output, constants.RunEndMarker, ""), "transaction type")
 }
 
 type PgbenchResult struct {
@@ -144,6 +251,22 @@ type PgbenchResult struct {
 	StdLatency             float64 `json:"stdLatency"`
 	InitialConnectionsTime float64 `json:"initialConnectionsTime"`
 	TPS                    float64 `json:"tps"`
+
+	// Scripts are reported if pgbench ran several scripts
+	Scripts []PgbenchScriptSummary `json:"scripts,omitempty"`
+}
+
+// PgbenchScriptSummary are the statistics pgbench reports for every script
+// when it ran several. Script counts from 0 like in the transaction log.
+type PgbenchScriptSummary struct {
+	Script       int     `json:"script"`
+	Name         string  `json:"name"`
+	Weight       int     `json:"weight"`
+	Transactions int     `json:"transactions"`
+	Failed       int     `json:"failed"`
+	TPS          float64 `json:"tps"`
+	AvgLatency   float64 `json:"avgLatency"`
+	StdLatency   float64 `json:"stdLatency"`
 }
 
 type PgbenchSecondResult struct {
@@ -158,6 +281,17 @@ func ParsePgbenchResult(msg string) *PgbenchResult {
 	lines := strings.Split(msg, "\n")
 
 	for _, l := range lines {
+		if match := scriptRegex.FindStringSubmatch(l); match != nil {
+			number, _ := strconv.Atoi(match[1])
+			result.Scripts = append(result.Scripts, PgbenchScriptSummary{Script: number - 1, Name: match[2]})
+			continue
+		}
+		// the lines of a script look like the ones of the report
+		if len(result.Scripts) > 0 {
+			parsePgbenchScriptLine(&result.Scripts[len(result.Scripts)-1], l)
+			continue
+		}
+
 		switch {
 		case scaleRegex.MatchString(l):
 			scale := strings.TrimSpace(strings.Split(l, ":")[1])
@@ -204,13 +338,30 @@ func ParsePgbenchResult(msg string) *PgbenchResult {
 	}
 
 	// if use pgbench -T, we need to calculate the transactions per client
-	if result.TransactionsPerClient == 0 {
+	if result.TransactionsPerClient == 0 && result.Clients > 0 {
 		result.TransactionsPerClient = result.TransactionsProcessed / result.Clients
 	}
 
 	return result
 }
 
+func parsePgbenchScriptLine(script *PgbenchScriptSummary, l string) {
+	switch {
+	case scriptWeightRegex.MatchString(l):
+		script.Weight, _ = strconv.Atoi(scriptWeightRegex.FindStringSubmatch(l)[1])
+	case scriptTransactionsRegex.MatchString(l):
+		match := scriptTransactionsRegex.FindStringSubmatch(l)
+		script.Transactions, _ = strconv.Atoi(match[1])
+		script.TPS, _ = strconv.ParseFloat(match[2], 64)
+	case transactionsFailedRegex.MatchString(l):
+		script.Failed, _ = strconv.Atoi(transactionsFailedRegex.FindStringSubmatch(l)[1])
+	case avgLatencyRegex.MatchString(l):
+		script.AvgLatency, _ = strconv.ParseFloat(avgLatencyRegex.FindStringSubmatch(l)[1], 64)
+	case stdLatencyRegex.MatchString(l):
+		script.StdLatency, _ = strconv.ParseFloat(stdLatencyRegex.FindStringSubmatch(l)[1], 64)
+	}
+}
+
 func ParsePgbenchSecondResult(msg string) *PgbenchSecondResult {
 	// parse string like "progress: 1.0 s, 610.0 tps, lat 3.043 ms stddev 8.900, 0 failed"
 	result := new(PgbenchSecondResult)
@@ -237,71 +388,3 @@ func ParsePgbenchSecondResult(msg string) *PgbenchSecondResult {
 
 	return result
 }
-
-func ScrapPgbench(file, benchName, jobName string) {
-	// read the file
-	klog.Infof("read file %s", file)
-	t, _ := tail.TailFile(file, tail.Config{Follow: true})
-
-	timer := time.NewTicker(30 * time.Second)
-	defer timer.Stop()
-
-	msg := ""
-	for {
-		select {
-		case line := <-t.Lines:
-			klog.Info("ScrapPgbench: ", line.Text)
-			timer.Reset(30 * time.Second)
-			switch {
-			case pgbenchSecondRegex.MatchString(line.Text):
-				// this represents the output of pgbench per second
-				msg = line.Text
-				UpdatePgbenchMetricsSecond(benchName, jobName, msg)
-			case tpsRegex.MatchString(line.Text):
-				// this means the pgbench is finished
-				msg += line.Text
-				UpdatePgbenchMetrics(benchName, jobName, msg)
-				return
-			default:
-				msg += line.Text + "\n"
-			}
-		case <-timer.C:
-			// don't receive any message in 30s, we think the pgbench is finished
-			return
-		}
-	}
-}
-
-func UpdatePgbenchMetrics(benchName, jobName, msg string) {
-	values := []string{benchName, jobName}
-	result := ParsePgbenchResult(msg)
-
-	CommonCounterInc(benchName, jobName, Pgbench)
-
-	// update total metrics
-	PgbenchGaugeMap[PgbenchScaleName].WithLabelValues(values...).Set(float64(result.Scale))
-	PgbenchGaugeMap[PgbenchClientsName].WithLabelValues(values...).Set(float64(result.Clients))
-	PgbenchGaugeMap[PgbenchThreadsName].WithLabelValues(values...).Set(float64(result.Threads))
-	PgbenchGaugeMap[PgbenchMaximumTryName].WithLabelValues(values...).Set(float64(result.MaximumTry))
-	PgbenchGaugeMap[PgbenchTransactionsPerClientName].WithLabelValues(values...).Set(float64(result.TransactionsPerClient))
-	PgbenchGaugeMap[PgbenchTransactionsProcessedName].WithLabelValues(values...).Set(float64(result.TransactionsProcessed))
-	PgbenchGaugeMap[PgbenchTransactionsFailedName].WithLabelValues(values...).Set(float64(result.TransactionsFailed))
-	PgbenchGaugeMap[PgbenchAvgLatencyName].WithLabelValues(values...).Set(result.AvgLatency)
-	PgbenchGaugeMap[PgbenchStdLatencyName].WithLabelValues(values...).Set(result.StdLatency)
-	PgbenchGaugeMap[PgbenchInitialConnectionsTimeName].WithLabelValues(values...).Set(result.InitialConnectionsTime)
-	PgbenchGaugeMap[PgbenchTpsName].WithLabelValues(values...).Set(result.TPS)
-	klog.Info("UpdatePgbenchTotalMetrics result")
-}
-
-func UpdatePgbenchMetricsSecond(benchName, jobName, msg string) {
-	values := []string{benchName, jobName}
-	secondResult := ParsePgbenchSecondResult(msg)
-	CommonCounterInc(benchName, jobName, Pgbench)
-
-	PgbenchGaugeMap[PgbenchTpsSecondName].WithLabelValues(values...).Set(secondResult.TPS)
-	PgbenchGaugeMap[PgbenchAvgLatencySecondName].WithLabelValues(values...).Set(secondResult.AvgLatency)
-	PgbenchGaugeMap[PgbenchStdLatencySecondName].WithLabelValues(values...).Set(secondResult.StdLatency)
-	PgbenchGaugeMap[PgbenchTransactionsFailedSecondName].WithLabelValues(values...).Set(float64(secondResult.FailedTransactionsSum))
-
-	klog.Info("update pgbench second metrics")
-}
@@ -0,0 +1,383 @@
+package exporter
+
+import (
+	"bufio"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+const (
+	PgbenchLatencyPercentileName = "kubebench_pgbench_latency_percentile"
+	PgbenchLatencyPercentileHelp = "The latency percentile of the pgbench transaction log"
+
+	PgbenchMaxLatencyName = "kubebench_pgbench_max_latency"
+	PgbenchMaxLatencyHelp = "The max latency of the pgbench transaction log"
+
+	PgbenchScriptTransactionsName = "kubebench_pgbench_script_transactions"
+	PgbenchScriptTransactionsHelp = "The transactions of every pgbench script"
+
+	PgbenchScriptFailedName = "kubebench_pgbench_script_failed"
+	PgbenchScriptFailedHelp = "The failed transactions of every pgbench script"
+
+	PgbenchScriptAvgLatencyName = "kubebench_pgbench_script_avg_latency"
+	PgbenchScriptAvgLatencyHelp = "The avg latency of every pgbench script"
+
+	PgbenchScriptP95LatencyName = "kubebench_pgbench_script_p95_latency"
+	PgbenchScriptP95LatencyHelp = "The 95th percentile latency of every pgbench script"
+
+	PgbenchScriptMaxLatencyName = "kubebench_pgbench_script_max_latency"
+	PgbenchScriptMaxLatencyHelp = "The max latency of every pgbench script"
+
+	PgbenchIntervalTransactionsName = "kubebench_pgbench_interval_transactions"
+	PgbenchIntervalTransactionsHelp = "The transactions of pgbench in every interval of the transaction log"
+
+	PgbenchIntervalAvgLatencyName = "kubebench_pgbench_interval_avg_latency"
+	PgbenchIntervalAvgLatencyHelp = "The avg latency of pgbench in every interval of the transaction log"
+
+	PgbenchIntervalMaxLatencyName = "kubebench_pgbench_interval_max_latency"
+	PgbenchIntervalMaxLatencyHelp = "The max latency of pgbench in every interval of the transaction log"
+
+	PgbenchIntervalLatencyHistogramName = "kubebench_pgbench_interval_latency_histogram"
+	PgbenchIntervalLatencyHistogramHelp = "The latency histogram in milliseconds of pgbench in every interval of the transaction log"
+
+	// PgbenchLogInterval is the interval in seconds the transactions of the
+	// per transaction log are grouped by
+	PgbenchLogInterval = 10
+)
+
+var (
+	// PgbenchPercentiles are the percentiles computed from the transaction log
+	PgbenchPercentiles = []float64{50, 95, 99}
+
+	// PgbenchLatencyBuckets are the upper bounds in milliseconds of the
+	// histogram of every interval
+	PgbenchLatencyBuckets = prometheus.ExponentialBuckets(0.1, 2, 20)
+)
+
+var pgbenchLogMetrics = []MetricDesc{
+	{Name: PgbenchLatencyPercentileName, Help: PgbenchLatencyPercentileHelp, Labels: []string{"percentile"}},
+	{Name: PgbenchMaxLatencyName, Help: PgbenchMaxLatencyHelp},
+	{Name: PgbenchScriptTransactionsName, Help: PgbenchScriptTransactionsHelp, Labels: []string{"script"}},
+	{Name: PgbenchScriptFailedName, Help: PgbenchScriptFailedHelp, Labels: []string{"script"}},
+	{Name: PgbenchScriptAvgLatencyName, Help: PgbenchScriptAvgLatencyHelp, Labels: []string{"script"}},
+	{Name: PgbenchScriptP95LatencyName, Help: PgbenchScriptP95LatencyHelp, Labels: []string{"script"}},
+	{Name: PgbenchScriptMaxLatencyName, Help: PgbenchScriptMaxLatencyHelp, Labels: []string{"script"}},
+	{Name: PgbenchIntervalTransactionsName, Help: PgbenchIntervalTransactionsHelp, Labels: []string{"interval"}},
+	{Name: PgbenchIntervalAvgLatencyName, Help: PgbenchIntervalAvgLatencyHelp, Labels: []string{"interval"}},
+	{Name: PgbenchIntervalMaxLatencyName, Help: PgbenchIntervalMaxLatencyHelp, Labels: []string{"interval"}},
+}
+
+// PgbenchLogResult is the analysis of the transaction logs of a run, the
+// latencies are in milliseconds
+type PgbenchLogResult struct {
+	// Aggregated is true for logs written with --aggregate-interval, they
+	// hold no percentiles and no scripts
+	Aggregated   bool                  `json:"aggregated,omitempty"`
+	Transactions int64                 `json:"transactions"`
+	Failed       int64                 `json:"failed,omitempty"`
+	Skipped      int64                 `json:"skipped,omitempty"`
+	Percentiles  []float64             `json:"percentiles,omitempty"`
+	Max          float64               `json:"max"`
+	Scripts      []PgbenchScriptResult `json:"scripts,omitempty"`
+
+	// Intervals are left out of the report, they are only exported as
+	// metrics
+	Intervals []PgbenchIntervalResult `json:"-"`
+}
+
+// PgbenchScriptResult are the statistics of the transactions of a script
+type PgbenchScriptResult struct {
+	Script       int     `json:"script"`
+	Transactions int64   `json:"transactions"`
+	Failed       int64   `json:"failed,omitempty"`
+	Avg          float64 `json:"avg"`
+	P95          float64 `json:"p95"`
+	Max          float64 `json:"max"`
+}
+
+// PgbenchIntervalResult are the statistics of the transactions of an
+// interval, Start is the offset in seconds from the first interval
+type PgbenchIntervalResult struct {
+	Start        int64
+	Transactions int64
+	Avg          float64
+	Max          float64
+	Histogram    []HistogramRow
+}
+
+// latencyRows counts the latencies rounded to three significant digits,
+// the percentiles stay precise without keeping every transaction
+type latencyRows map[float64]uint64
+
+func (r latencyRows) add(latency float64) {
+	if latency > 0 {
+		scale := math.Pow(10, 3-math.Ceil(math.Log10(latency)))
+		latency = math.Round(latency*scale) / scale
+	}
+	r[latency]++
+}
+
+func (r latencyRows) rows() []HistogramRow {
+	rows := make([]HistogramRow, 0, len(r))
+	for value, count := range r {
+		rows = append(rows, HistogramRow{Value: value, Count: count})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].Value < rows[j].Value
+	})
+	return rows
+}
+
+// latencyStats sums up the latencies of a group of transactions
+type latencyStats struct {
+	transactions int64
+	failed       int64
+	sum          float64
+	max          float64
+	latencies    latencyRows
+}
+
+func newLatencyStats() *latencyStats {
+	return &latencyStats{latencies: latencyRows{}}
+}
+
+func (s *latencyStats) add(latency float64) {
+	s.transactions++
+	s.sum += latency
+	s.max = math.Max(s.max, latency)
+	s.latencies.add(latency)
+}
+
+func (s *latencyStats) avg() float64 {
+	if s.transactions == 0 {
+		return 0
+	}
+	return s.sum / float64(s.transactions)
+}
+
+// ParsePgbenchLogs analyzes the transaction logs pgbench wrote to dir, the
+// per transaction logs are preferred over the aggregated ones. It returns
+// nil if there are no logs.
+func ParsePgbenchLogs(dir string) (*PgbenchLogResult, error) {
+	files, _ := filepath.Glob(filepath.Join(dir, constants.PgbenchLogPrefix+".*"))
+	if len(files) > 0 {
+		return parsePgbenchTransactionLogs(files)
+	}
+	files, _ = filepath.Glob(filepath.Join(dir, constants.PgbenchAggregateLogPrefix+".*"))
+	if len(files) > 0 {
+		return parsePgbenchAggregateLogs(files)
+	}
+	return nil, nil
+}
+
+// parsePgbenchTransactionLogs parses the lines of the per transaction logs
+// "client_id transaction_no time script_no time_epoch time_us [...]", time
+// is the latency in microseconds or skipped or failed
+func parsePgbenchTransactionLogs(files []string) (*PgbenchLogResult, error) {
+	result := &PgbenchLogResult{}
+	total := newLatencyStats()
+	scripts := map[int]*latencyStats{}
+	intervals := map[int64]*latencyStats{}
+	start := int64(math.MaxInt64)
+
+	err := readLogLines(files, func(fields []string) {
+		if len(fields) < 6 {
+			return
+		}
+		script, err := strconv.Atoi(fields[3])
+		if err != nil {
+			return
+		}
+		epoch, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return
+		}
+		if scripts[script] == nil {
+			scripts[script] = newLatencyStats()
+		}
+
+		switch fields[2] {
+		case "skipped":
+			result.Skipped++
+			return
+		case "failed":
+			result.Failed++
+			scripts[script].failed++
+			return
+		}
+		us, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			return
+		}
+		latency := us / 1000
+
+		total.add(latency)
+		scripts[script].add(latency)
+		interval := epoch / PgbenchLogInterval * PgbenchLogInterval
+		if intervals[interval] == nil {
+			intervals[interval] = newLatencyStats()
+		}
+		intervals[interval].add(latency)
+		if interval < start {
+			start = interval
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Transactions = total.transactions + result.Failed + result.Skipped
+	result.Max = total.max
+	rows := total.latencies.rows()
+	for _, percentile := range PgbenchPercentiles {
+		result.Percentiles = append(result.Percentiles, HistogramPercentile(rows, percentile))
+	}
+	for script, stats := range scripts {
+		result.Scripts = append(result.Scripts, PgbenchScriptResult{
+			Script:       script,
+			Transactions: stats.transactions + stats.failed,
+			Failed:       stats.failed,
+			Avg:          stats.avg(),
+			P95:          HistogramPercentile(stats.latencies.rows(), 95),
+			Max:          stats.max,
+		})
+	}
+	sort.Slice(result.Scripts, func(i, j int) bool {
+		return result.Scripts[i].Script < result.Scripts[j].Script
+	})
+	for interval, stats := range intervals {
+		result.Intervals = append(result.Intervals, PgbenchIntervalResult{
+			Start:        interval - start,
+			Transactions: stats.transactions,
+			Avg:          stats.avg(),
+			Max:          stats.max,
+			Histogram:    stats.latencies.rows(),
+		})
+	}
+	sortIntervals(result.Intervals)
+	return result, nil
+}
+
+// parsePgbenchAggregateLogs parses the lines of the aggregated logs
+// "interval_start num_transactions sum_latency sum_latency_2 min_latency
+// max_latency [failures ...]", the latencies are in microseconds
+func parsePgbenchAggregateLogs(files []string) (*PgbenchLogResult, error) {
+	result := &PgbenchLogResult{Aggregated: true}
+	intervals := map[int64]*PgbenchIntervalResult{}
+	sums := map[int64]float64{}
+	start := int64(math.MaxInt64)
+
+	err := readLogLines(files, func(fields []string) {
+		if len(fields) < 6 {
+			return
+		}
+		values := make([]float64, 7)
+		for i := 0; i < len(values) && i < len(fields); i++ {
+			value, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return
+			}
+			values[i] = value
+		}
+
+		// every thread writes its own log, the lines of an interval add up
+		interval := int64(values[0])
+		if intervals[interval] == nil {
+			intervals[interval] = &PgbenchIntervalResult{Start: interval}
+		}
+		stats := intervals[interval]
+		stats.Transactions += int64(values[1])
+		stats.Max = math.Max(stats.Max, values[5]/1000)
+		sums[interval] += values[2] / 1000
+		result.Transactions += int64(values[1])
+		result.Failed += int64(values[6])
+		result.Max = math.Max(result.Max, values[5]/1000)
+		if interval < start {
+			start = interval
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for interval, stats := range intervals {
+		if stats.Transactions > 0 {
+			stats.Avg = sums[interval] / float64(stats.Transactions)
+		}
+		stats.Start -= start
+		result.Intervals = append(result.Intervals, *stats)
+	}
+	sortIntervals(result.Intervals)
+	return result, nil
+}
+
+// readLogLines calls parse with the fields of every line of the files
+func readLogLines(files []string, parse func(fields []string)) error {
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			parse(strings.Fields(scanner.Text()))
+		}
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortIntervals(intervals []PgbenchIntervalResult) {
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].Start < intervals[j].Start
+	})
+}
+
+// pgbenchLogSamples turns the analysis of the transaction logs into samples
+// and the histograms of the intervals
+func pgbenchLogSamples(result *PgbenchLogResult) ([]Sample, []HistogramSample) {
+	samples := []Sample{{Name: PgbenchMaxLatencyName, Value: result.Max}}
+	for i, value := range result.Percentiles {
+		samples = append(samples, Sample{
+			Name:   PgbenchLatencyPercentileName,
+			Labels: []string{strconv.FormatFloat(PgbenchPercentiles[i], 'f', -1, 64)},
+			Value:  value,
+		})
+	}
+	for _, script := range result.Scripts {
+		labels := []string{strconv.Itoa(script.Script)}
+		samples = append(samples,
+			Sample{Name: PgbenchScriptTransactionsName, Labels: labels, Value: float64(script.Transactions)},
+			Sample{Name: PgbenchScriptFailedName, Labels: labels, Value: float64(script.Failed)},
+			Sample{Name: PgbenchScriptAvgLatencyName, Labels: labels, Value: script.Avg},
+			Sample{Name: PgbenchScriptP95LatencyName, Labels: labels, Value: script.P95},
+			Sample{Name: PgbenchScriptMaxLatencyName, Labels: labels, Value: script.Max},
+		)
+	}
+
+	var histograms []HistogramSample
+	for _, interval := range result.Intervals {
+		labels := []string{strconv.FormatInt(interval.Start, 10)}
+		samples = append(samples,
+			Sample{Name: PgbenchIntervalTransactionsName, Labels: labels, Value: float64(interval.Transactions)},
+			Sample{Name: PgbenchIntervalAvgLatencyName, Labels: labels, Value: interval.Avg},
+			Sample{Name: PgbenchIntervalMaxLatencyName, Labels: labels, Value: interval.Max},
+		)
+		if interval.Histogram != nil {
+			histograms = append(histograms, NewHistogramSample(PgbenchIntervalLatencyHistogramName, labels, PgbenchLatencyBuckets, interval.Histogram))
+		}
+	}
+	return samples, histograms
+}
@@ -0,0 +1,95 @@
+package exporter
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParsePgbenchTransactionLogs(t *testing.T) {
+	result, err := ParsePgbenchLogs("testdata/pgbench_log")
+	if err != nil || result == nil {
+		t.Fatalf("expected the transaction logs to be parsed, got %v", err)
+	}
+
+	if result.Aggregated || result.Transactions != 8 || result.Failed != 1 || result.Skipped != 1 || result.Max != 10 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	expected := []float64{3, 10, 10}
+	for i, value := range result.Percentiles {
+		if value != expected[i] {
+			t.Errorf("expected p%v %f, got %f", PgbenchPercentiles[i], expected[i], value)
+		}
+	}
+
+	scripts := []PgbenchScriptResult{
+		{Script: 0, Transactions: 4, Avg: 3, P95: 5, Max: 5},
+		{Script: 1, Transactions: 3, Failed: 1, Avg: 6.5, P95: 10, Max: 10},
+	}
+	if len(result.Scripts) != len(scripts) {
+		t.Fatalf("expected %d scripts, got %d", len(scripts), len(result.Scripts))
+	}
+	for i, script := range scripts {
+		if result.Scripts[i] != script {
+			t.Errorf("expected script %+v, got %+v", script, result.Scripts[i])
+		}
+	}
+
+	if len(result.Intervals) != 2 {
+		t.Fatalf("expected 2 intervals, got %d", len(result.Intervals))
+	}
+	second := result.Intervals[1]
+	if second.Start != 10 || second.Transactions != 3 || second.Max != 10 || math.Abs(second.Avg-19.0/3) > 1e-9 {
+		t.Errorf("unexpected interval: %+v", second)
+	}
+
+	samples, histograms := pgbenchLogSamples(result)
+	// max, 3 percentiles, 5 for every script and 3 for every interval
+	if len(samples) != 1+3+2*5+2*3 || len(histograms) != 2 {
+		t.Errorf("expected 20 samples and 2 histograms, got %d and %d", len(samples), len(histograms))
+	}
+	if histograms[0].Count != 3 || histograms[0].Labels[0] != "0" {
+		t.Errorf("unexpected histogram of the first interval: %+v", histograms[0])
+	}
+}
+
+func TestParsePgbenchAggregateLogs(t *testing.T) {
+	result, err := ParsePgbenchLogs("testdata/pgbench_agg")
+	if err != nil || result == nil {
+		t.Fatalf("expected the aggregated logs to be parsed, got %v", err)
+	}
+
+	if !result.Aggregated || result.Transactions != 250 || result.Failed != 3 || result.Max != 12 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if result.Percentiles != nil || result.Scripts != nil {
+		t.Fatalf("expected no percentiles and scripts from aggregated logs")
+	}
+	if len(result.Intervals) != 2 {
+		t.Fatalf("expected 2 intervals, got %d", len(result.Intervals))
+	}
+	if first := result.Intervals[0]; first.Start != 0 || first.Transactions != 200 || first.Avg != 2.5 || first.Max != 9 {
+		t.Errorf("unexpected interval: %+v", first)
+	}
+	if second := result.Intervals[1]; second.Start != 5 || second.Transactions != 50 || second.Avg != 3 || second.Max != 12 {
+		t.Errorf("unexpected interval: %+v", second)
+	}
+
+	if _, histograms := pgbenchLogSamples(result); histograms != nil {
+		t.Errorf("expected no histograms from aggregated logs")
+	}
+}
+
+func TestUpdateResultFiles(t *testing.T) {
+	initGauges(Pgbench)
+
+	report, ok := UpdateResultFiles(Pgbench, "bench-a", "pgbench-run-0", "testdata/pgbench_log").(*PgbenchLogResult)
+	if !ok || report.Transactions != 8 {
+		t.Fatalf("expected the report of the transaction logs, got %+v", report)
+	}
+	if UpdateResultFiles(Pgbench, "bench-a", "pgbench-run-0", "testdata") != nil {
+		t.Fatalf("expected no report without transaction logs")
+	}
+	if UpdateResultFiles(Sysbench, "bench-a", "sysbench-run-0", "testdata/pgbench_log") != nil {
+		t.Fatalf("expected no report of kinds without result files")
+	}
+}
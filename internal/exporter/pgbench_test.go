@@ -2,7 +2,10 @@ package exporter
 
 import (
 	"os"
+	"strings"
 	"testing"
+
+	"github.com/apecloud/kubebench/pkg/constants"
 )
 
 func TestParsePgbenchResult(t *testing.T) {
@@ -84,6 +87,62 @@ func TestParsePgbenchResult(t *testing.T) {
 	}
 }
 
+func TestParsePgbenchResultScripts(t *testing.T) {
+	msg, err := os.ReadFile("testdata/pgbench_scripts.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := ParsePgbenchResult(string(msg))
+	if result.AvgLatency != 2.647 || result.StdLatency != 11.912 || result.TPS != 754.431143 {
+		t.Fatalf("the statistics of the scripts changed the report: %+v", result)
+	}
+	expected := []PgbenchScriptSummary{
+		{Script: 0, Name: "<builtin: TPC-B (sort of)>", Weight: 1, Transactions: 7526, TPS: 251.413205, AvgLatency: 5.521, StdLatency: 18.032},
+		{Script: 1, Name: "/etc/kubebench/pgbench/checkout-sql/checkout.sql", Weight: 2, Transactions: 15057, TPS: 503.017938, AvgLatency: 1.211, StdLatency: 6.547},
+	}
+	if len(result.Scripts) != len(expected) {
+		t.Fatalf("expected %d scripts, got %+v", len(expected), result.Scripts)
+	}
+	for i := range expected {
+		if result.Scripts[i] != expected[i] {
+			t.Errorf("expected script %+v, got %+v", expected[i], result.Scripts[i])
+		}
+	}
+}
+
+func TestParsePgbenchSummaryScripts(t *testing.T) {
+	msg, err := os.ReadFile("testdata/pgbench_scripts.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser, _ := GetParser(Pgbench)
+
+	// the statistics of the scripts follow the tps, wait for the end
+	unfinished := strings.Replace(string(msg), constants.RunEndMarker, "", 1)
+	if _, ok := parser.ParseSummary(unfinished); ok {
+		t.Fatal("expected no summary before the end of the run")
+	}
+
+	samples, ok := parser.ParseSummary(string(msg))
+	if !ok || len(samples) != 11+2*6 {
+		t.Fatalf("expected the report and 6 samples for each script, got %d", len(samples))
+	}
+	found := false
+	for _, sample := range samples {
+		if sample.Name == PgbenchScriptTpsName && len(sample.Labels) == 1 && sample.Labels[0] == "1" {
+			found = sample.Value == 503.017938
+		}
+	}
+	if !found {
+		t.Fatalf("expected the tps of the second script: %+v", samples)
+	}
+
+	if summary := parser.Summarize(string(msg)); strings.Contains(summary, constants.RunEndMarker) || !strings.Contains(summary, "SQL script 2") {
+		t.Fatalf("unexpected summary: %s", summary)
+	}
+}
+
 func TestParsePgbenchSecondResult(t *testing.T) {
 	testcase := []struct {
 		input    string
@@ -0,0 +1,188 @@
+package exporter
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+var (
+	// match "scale factor: 1", the run jobs print it ahead of the runner
+	queryScaleRegex = regexp.MustCompile(`^scale factor: (\d+)`)
+
+	// match "run query 1"
+	runQueryRegex = regexp.MustCompile(`^run query (\d+)$`)
+
+	// match "query 1 rows: 4"
+	queryRowsRegex = regexp.MustCompile(`^query (\d+) rows: (\d+)`)
+
+	// match "query 1 run cost 8.513 seconds"
+	queryCostRegex = regexp.MustCompile(`^query (\d+) run cost (\d+(\.\d+)?) seconds`)
+)
+
+var (
+	TpchGaugeMap  = map[string]*prometheus.GaugeVec{}
+	TpcdsGaugeMap = map[string]*prometheus.GaugeVec{}
+)
+
+func init() {
+	RegisterParser(Tpch, queryParser{kind: Tpch, marker: "run tpch query"}, &TpchGaugeMap)
+	RegisterParser(Tpcds, queryParser{kind: Tpcds, marker: "run query"}, &TpcdsGaugeMap)
+}
+
+// QueryResult is the result of a query, the duration is in seconds
+type QueryResult struct {
+	Query    int     `json:"query"`
+	Duration float64 `json:"duration"`
+	Rows     int64   `json:"rows"`
+	Failed   bool    `json:"failed,omitempty"`
+}
+
+// QueryRunResult is the result of a run of the queries of TPC-H or TPC-DS.
+// Power is 3600 times the scale over the geometric mean of the durations of
+// the queries, Throughput the queries per hour times the scale, both of a
+// single stream without the refresh functions.
+type QueryRunResult struct {
+	Scale      int           `json:"scale"`
+	Queries    []QueryResult `json:"queries"`
+	Duration   float64       `json:"duration"`
+	Failed     int           `json:"failed"`
+	Power      float64       `json:"power"`
+	Throughput float64       `json:"throughput"`
+}
+
+// ParseQueryResult parses the queries the runner reports, a query that
+// started but reported no cost failed
+func ParseQueryResult(msg string) *QueryRunResult {
+	result := &QueryRunResult{Scale: 1}
+	queries := map[int]*QueryResult{}
+
+	for _, l := range strings.Split(msg, "\n") {
+		l = strings.TrimSpace(l)
+		switch {
+		case queryScaleRegex.MatchString(l):
+			result.Scale, _ = strconv.Atoi(queryScaleRegex.FindStringSubmatch(l)[1])
+		case runQueryRegex.MatchString(l):
+			query, _ := strconv.Atoi(runQueryRegex.FindStringSubmatch(l)[1])
+			queries[query] = &QueryResult{Query: query, Failed: true}
+		case queryRowsRegex.MatchString(l):
+			match := queryRowsRegex.FindStringSubmatch(l)
+			query, _ := strconv.Atoi(match[1])
+			if queries[query] != nil {
+				queries[query].Rows, _ = strconv.ParseInt(match[2], 10, 64)
+			}
+		case queryCostRegex.MatchString(l):
+			match := queryCostRegex.FindStringSubmatch(l)
+			query, _ := strconv.Atoi(match[1])
+			if queries[query] != nil {
+				queries[query].Duration, _ = strconv.ParseFloat(match[2], 64)
+				queries[query].Failed = false
+			}
+		}
+	}
+
+	logSum := 0.0
+	for _, query := range queries {
+		result.Queries = append(result.Queries, *query)
+		if query.Failed {
+			result.Failed++
+			continue
+		}
+		result.Duration += query.Duration
+		// the queries faster than a millisecond count as a millisecond
+		logSum += math.Log(math.Max(query.Duration, 0.001))
+	}
+	sort.Slice(result.Queries, func(i, j int) bool {
+		return result.Queries[i].Query < result.Queries[j].Query
+	})
+
+	succeeded := len(queries) - result.Failed
+	if succeeded > 0 {
+		result.Power = 3600 * float64(result.Scale) / math.Exp(logSum/float64(succeeded))
+	}
+	if result.Duration > 0 {
+		result.Throughput = float64(succeeded) * 3600 / result.Duration * float64(result.Scale)
+	}
+	return result
+}
+
+// queryParser parses the queries of the runners of TPC-H and TPC-DS once
+// the run job printed the end marker
+type queryParser struct {
+	kind   string
+	marker string
+}
+
+func (p queryParser) name(metric string) string {
+	return fmt.Sprintf("kubebench_%s_%s", p.kind, metric)
+}
+
+func (p queryParser) Metrics() []MetricDesc {
+	return []MetricDesc{
+		{Name: p.name("query_duration_seconds"), Help: "The duration of every query", Labels: []string{"query"}},
+		{Name: p.name("query_rows"), Help: "The rows every query returned", Labels: []string{"query"}},
+		{Name: p.name("query_failed"), Help: "1 if the query failed", Labels: []string{"query"}},
+		{Name: p.name("queries_failed"), Help: "The number of failed queries"},
+		{Name: p.name("duration_seconds"), Help: "The duration of the queries"},
+		{Name: p.name("power"), Help: "The power of the queries of a single stream"},
+		{Name: p.name("throughput"), Help: "The queries per hour times the scale of a single stream"},
+	}
+}
+
+func (queryParser) Streaming() bool {
+	return false
+}
+
+func (queryParser) ParseInterval(string) ([]Sample, bool) {
+	return nil, false
+}
+
+func (p queryParser) ParseSummary(output string) ([]Sample, bool) {
+	if !strings.Contains(output, constants.RunEndMarker) {
+		return nil, false
+	}
+
+	result := ParseQueryResult(output)
+	samples := []Sample{
+		{Name: p.name("queries_failed"), Value: float64(result.Failed)},
+		{Name: p.name("duration_seconds"), Value: result.Duration},
+		{Name: p.name("power"), Value: result.Power},
+		{Name: p.name("throughput"), Value: result.Throughput},
+	}
+	for _, query := range result.Queries {
+		labels := []string{strconv.Itoa(query.Query)}
+		failed := 0.0
+		if query.Failed {
+			failed = 1
+		}
+		samples = append(samples,
+			Sample{Name: p.name("query_duration_seconds"), Labels: labels, Value: query.Duration},
+			Sample{Name: p.name("query_rows"), Labels: labels, Value: float64(query.Rows)},
+			Sample{Name: p.name("query_failed"), Labels: labels, Value: failed},
+		)
+	}
+	return samples, true
+}
+
+func (p queryParser) Summarize(output string) string {
+	return summarizeFrom(output, p.marker)
+}
+
+// ParseResultFiles reports the queries of the log of the kind in dir for
+// the controller, the metrics are set by the summary already
+func (p queryParser) ParseResultFiles(dir string) ([]Sample, []HistogramSample, any, bool) {
+	data, err := os.ReadFile(filepath.Join(dir, p.kind+".log"))
+	if err != nil || !strings.Contains(string(data), constants.RunEndMarker) {
+		return nil, nil, nil, false
+	}
+	return nil, nil, ParseQueryResult(string(data)), true
+}
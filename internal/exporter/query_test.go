@@ -0,0 +1,66 @@
+package exporter
+
+import (
+	"math"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+func TestParseQueryResult(t *testing.T) {
+	msg, err := os.ReadFile("testdata/tpch.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := ParseQueryResult(string(msg))
+	if result.Scale != 2 || result.Failed != 1 || result.Duration != 14 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	queries := []QueryResult{
+		{Query: 1, Duration: 8, Rows: 4},
+		{Query: 2, Duration: 2, Rows: 100},
+		{Query: 3, Failed: true},
+		{Query: 4, Duration: 4, Rows: 10},
+	}
+	if len(result.Queries) != len(queries) {
+		t.Fatalf("expected %d queries, got %d", len(queries), len(result.Queries))
+	}
+	for i, query := range queries {
+		if result.Queries[i] != query {
+			t.Errorf("expected query %+v, got %+v", query, result.Queries[i])
+		}
+	}
+	// the geometric mean of 8, 2 and 4 is 4
+	if math.Abs(result.Power-1800) > 1e-9 {
+		t.Errorf("expected power 1800, got %f", result.Power)
+	}
+	if math.Abs(result.Throughput-3*3600/14.0*2) > 1e-9 {
+		t.Errorf("unexpected throughput %f", result.Throughput)
+	}
+}
+
+func TestQueryParserWaitsForEndMarker(t *testing.T) {
+	msg, err := os.ReadFile("testdata/tpch.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parser, _ := GetParser(Tpch)
+	unfinished := strings.Replace(string(msg), constants.RunEndMarker, "", 1)
+	if _, ok := parser.ParseSummary(unfinished); ok {
+		t.Fatal("expected no summary before the end marker")
+	}
+	samples, ok := parser.ParseSummary(string(msg))
+	// 4 for the run and 3 for every query
+	if !ok || len(samples) != 4+4*3 {
+		t.Fatalf("expected 16 samples, got %d", len(samples))
+	}
+
+	_, _, report, ok := parser.(ResultFileParser).ParseResultFiles("testdata")
+	if result, _ := report.(*QueryRunResult); !ok || result == nil || len(result.Queries) != 4 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+}
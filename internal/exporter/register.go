@@ -1,15 +1,25 @@
 package exporter
 
+import "github.com/prometheus/client_golang/prometheus"
+
+// InitMetrics creates the gauges of the metrics of all parsers.
 func InitMetrics() {
-	InitPgbench()
-	InitSysbench()
-	InitEsrally()
+	for _, kind := range Kinds() {
+		initGauges(kind)
+	}
 }
 
 // Register registers all metrics.
 func Register() {
 	RegisterCommon()
-	RegisterPgbenchMetrics()
-	RegisterSysbenchMetrics()
-	RegisterEsrallyMetrics()
+	for _, kind := range Kinds() {
+		if gauges := parsers[kind].gauges; gauges != nil {
+			for _, gauge := range *gauges {
+				prometheus.MustRegister(gauge)
+			}
+		}
+	}
+	for _, histogram := range histograms {
+		prometheus.MustRegister(histogram)
+	}
 }
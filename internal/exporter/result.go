@@ -0,0 +1,163 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+// MaxTerminationMessageSize is the size kubernetes keeps of the termination
+// message of a container
+const MaxTerminationMessageSize = 4096
+
+var (
+	// resultMu guards the result of the running scrape, the HTTP API reads
+	// it while the scrape updates it
+	resultMu sync.Mutex
+	current  *Result
+)
+
+// Result is the result file the exporter writes when scraping finished, it
+// holds the samples the parser of the kind turned the output into
+type Result struct {
+	Version   int    `json:"version"`
+	Kind      string `json:"kind"`
+	Benchmark string `json:"benchmark"`
+	Job       string `json:"job"`
+
+	// Start is the unix time in seconds scraping started
+	Start int64 `json:"start"`
+
+	// Complete is false if scraping stopped before the final report
+	Complete bool `json:"complete"`
+
+	// Summary holds the samples of the final report
+	Summary []Sample `json:"summary,omitempty"`
+
+	// Report is the report of the result files of the benchmark, see
+	// ResultFileParser
+	Report json.RawMessage `json:"report,omitempty"`
+
+	// Message is the part of the output the controller keeps in the status
+	// of the benchmark, see ResultParser.Summarize
+	Message string `json:"message,omitempty"`
+
+	// Intervals holds the samples of the interval reports in order
+	Intervals []Interval `json:"intervals,omitempty"`
+
+	// Truncated is set in the termination message if the summary, the
+	// report or the message did not fit, the result file holds them
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Interval holds the samples of an interval report, Time is the unix time
+// in seconds the exporter read it
+type Interval struct {
+	Time    int64    `json:"time"`
+	Samples []Sample `json:"samples"`
+}
+
+func newResult(kind, benchName, jobName string) *Result {
+	return &Result{
+		Version:   constants.ResultFileVersion,
+		Kind:      kind,
+		Benchmark: benchName,
+		Job:       jobName,
+		Start:     time.Now().Unix(),
+	}
+}
+
+func (r *Result) addInterval(samples []Sample) {
+	resultMu.Lock()
+	defer resultMu.Unlock()
+	r.Intervals = append(r.Intervals, Interval{Time: time.Now().Unix(), Samples: samples})
+}
+
+func (r *Result) setSummary(samples []Sample) {
+	resultMu.Lock()
+	defer resultMu.Unlock()
+	r.Complete = true
+	r.Summary = samples
+}
+
+func (r *Result) setReport(report any) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	resultMu.Lock()
+	defer resultMu.Unlock()
+	r.Report = data
+	return nil
+}
+
+func (r *Result) setMessage(message string) {
+	resultMu.Lock()
+	defer resultMu.Unlock()
+	r.Message = message
+}
+
+// setCurrent makes the result the one the HTTP API reports
+func setCurrent(result *Result) {
+	resultMu.Lock()
+	defer resultMu.Unlock()
+	current = result
+}
+
+// WriteResult writes the result to the file
+func WriteResult(file string, result *Result) error {
+	resultMu.Lock()
+	data, err := json.Marshal(result)
+	resultMu.Unlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0644)
+}
+
+// TerminationMessage returns the result without the intervals for the
+// termination message, the report, the summary and then the message are
+// dropped if the termination message gets too long
+func TerminationMessage(result *Result) ([]byte, error) {
+	resultMu.Lock()
+	message := *result
+	resultMu.Unlock()
+	message.Intervals = nil
+	data, err := json.Marshal(message)
+	if err != nil || len(data) <= MaxTerminationMessageSize {
+		return data, err
+	}
+
+	message.Truncated = true
+	message.Report = nil
+	data, err = json.Marshal(message)
+	if err != nil || len(data) <= MaxTerminationMessageSize {
+		return data, err
+	}
+
+	message.Summary = nil
+	data, err = json.Marshal(message)
+	if err != nil || len(data) <= MaxTerminationMessageSize {
+		return data, err
+	}
+
+	message.Message = ""
+	return json.Marshal(message)
+}
+
+// ParseResult parses a result file or termination message, it fails for the
+// versions the parser doesn't know
+func ParseResult(data []byte) (*Result, error) {
+	result := &Result{}
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, err
+	}
+	if result.Version < 1 || result.Version > constants.ResultFileVersion {
+		return nil, fmt.Errorf("unsupported result version %d", result.Version)
+	}
+	return result, nil
+}
@@ -0,0 +1,88 @@
+package exporter
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+func TestScrapeWritesResultFile(t *testing.T) {
+	initGauges(Sysbench)
+	file := writeWarmupLog(t, "", "testdata/sysbench.txt")
+
+	if Scrape(Sysbench, file, "sb", "sb-run-0", "", make(chan struct{}, 1)) == nil {
+		t.Fatal("expected a result")
+	}
+
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(file), constants.ResultFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := ParseResult(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Kind != Sysbench || result.Job != "sb-run-0" || !result.Complete || len(result.Summary) == 0 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if len(result.Intervals) == 0 || len(result.Intervals[0].Samples) != 9 {
+		t.Fatalf("expected the interval reports, got %+v", result.Intervals)
+	}
+	if !strings.HasPrefix(result.Message, "SQL statistics") {
+		t.Fatalf("expected the summary as message, got %q", result.Message)
+	}
+}
+
+func TestTerminationMessageTruncates(t *testing.T) {
+	result := newResult(Tpcds, "tpcds", "tpcds-run")
+	result.setSummary([]Sample{{Name: "kubebench_tpcds_power", Value: 1800}})
+	result.addInterval([]Sample{{Name: "kubebench_tpcds_power", Value: 1}})
+	if err := result.setReport(map[string]string{"queries": "short"}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := TerminationMessage(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, err := ParseResult(data)
+	if err != nil || message.Truncated || len(message.Intervals) != 0 || len(message.Report) == 0 {
+		t.Fatalf("expected the whole result without intervals, got %s", data)
+	}
+
+	if err := result.setReport(map[string]string{"queries": strings.Repeat("q", MaxTerminationMessageSize)}); err != nil {
+		t.Fatal(err)
+	}
+	data, err = TerminationMessage(result)
+	if err != nil || len(data) > MaxTerminationMessageSize {
+		t.Fatalf("expected a message that fits, got %d bytes: %v", len(data), err)
+	}
+	if message, _ = ParseResult(data); !message.Truncated || message.Report != nil || len(message.Summary) != 1 {
+		t.Fatalf("expected the report to be dropped, got %s", data)
+	}
+
+	result.setMessage(strings.Repeat("m", MaxTerminationMessageSize))
+	data, err = TerminationMessage(result)
+	if err != nil || len(data) > MaxTerminationMessageSize {
+		t.Fatalf("expected a message that fits, got %d bytes: %v", len(data), err)
+	}
+	if message, _ = ParseResult(data); !message.Truncated || message.Message != "" || message.Summary != nil {
+		t.Fatalf("expected the summary and the message to be dropped, got %s", data)
+	}
+}
+
+func TestParseResultVersion(t *testing.T) {
+	for _, version := range []int{0, constants.ResultFileVersion + 1} {
+		data, _ := json.Marshal(Result{Version: version})
+		if _, err := ParseResult(data); err == nil {
+			t.Errorf("expected version %d to be rejected", version)
+		}
+	}
+	if _, err := ParseResult([]byte("Error: connection refused")); err == nil {
+		t.Error("expected a log to be rejected")
+	}
+}
@@ -2,8 +2,15 @@ package exporter
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/hpcloud/tail"
 	"k8s.io/klog/v2"
+
+	"github.com/apecloud/kubebench/pkg/constants"
 )
 
 const (
@@ -12,24 +19,165 @@ const (
 	Esrally  = "esrally"
 )
 
-// Scrape is a function to scrape benchmark result from log.
-func Scrape(benchType, file, benchName, jobName, doneFile string, ch chan struct{}) {
+// Scrape is a function to scrape benchmark result from log. The result is
+// written to the result file next to the log when scraping finished, it is
+// nil if the type has no parser.
+func Scrape(benchType, file, benchName, jobName, doneFile string, ch chan struct{}) *Result {
 	defer func() {
 		// notify the channel
 		ch <- struct{}{}
 	}()
 
-	switch benchType {
-	case Pgbench:
-		klog.Info("scrape pgbench result")
-		ScrapPgbench(file, benchName, jobName)
-	case Sysbench:
-		klog.Info("scrape sysbench result")
-		ScrapeSysbench(file, benchName, jobName)
-	case Esrally:
-		klog.Info("scrape esrally result")
-		ScrapeEsrally(file, doneFile, benchName, jobName)
-	default:
+	parser, ok := GetParser(benchType)
+	if !ok {
 		fmt.Printf("not support benchmark type: %s\n", benchType)
+		return nil
+	}
+
+	klog.Infof("scrape %s result", benchType)
+	result := newResult(benchType, benchName, jobName)
+	setCurrent(result)
+	if parser.Streaming() {
+		followLog(benchType, parser, file, benchName, jobName, result)
+	} else {
+		pollReport(benchType, file, doneFile, benchName, jobName, result)
+	}
+	if content, err := os.ReadFile(file); err == nil {
+		result.setMessage(SummaryFunc(benchType)(string(content)))
+	}
+
+	resultFile := filepath.Join(filepath.Dir(file), constants.ResultFileName)
+	if err := WriteResult(resultFile, result); err != nil {
+		klog.Errorf("failed to write the result file %s: %v", resultFile, err)
+	}
+	return result
+}
+
+// followLog parses the log line by line, every interval report updates the
+// metrics right away, the lines in between are collected until they hold
+// the final report
+func followLog(kind string, parser ResultParser, file, benchName, jobName string, result *Result) {
+	// read the file
+	klog.Info("read file: ", file)
+	t, _ := tail.TailFile(file, tail.Config{Follow: true})
+
+	timer := time.NewTicker(30 * time.Second)
+	defer timer.Stop()
+
+	msg := ""
+	warmup := &warmupFilter{}
+	for {
+		select {
+		case line := <-t.Lines:
+			klog.Infof("scrape %s: %s", kind, line.Text)
+			timer.Reset(30 * time.Second)
+			if warmup.skip(line.Text) {
+				continue
+			}
+			if samples, ok := parser.ParseInterval(line.Text); ok {
+				msg = ""
+				UpdateMetrics(kind, benchName, jobName, samples)
+				result.addInterval(samples)
+				continue
+			}
+			msg += line.Text + "\n"
+			if samples, ok := parser.ParseSummary(msg); ok {
+				UpdateMetrics(kind, benchName, jobName, samples)
+				klog.Infof("update %s total metrics", kind)
+				result.setSummary(samples)
+				updateResultFiles(kind, benchName, jobName, filepath.Dir(file), result)
+				return
+			}
+		case <-timer.C:
+			// don't receive any message in 30s, we think the test is finished
+			return
+		}
+	}
+}
+
+// pollReport reads the report file every second until it holds a complete
+// report, or the done file tells that no report will come
+func pollReport(kind, file, doneFile, benchName, jobName string, result *Result) {
+	klog.Infof("read %s report file %s", kind, file)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		if content, err := os.ReadFile(file); err == nil && len(content) > 0 {
+			if samples, ok := UpdateSummary(kind, benchName, jobName, string(content)); ok {
+				klog.Infof("update %s metrics", kind)
+				result.setSummary(samples)
+				updateResultFiles(kind, benchName, jobName, filepath.Dir(file), result)
+				return
+			}
+		}
+		if doneFile != "" {
+			if _, err := os.Stat(doneFile); err == nil {
+				klog.Infof("%s done marker found before report metrics were parsed: %s", kind, doneFile)
+				return
+			}
+		}
+		<-ticker.C
+	}
+}
+
+// updateResultFiles updates the metrics of the result files next to the
+// output and adds their report to the result, the report is printed after
+// the marker too in case it doesn't fit the termination message
+func updateResultFiles(kind, benchName, jobName, dir string, result *Result) {
+	report := UpdateResultFiles(kind, benchName, jobName, dir)
+	if report == nil {
+		return
+	}
+	if err := result.setReport(report); err != nil {
+		klog.Errorf("failed to marshal the %s report: %v", kind, err)
+		return
+	}
+	klog.Infof("update %s result file metrics", kind)
+	fmt.Printf("%s %s\n", constants.ResultReportMarker, result.Report)
+}
+
+// DumpFile prints the file between markers, it ends up in the container log
+// and with it in the log archive
+func DumpFile(file string) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		klog.Errorf("failed to read %s: %v", file, err)
+		return
+	}
+	fmt.Printf("==== kubebench file %s ====\n%s\n==== kubebench file end ====\n", file, data)
+}
+
+// warmupFilter drops the output of the warmup pass that the run jobs print
+// between the warmup markers ahead of the measured pass
+type warmupFilter struct {
+	warmup bool
+}
+
+// skip reports whether the line is a marker or belongs to the warmup pass
+func (f *warmupFilter) skip(line string) bool {
+	switch {
+	case strings.Contains(line, constants.WarmupStartMarker):
+		f.warmup = true
+		return true
+	case strings.Contains(line, constants.WarmupEndMarker):
+		f.warmup = false
+		return true
+	default:
+		return f.warmup
+	}
+}
+
+// stripWarmup drops the warmup markers and the output of the warmup pass
+// between them
+func stripWarmup(output string) string {
+	warmup := &warmupFilter{}
+	lines := strings.Split(output, "\n")
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if !warmup.skip(line) {
+			kept = append(kept, line)
+		}
 	}
+	return strings.Join(kept, "\n")
 }
@@ -0,0 +1,94 @@
+package exporter
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	Tpcc       = "tpcc"
+	Tpch       = "tpch"
+	Tpcds      = "tpcds"
+	Ycsb       = "ycsb"
+	RedisBench = "redisbench"
+)
+
+func init() {
+	RegisterParser(Tpcc, summaryParser{summarize: func(output string) string {
+		return summarizeFrom(output, "Measured tpmC (NewOrders)")
+	}}, nil)
+	RegisterParser(Ycsb, summaryParser{summarize: func(output string) string {
+		return summarizeFrom(output, "Run finished, takes")
+	}}, nil)
+	RegisterParser(RedisBench, summaryParser{summarize: summarizeRedisBench}, nil)
+}
+
+// summaryParser only summarizes the output for the status, the exporter has
+// no metrics for the benchmark
+type summaryParser struct {
+	summarize func(string) string
+}
+
+func (summaryParser) Metrics() []MetricDesc {
+	return nil
+}
+
+func (summaryParser) Streaming() bool {
+	return true
+}
+
+func (summaryParser) ParseInterval(string) ([]Sample, bool) {
+	return nil, false
+}
+
+func (summaryParser) ParseSummary(string) ([]Sample, bool) {
+	return nil, false
+}
+
+func (p summaryParser) Summarize(output string) string {
+	return p.summarize(output)
+}
+
+// summarizeFrom returns the output from the first line containing marker,
+// the following lines are aligned to the condition message
+func summarizeFrom(output, marker string) string {
+	result := ""
+	lines := strings.Split(output, "\n")
+	index := len(lines)
+
+	for i, l := range lines {
+		if strings.Contains(l, marker) {
+			index = i
+			result += fmt.Sprintf("%s\n", l)
+			break
+		}
+	}
+
+	for i := index + 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			// align the output
+			result += fmt.Sprintf("%*s\n", len(lines[i])+27, lines[i])
+		}
+	}
+
+	// delete the last \n
+	return strings.TrimSpace(result)
+}
+
+// summarizeRedisBench returns the requests per second of every test,
+// redis-benchmark separates its progress with \r
+func summarizeRedisBench(output string) string {
+	result := ""
+
+	lines := strings.Split(output, "\r")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+
+		// save the result query/sev value
+		if strings.Contains(line, "per second") {
+			result += fmt.Sprintf("%s\n", line)
+		}
+	}
+
+	return strings.TrimSpace(result)
+}
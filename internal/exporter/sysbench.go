@@ -4,11 +4,8 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
-	"time"
 
-	"github.com/hpcloud/tail"
 	"github.com/prometheus/client_golang/prometheus"
-	"k8s.io/klog/v2"
 )
 
 var (
@@ -51,8 +48,8 @@ var (
 	//match "max:                                   91.40"
 	latencyMaxRegex = regexp.MustCompile(`max:\s+(\d+\.\d+)`)
 
-	//match "99th percentile:                       71.83"
-	latencyNinetyNinthRegex = regexp.MustCompile(`99th\s+percentile:\s+(\d+\.\d+)`)
+	//match "99th percentile:                       71.83", the percentile is configurable
+	latencyNinetyNinthRegex = regexp.MustCompile(`\d+th\s+percentile:\s+(\d+\.\d+)`)
 
 	//match "sum:                                39998.33"
 	latencySumRegex = regexp.MustCompile(`sum:\s+(\d+\.\d+)`)
@@ -63,8 +60,11 @@ var (
 	//match "execution time (avg/stddev):   9.9996/0.00"
 	execTimeRegex = regexp.MustCompile(`execution\s+time\s+\(avg/stddev\):\s+(\d+\.\d+)/(\d+\.\d+)`)
 
+	//match "       1.339 |**                                       12"
+	histogramRowRegex = regexp.MustCompile(`^\s*(\d+\.\d+)\s+\|\**\s+(\d+)\s*$`)
+
 	//match "[ 1s ] thds: 4 tps: 563.40 qps: 11319.87 (r/w/o: 7931.50/2257.58/1130.79) lat (ms,99%): 70.55 err/s: 0.00 reconn/s: 0.00"
-	sysbenchSecondRegex = regexp.MustCompile(`\[ \d+s \]\s+thds:\s+(\d+)\s+tps:\s+(\d+\.\d+)\s+qps:\s+(\d+\.\d+)\s+\(r/w/o:\s+(\d+\.\d+)/(\d+\.\d+)/(\d+\.\d+)\)\s+lat\s+\(ms,99%\):\s+(\d+\.\d+)\s+err/s:\s+(\d+\.\d+)\s+reconn/s:\s+(\d+\.\d+)`)
+	sysbenchSecondRegex = regexp.MustCompile(`\[ \d+s \]\s+thds:\s+(\d+)\s+tps:\s+(\d+\.\d+)\s+qps:\s+(\d+\.\d+)\s+\(r/w/o:\s+(\d+\.\d+)/(\d+\.\d+)/(\d+\.\d+)\)\s+lat\s+\(ms,\d+%\):\s+(\d+\.\d+)\s+err/s:\s+(\d+\.\d+)\s+reconn/s:\s+(\d+\.\d+)`)
 )
 
 const (
@@ -151,51 +151,148 @@ const (
 
 	SysbenchReconnectsSecondName = "kubebench_sysbench_reconnects_second"
 	SysbenchReconnectsSecondHelp = "Sysbench reconnects every second"
+
+	SysbenchLatencyPercentileName = "kubebench_sysbench_latency_percentile"
+	SysbenchLatencyPercentileHelp = "Sysbench latency percentile of the histogram"
+
+	SysbenchLatencyHistogramName = "kubebench_sysbench_latency_histogram"
+	SysbenchLatencyHistogramHelp = "Sysbench latency histogram in milliseconds"
 )
 
 var (
 	SysbenchLabels   = []string{"benchmark", "name"}
 	SysbenchGaugeMap = map[string]*prometheus.GaugeVec{}
+
+	// SysbenchPercentiles are the percentiles computed from the histogram
+	SysbenchPercentiles = []float64{50, 90, 95, 99, 99.9}
+
+	// SysbenchLatencyBuckets are the upper bounds in milliseconds of the
+	// histogram, fixed so that the histograms of runs compare
+	SysbenchLatencyBuckets = prometheus.ExponentialBuckets(0.1, 2, 20)
 )
 
-// InitSysbench init sysbench metrics
-func InitSysbench() {
-	// Init Gauge
-	SysbenchGaugeMap[SysbenchQueryReadName] = NewGauge(SysbenchQueryReadName, SysbenchQueryReadHelp, SysbenchLabels)
-	SysbenchGaugeMap[SysbenchQueryWriteName] = NewGauge(SysbenchQueryWriteName, SysbenchQueryWriteHelp, SysbenchLabels)
-	SysbenchGaugeMap[SysbenchQueryOtherName] = NewGauge(SysbenchQueryOtherName, SysbenchQueryOtherHelp, SysbenchLabels)
-	SysbenchGaugeMap[SysbenchQueryTotalName] = NewGauge(SysbenchQueryTotalName, SysbenchQueryTotalHelp, SysbenchLabels)
-	SysbenchGaugeMap[SysbenchTransactionsName] = NewGauge(SysbenchTransactionsName, SysbenchTransactionsHelp, SysbenchLabels)
-	SysbenchGaugeMap[SysbenchQueriesName] = NewGauge(SysbenchQueriesName, SysbenchQueriesHelp, SysbenchLabels)
-	SysbenchGaugeMap[SysbenchIgnoredErrorsName] = NewGauge(SysbenchIgnoredErrorsName, SysbenchIgnoredErrorsHelp, SysbenchLabels)
-	SysbenchGaugeMap[SysbenchReconnectsName] = NewGauge(SysbenchReconnectsName, SysbenchReconnectsHelp, SysbenchLabels)
-	SysbenchGaugeMap[SysbenchTotalEventsName] = NewGauge(SysbenchTotalEventsName, SysbenchTotalEventsHelp, SysbenchLabels)
-	SysbenchGaugeMap[SysbenchTotalTimeName] = NewGauge(SysbenchTotalTimeName, SysbenchTotalTimeHelp, SysbenchLabels)
-	SysbenchGaugeMap[SysbenchLatencyMinName] = NewGauge(SysbenchLatencyMinName, SysbenchLatencyMinHelp, SysbenchLabels)
-	SysbenchGaugeMap[SysbenchLatencyAvgName] = NewGauge(SysbenchLatencyAvgName, SysbenchLatencyAvgHelp, SysbenchLabels)
-	SysbenchGaugeMap[SysbenchLatencyMaxName] = NewGauge(SysbenchLatencyMaxName, SysbenchLatencyMaxHelp, SysbenchLabels)
-	SysbenchGaugeMap[SysbenchLatencyNinetyNinthName] = NewGauge(SysbenchLatencyNinetyNinthName, SysbenchLatencyNinetyNinthHelp, SysbenchLabels)
-	SysbenchGaugeMap[SysbenchLatencySumName] = NewGauge(SysbenchLatencySumName, SysbenchLatencySumHelp, SysbenchLabels)
-	SysbenchGaugeMap[SysbenchEventsAvgName] = NewGauge(SysbenchEventsAvgName, SysbenchEventsAvgHelp, SysbenchLabels)
-	SysbenchGaugeMap[SysbenchEventsStddevName] = NewGauge(SysbenchEventsStddevName, SysbenchEventsStddevHelp, SysbenchLabels)
-	SysbenchGaugeMap[SysbenchExecTimeAvgName] = NewGauge(SysbenchExecTimeAvgName, SysbenchExecTimeAvgHelp, SysbenchLabels)
-	SysbenchGaugeMap[SysbenchExecTimeStddevName] = NewGauge(SysbenchExecTimeStddevName, SysbenchExecTimeStddevHelp, SysbenchLabels)
-	SysbenchGaugeMap[SysbenchThreadsName] = NewGauge(SysbenchThreadsName, SysbenchThreadsHelp, SysbenchLabels)
-	SysbenchGaugeMap[SysbenchTpsSecondName] = NewGauge(SysbenchTpsSecondName, SysbenchTpsSecondHelp, SysbenchLabels)
-	SysbenchGaugeMap[SysbenchQpsSecondName] = NewGauge(SysbenchQpsSecondName, SysbenchQpsSecondHelp, SysbenchLabels)
-	SysbenchGaugeMap[SysbenchReadQpsSecondName] = NewGauge(SysbenchReadQpsSecondName, SysbenchReadQpsSecondHelp, SysbenchLabels)
-	SysbenchGaugeMap[SysbenchWriteQpsSecondName] = NewGauge(SysbenchWriteQpsSecondName, SysbenchWriteQpsSecondHelp, SysbenchLabels)
-	SysbenchGaugeMap[SysbenchOtherQpsSecondName] = NewGauge(SysbenchOtherQpsSecondName, SysbenchOtherQpsSecondHelp, SysbenchLabels)
-	SysbenchGaugeMap[SysbenchLatencySecondName] = NewGauge(SysbenchLatencySecondName, SysbenchLatencySecondHelp, SysbenchLabels)
-	SysbenchGaugeMap[SysbenchErrorsSecondName] = NewGauge(SysbenchErrorsSecondName, SysbenchErrorsSecondHelp, SysbenchLabels)
-	SysbenchGaugeMap[SysbenchReconnectsSecondName] = NewGauge(SysbenchReconnectsSecondName, SysbenchReconnectsSecondHelp, SysbenchLabels)
+var sysbenchMetrics = []MetricDesc{
+	{Name: SysbenchQueryReadName, Help: SysbenchQueryReadHelp},
+	{Name: SysbenchQueryWriteName, Help: SysbenchQueryWriteHelp},
+	{Name: SysbenchQueryOtherName, Help: SysbenchQueryOtherHelp},
+	{Name: SysbenchQueryTotalName, Help: SysbenchQueryTotalHelp},
+	{Name: SysbenchTransactionsName, Help: SysbenchTransactionsHelp},
+	{Name: SysbenchQueriesName, Help: SysbenchQueriesHelp},
+	{Name: SysbenchIgnoredErrorsName, Help: SysbenchIgnoredErrorsHelp},
+	{Name: SysbenchReconnectsName, Help: SysbenchReconnectsHelp},
+	{Name: SysbenchTotalEventsName, Help: SysbenchTotalEventsHelp},
+	{Name: SysbenchTotalTimeName, Help: SysbenchTotalTimeHelp},
+	{Name: SysbenchLatencyMinName, Help: SysbenchLatencyMinHelp},
+	{Name: SysbenchLatencyAvgName, Help: SysbenchLatencyAvgHelp},
+	{Name: SysbenchLatencyMaxName, Help: SysbenchLatencyMaxHelp},
+	{Name: SysbenchLatencyNinetyNinthName, Help: SysbenchLatencyNinetyNinthHelp},
+	{Name: SysbenchLatencySumName, Help: SysbenchLatencySumHelp},
+	{Name: SysbenchEventsAvgName, Help: SysbenchEventsAvgHelp},
+	{Name: SysbenchEventsStddevName, Help: SysbenchEventsStddevHelp},
+	{Name: SysbenchExecTimeAvgName, Help: SysbenchExecTimeAvgHelp},
+	{Name: SysbenchExecTimeStddevName, Help: SysbenchExecTimeStddevHelp},
+	{Name: SysbenchThreadsName, Help: SysbenchThreadsHelp},
+	{Name: SysbenchTpsSecondName, Help: SysbenchTpsSecondHelp},
+	{Name: SysbenchQpsSecondName, Help: SysbenchQpsSecondHelp},
+	{Name: SysbenchReadQpsSecondName, Help: SysbenchReadQpsSecondHelp},
+	{Name: SysbenchWriteQpsSecondName, Help: SysbenchWriteQpsSecondHelp},
+	{Name: SysbenchOtherQpsSecondName, Help: SysbenchOtherQpsSecondHelp},
+	{Name: SysbenchLatencySecondName, Help: SysbenchLatencySecondHelp},
+	{Name: SysbenchErrorsSecondName, Help: SysbenchErrorsSecondHelp},
+	{Name: SysbenchReconnectsSecondName, Help: SysbenchReconnectsSecondHelp},
+	{Name: SysbenchLatencyPercentileName, Help: SysbenchLatencyPercentileHelp, Labels: []string{"percentile"}},
+}
+
+func init() {
+	RegisterParser(Sysbench, sysbenchParser{}, &SysbenchGaugeMap)
+}
+
+// sysbenchParser parses the report of every interval and the statistics
+// sysbench prints when it finished
+type sysbenchParser struct{}
+
+func (sysbenchParser) Metrics() []MetricDesc {
+	return sysbenchMetrics
+}
+
+func (sysbenchParser) Streaming() bool {
+	return true
 }
 
-// RegisterSysbenchMetrics register sysbench metrics
-func RegisterSysbenchMetrics() {
-	for _, v := range SysbenchGaugeMap {
-		prometheus.MustRegister(v)
+func (sysbenchParser) ParseInterval(line string) ([]Sample, bool) {
+	if !sysbenchSecondRegex.MatchString(line) {
+		return nil, false
 	}
+	result := ParseSysbenchSecondResult(line)
+	return []Sample{
+		{Name: SysbenchThreadsName, Value: float64(result.Threads)},
+		{Name: SysbenchTpsSecondName, Value: result.TPS},
+		{Name: SysbenchQpsSecondName, Value: result.QPS},
+		{Name: SysbenchReadQpsSecondName, Value: result.Read},
+		{Name: SysbenchWriteQpsSecondName, Value: result.Write},
+		{Name: SysbenchOtherQpsSecondName, Value: result.Other},
+		{Name: SysbenchLatencySecondName, Value: result.NinetyNinth},
+		{Name: SysbenchErrorsSecondName, Value: result.Errors},
+		{Name: SysbenchReconnectsSecondName, Value: result.Reconnects},
+	}, true
+}
+
+func (sysbenchParser) ParseSummary(output string) ([]Sample, bool) {
+	// the execution time of the threads is the last line of the statistics
+	if !execTimeRegex.MatchString(output) {
+		return nil, false
+	}
+	result := ParseSysBenchResult(output)
+	samples := []Sample{
+		{Name: SysbenchQueryReadName, Value: float64(result.SQL.Read)},
+		{Name: SysbenchQueryWriteName, Value: float64(result.SQL.Write)},
+		{Name: SysbenchQueryOtherName, Value: float64(result.SQL.Other)},
+		{Name: SysbenchQueryTotalName, Value: float64(result.SQL.Total)},
+		{Name: SysbenchTransactionsName, Value: float64(result.Transactions)},
+		{Name: SysbenchQueriesName, Value: float64(result.Queries)},
+		{Name: SysbenchIgnoredErrorsName, Value: float64(result.IgnoreErrors)},
+		{Name: SysbenchReconnectsName, Value: float64(result.Reconnects)},
+		{Name: SysbenchTotalEventsName, Value: float64(result.General.TotalEvents)},
+		{Name: SysbenchTotalTimeName, Value: result.General.TotalTime},
+		{Name: SysbenchLatencyMinName, Value: result.Latency.Min},
+		{Name: SysbenchLatencyAvgName, Value: result.Latency.Avg},
+		{Name: SysbenchLatencyMaxName, Value: result.Latency.Max},
+		{Name: SysbenchLatencyNinetyNinthName, Value: result.Latency.NinetyNinth},
+		{Name: SysbenchLatencySumName, Value: result.Latency.Sum},
+		{Name: SysbenchEventsAvgName, Value: result.ThreadsFairness.EventsAvg},
+		{Name: SysbenchEventsStddevName, Value: result.ThreadsFairness.EventsStddev},
+		{Name: SysbenchExecTimeAvgName, Value: result.ThreadsFairness.ExecTimeAvg},
+		{Name: SysbenchExecTimeStddevName, Value: result.ThreadsFairness.ExecTimeStd},
+	}
+	for i, percentile := range SysbenchPercentiles {
+		if result.Histogram == nil {
+			break
+		}
+		samples = append(samples, Sample{
+			Name:   SysbenchLatencyPercentileName,
+			Labels: []string{strconv.FormatFloat(percentile, 'f', -1, 64)},
+			Value:  result.Percentiles[i],
+		})
+	}
+	return samples, true
+}
+
+func (sysbenchParser) Histograms() []MetricDesc {
+	return []MetricDesc{
+		{Name: SysbenchLatencyHistogramName, Help: SysbenchLatencyHistogramHelp},
+	}
+}
+
+func (sysbenchParser) ParseHistograms(output string) []HistogramSample {
+	rows := ParseSysbenchHistogram(output)
+	if len(rows) == 0 {
+		return nil
+	}
+	return []HistogramSample{NewHistogramSample(SysbenchLatencyHistogramName, nil, SysbenchLatencyBuckets, rows)}
+}
+
+func (sysbenchParser) Summarize(output string) string {
+	return summarizeFrom(output, "SQL statistics")
 }
 
 type SysbenchResult struct {
@@ -207,6 +304,11 @@ type SysbenchResult struct {
 	Queries         int               `json:"queries"`
 	IgnoreErrors    int               `json:"ignoreErrors"`
 	Reconnects      int               `json:"reconnects"`
+
+	// Histogram holds the rows of the latency histogram, Percentiles the
+	// SysbenchPercentiles computed from it
+	Histogram   []HistogramRow `json:"histogram,omitempty"`
+	Percentiles []float64      `json:"percentiles,omitempty"`
 }
 
 type SysbenchSecondResult struct {
@@ -248,40 +350,6 @@ type ThreadsFairness struct {
 	ExecTimeStd  float64 `json:"execTimeStd"`
 }
 
-func ScrapeSysbench(file, benchName, jobName string) {
-	// read the file
-	klog.Info("read file: ", file)
-	t, _ := tail.TailFile(file, tail.Config{Follow: true})
-
-	timer := time.NewTicker(30 * time.Second)
-	defer timer.Stop()
-
-	msg := ""
-	for {
-		select {
-		case line := <-t.Lines:
-			klog.Info("ScrapeSysbench: ", line.Text)
-			timer.Reset(30 * time.Second)
-			switch {
-			case sysbenchSecondRegex.MatchString(line.Text):
-				// this represents the output of every second
-				msg = line.Text
-				UpdateSysbenchMetricsSecond(benchName, jobName, msg)
-			case execTimeRegex.MatchString(line.Text):
-				// this represents the output of the whole test
-				msg += line.Text
-				UpdateSysbenchMetrics(benchName, jobName, msg)
-				return
-			default:
-				msg += line.Text + "\n"
-			}
-		case <-timer.C:
-			// don't receive any message in 30s, we think the sysbench test is finished
-			return
-		}
-	}
-}
-
 func ParseSysBenchResult(msg string) *SysbenchResult {
 	result := new(SysbenchResult)
 	lines := strings.Split(msg, "\n")
@@ -353,9 +421,33 @@ func ParseSysBenchResult(msg string) *SysbenchResult {
 		}
 	}
 
+	result.Histogram = ParseSysbenchHistogram(msg)
+	if result.Histogram != nil {
+		result.Percentiles = make([]float64, len(SysbenchPercentiles))
+		for i, percentile := range SysbenchPercentiles {
+			result.Percentiles[i] = HistogramPercentile(result.Histogram, percentile)
+		}
+	}
+
 	return result
 }
 
+// ParseSysbenchHistogram parses the rows of the latency histogram sysbench
+// prints ahead of the statistics with --histogram
+func ParseSysbenchHistogram(msg string) []HistogramRow {
+	var rows []HistogramRow
+	for _, l := range strings.Split(msg, "\n") {
+		match := histogramRowRegex.FindStringSubmatch(l)
+		if match == nil {
+			continue
+		}
+		value, _ := strconv.ParseFloat(match[1], 64)
+		count, _ := strconv.ParseUint(match[2], 10, 64)
+		rows = append(rows, HistogramRow{Value: value, Count: count})
+	}
+	return rows
+}
+
 func ParseSysbenchSecondResult(msg string) *SysbenchSecondResult {
 	//parse string like "[ 1s ] thds: 4 tps: 563.40 qps: 11319.87 (r/w/o: 7931.50/2257.58/1130.79) lat (ms,99%): 70.55 err/s: 0.00 reconn/s: 0.00"
 	result := new(SysbenchSecondResult)
@@ -364,7 +456,7 @@ func ParseSysbenchSecondResult(msg string) *SysbenchSecondResult {
 	tpsIndex := strings.Index(msg, "tps:")
 	qpsIndex := strings.Index(msg, "qps:")
 	rwoIndex := strings.Index(msg, "(r/w/o:")
-	latIndex := strings.Index(msg, "lat (ms,99%):")
+	latIndex := strings.Index(msg, "lat (ms,")
 	errIndex := strings.Index(msg, "err/s:")
 	reconnIndex := strings.Index(msg, "reconn/s:")
 
@@ -394,7 +486,7 @@ func ParseSysbenchSecondResult(msg string) *SysbenchSecondResult {
 	result.Write, _ = strconv.ParseFloat(wMsg, 64)
 	result.Other, _ = strconv.ParseFloat(oMsg, 64)
 
-	// parse ninety-fifth latency
+	// parse the latency percentile
 	latMsg := msg[latIndex:errIndex]
 	latMsg = strings.TrimSpace(strings.Split(latMsg, ":")[1])
 	result.NinetyNinth, _ = strconv.ParseFloat(latMsg, 64)
@@ -411,52 +503,3 @@ func ParseSysbenchSecondResult(msg string) *SysbenchSecondResult {
 
 	return result
 }
-
-func UpdateSysbenchMetrics(benchName, jobName, msg string) {
-	value := []string{benchName, jobName}
-	result := ParseSysBenchResult(msg)
-
-	CommonCounterInc(benchName, jobName, Sysbench)
-
-	// update total metrics
-	SysbenchGaugeMap[SysbenchQueryReadName].WithLabelValues(value...).Set(float64(result.SQL.Read))
-	SysbenchGaugeMap[SysbenchQueryWriteName].WithLabelValues(value...).Set(float64(result.SQL.Write))
-	SysbenchGaugeMap[SysbenchQueryOtherName].WithLabelValues(value...).Set(float64(result.SQL.Other))
-	SysbenchGaugeMap[SysbenchQueryTotalName].WithLabelValues(value...).Set(float64(result.SQL.Total))
-	SysbenchGaugeMap[SysbenchTransactionsName].WithLabelValues(value...).Set(float64(result.Transactions))
-	SysbenchGaugeMap[SysbenchQueriesName].WithLabelValues(value...).Set(float64(result.Queries))
-	SysbenchGaugeMap[SysbenchIgnoredErrorsName].WithLabelValues(value...).Set(float64(result.IgnoreErrors))
-	SysbenchGaugeMap[SysbenchReconnectsName].WithLabelValues(value...).Set(float64(result.Reconnects))
-	SysbenchGaugeMap[SysbenchTotalEventsName].WithLabelValues(value...).Set(float64(result.General.TotalEvents))
-	SysbenchGaugeMap[SysbenchTotalTimeName].WithLabelValues(value...).Set(result.General.TotalTime)
-	SysbenchGaugeMap[SysbenchLatencyMinName].WithLabelValues(value...).Set(result.Latency.Min)
-	SysbenchGaugeMap[SysbenchLatencyAvgName].WithLabelValues(value...).Set(result.Latency.Avg)
-	SysbenchGaugeMap[SysbenchLatencyMaxName].WithLabelValues(value...).Set(result.Latency.Max)
-	SysbenchGaugeMap[SysbenchLatencyNinetyNinthName].WithLabelValues(value...).Set(result.Latency.NinetyNinth)
-	SysbenchGaugeMap[SysbenchLatencySumName].WithLabelValues(value...).Set(result.Latency.Sum)
-	SysbenchGaugeMap[SysbenchEventsAvgName].WithLabelValues(value...).Set(result.ThreadsFairness.EventsAvg)
-	SysbenchGaugeMap[SysbenchEventsStddevName].WithLabelValues(value...).Set(result.ThreadsFairness.EventsStddev)
-	SysbenchGaugeMap[SysbenchExecTimeAvgName].WithLabelValues(value...).Set(result.ThreadsFairness.ExecTimeAvg)
-	SysbenchGaugeMap[SysbenchExecTimeStddevName].WithLabelValues(value...).Set(result.ThreadsFairness.ExecTimeStd)
-	klog.Info("update sysbench total metrics")
-}
-
-func UpdateSysbenchMetricsSecond(benchName, jobName, msg string) {
-	value := []string{benchName, jobName}
-	secondResult := ParseSysbenchSecondResult(msg)
-
-	CommonCounterInc(benchName, jobName, Sysbench)
-
-	// update second metrics
-	SysbenchGaugeMap[SysbenchThreadsName].WithLabelValues(value...).Set(float64(secondResult.Threads))
-	SysbenchGaugeMap[SysbenchTpsSecondName].WithLabelValues(value...).Set(secondResult.TPS)
-	SysbenchGaugeMap[SysbenchQpsSecondName].WithLabelValues(value...).Set(secondResult.QPS)
-	SysbenchGaugeMap[SysbenchReadQpsSecondName].WithLabelValues(value...).Set(secondResult.Read)
-	SysbenchGaugeMap[SysbenchWriteQpsSecondName].WithLabelValues(value...).Set(secondResult.Write)
-	SysbenchGaugeMap[SysbenchOtherQpsSecondName].WithLabelValues(value...).Set(secondResult.Other)
-	SysbenchGaugeMap[SysbenchLatencySecondName].WithLabelValues(value...).Set(secondResult.NinetyNinth)
-	SysbenchGaugeMap[SysbenchErrorsSecondName].WithLabelValues(value...).Set(secondResult.Errors)
-	SysbenchGaugeMap[SysbenchReconnectsSecondName].WithLabelValues(value...).Set(secondResult.Reconnects)
-
-	klog.Info("update sysbench second metrics")
-}
@@ -192,3 +192,50 @@ func TestParseSysbenchSecondsResult(t *testing.T) {
 		}
 	}
 }
+
+func TestParseSysbenchHistogram(t *testing.T) {
+	msg, err := os.ReadFile("testdata/sysbench_histogram.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := ParseSysBenchResult(string(msg))
+	if len(result.Histogram) != 9 {
+		t.Fatalf("expected 9 histogram rows, got %d", len(result.Histogram))
+	}
+	if result.Latency.NinetyNinth != 20.37 {
+		t.Errorf("expected the configured percentile 20.37, got %f", result.Latency.NinetyNinth)
+	}
+	expected := []float64{2.477, 10.090, 20.370, 41.100, 184.540}
+	for i, percentile := range SysbenchPercentiles {
+		if result.Percentiles[i] != expected[i] {
+			t.Errorf("expected p%v %f, got %f", percentile, expected[i], result.Percentiles[i])
+		}
+	}
+
+	sample := NewHistogramSample(SysbenchLatencyHistogramName, nil, SysbenchLatencyBuckets, result.Histogram)
+	if sample.Count != 763 {
+		t.Errorf("expected 763 events, got %d", sample.Count)
+	}
+	if len(sample.Buckets) != len(SysbenchLatencyBuckets) {
+		t.Errorf("expected %d buckets, got %d", len(SysbenchLatencyBuckets), len(sample.Buckets))
+	}
+	// the rows up to 1.6ms
+	if sample.Buckets[1.6] != 50 {
+		t.Errorf("expected 50 events up to 1.6ms, got %d", sample.Buckets[1.6])
+	}
+
+	if result := ParseSysBenchResult(""); result.Histogram != nil || result.Percentiles != nil {
+		t.Errorf("expected no histogram without --histogram")
+	}
+}
+
+func TestParseSysbenchSecondResultPercentile(t *testing.T) {
+	line := "[ 1s ] thds: 4 tps: 563.40 qps: 11319.87 (r/w/o: 7931.50/2257.58/1130.79) lat (ms,95%): 12.30 err/s: 0.00 reconn/s: 0.00"
+	if !sysbenchSecondRegex.MatchString(line) {
+		t.Fatalf("expected the interval with another percentile to match")
+	}
+	if result := ParseSysbenchSecondResult(line); result.NinetyNinth != 12.30 {
+		t.Errorf("expected 12.30, got %f", result.NinetyNinth)
+	}
+}
@@ -0,0 +1,116 @@
+package exporter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+// writeWarmupLog writes a log with a warmup pass made of the first lines of
+// warmup ahead of the measured pass in testdata
+func writeWarmupLog(t *testing.T, warmup, measured string) string {
+	t.Helper()
+	data, err := os.ReadFile(measured)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := strings.Join([]string{constants.WarmupStartMarker, warmup, constants.WarmupEndMarker, string(data)}, "\n") + "\n"
+
+	file := filepath.Join(t.TempDir(), "bench.log")
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestScrapeSysbenchSkipsWarmup(t *testing.T) {
+	initGauges(Sysbench)
+	warmup := `[ 1s ] thds: 4 tps: 1.00 qps: 2.00 (r/w/o: 1.00/0.50/0.50) lat (ms,99%): 900.00 err/s: 0.00 reconn/s: 0.00
+SQL statistics:
+    queries performed:
+        read:                            1
+    transactions:                        1   (1.00 per sec.)
+Threads fairness:
+    execution time (avg/stddev):   1.0000/0.00`
+	file := writeWarmupLog(t, warmup, "testdata/sysbench.txt")
+
+	Scrape(Sysbench, file, "sb", "sb-run-0", "", make(chan struct{}, 1))
+
+	if got := testutil.ToFloat64(SysbenchGaugeMap[SysbenchTpsSecondName].WithLabelValues("sb", "sb-run-0")); got != 563.40 {
+		t.Fatalf("expected tps of the measured pass, got %f", got)
+	}
+	if got := testutil.ToFloat64(SysbenchGaugeMap[SysbenchQueryReadName].WithLabelValues("sb", "sb-run-0")); got != 110110 {
+		t.Fatalf("expected reads of the measured pass, got %f", got)
+	}
+}
+
+func TestScrapePgbenchSkipsWarmup(t *testing.T) {
+	initGauges(Pgbench)
+	warmup := `progress: 1.0 s, 5.0 tps, lat 300.000 ms stddev 8.900, 0 failed
+number of clients: 2
+tps = 5.000000 (without initial connection time)`
+	file := writeWarmupLog(t, warmup, "testdata/pgbench.txt")
+
+	Scrape(Pgbench, file, "pg", "pg-run-0", "", make(chan struct{}, 1))
+
+	if got := testutil.ToFloat64(PgbenchGaugeMap[PgbenchTpsSecondName].WithLabelValues("pg", "pg-run-0")); got != 610 {
+		t.Fatalf("expected tps of the measured pass, got %f", got)
+	}
+	if got := testutil.ToFloat64(PgbenchGaugeMap[PgbenchTpsName].WithLabelValues("pg", "pg-run-0")); got != 754.431143 {
+		t.Fatalf("expected tps of the measured pass, got %f", got)
+	}
+}
+
+func TestSummaryFuncSkipsWarmup(t *testing.T) {
+	sysbench, err := os.ReadFile("testdata/sysbench.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pgbench, err := os.ReadFile("testdata/pgbench.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testcase := []struct {
+		kind     string
+		warmup   string
+		measured string
+		expected string
+	}{
+		{
+			kind:     Sysbench,
+			warmup:   "SQL statistics:\n    transactions:                        1   (1.00 per sec.)",
+			measured: string(sysbench),
+			expected: "(392.62 per sec.)",
+		},
+		{
+			kind:     Pgbench,
+			warmup:   "transaction type: <builtin: TPC-B (sort of)>\ntps = 5.000000 (without initial connection time)",
+			measured: string(pgbench),
+			expected: "tps = 754.431143",
+		},
+		{
+			kind:     Ycsb,
+			warmup:   "Run finished, takes 1s\nREAD - Takes(s): 1.0, Count: 1, OPS: 1.0",
+			measured: "Run finished, takes 60s\nREAD - Takes(s): 60.0, Count: 6000, OPS: 100.0",
+			expected: "OPS: 100.0",
+		},
+	}
+
+	for _, tc := range testcase {
+		output := strings.Join([]string{constants.WarmupStartMarker, tc.warmup, constants.WarmupEndMarker, tc.measured}, "\n")
+
+		summary := SummaryFunc(tc.kind)(output)
+		if strings.Contains(summary, constants.WarmupEndMarker) || !strings.Contains(summary, tc.expected) {
+			t.Fatalf("%s: expected the summary of the measured pass, got %q", tc.kind, summary)
+		}
+		if strings.Contains(summary, strings.Split(tc.warmup, "\n")[1]) {
+			t.Fatalf("%s: the summary holds the warmup pass: %q", tc.kind, summary)
+		}
+	}
+}
@@ -0,0 +1,349 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/apecloud/kubebench/api/v1alpha1"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+const defaultArchiveChunkBytes = 512 * 1024
+
+// ArchiveJobLogs archives the logs of all containers of the job's pods and
+// records where they went. A log that can not be archived is recorded with
+// the error, the benchmark goes on without it.
+func ArchiveJobLogs(cli client.Client, restConfig *rest.Config, reqCtx context.Context, scheme *runtime.Scheme, owner client.Object, archive *v1alpha1.LogArchive, jobName string, records *[]v1alpha1.ArchivedLog) error {
+	if archive == nil {
+		return nil
+	}
+	l := log.FromContext(reqCtx)
+
+	podList, err := GetPodListFromJob(cli, reqCtx, jobName, owner.GetNamespace())
+	if err != nil {
+		l.Error(err, "failed to get pod list from job", "job", jobName)
+		return err
+	}
+
+	logs := make(map[string]string)
+	for _, pod := range podList.Items {
+		for _, container := range pod.Spec.Containers {
+			msg, err := GetContainerLogFromPod(restConfig, reqCtx, pod.Name, pod.Namespace, container.Name)
+			if err != nil {
+				l.Error(err, "failed to get log from pod", "pod", pod.Name, "container", container.Name)
+				setArchivedLog(records, v1alpha1.ArchivedLog{Job: jobName, Container: container.Name, Error: err.Error()})
+				continue
+			}
+			logs[container.Name] = msg
+		}
+	}
+
+	for _, record := range ArchiveLogs(cli, reqCtx, scheme, owner, archive, jobName, logs) {
+		setArchivedLog(records, record)
+	}
+	return nil
+}
+
+// ArchiveLogs stores the logs of the containers of the job in the archive,
+// the logs are keyed by container name
+func ArchiveLogs(cli client.Client, reqCtx context.Context, scheme *runtime.Scheme, owner client.Object, archive *v1alpha1.LogArchive, jobName string, logs map[string]string) []v1alpha1.ArchivedLog {
+	l := log.FromContext(reqCtx)
+
+	containers := make([]string, 0, len(logs))
+	for container := range logs {
+		containers = append(containers, container)
+	}
+	sort.Strings(containers)
+
+	records := make([]v1alpha1.ArchivedLog, 0, len(containers))
+	chunks := make(map[string][]string)
+	for _, container := range containers {
+		record := v1alpha1.ArchivedLog{Job: jobName, Container: container}
+
+		var err error
+		switch {
+		case archive.PVC != nil, archive.S3 != nil:
+			// the location is recorded by SyncArchivedLogs once the log is
+			// in the claim or the bucket
+			chunks[container], err = archiveToConfigMaps(cli, reqCtx, scheme, owner, jobName, container, logs[container], defaultArchiveChunkBytes)
+		case archive.ConfigMap != nil:
+			record.Location = fmt.Sprintf("configmap://%s/%s", owner.GetNamespace(), archiveConfigMapPrefix(jobName, container))
+			var names []string
+			names, err = archiveToConfigMaps(cli, reqCtx, scheme, owner, jobName, container, logs[container], archive.ConfigMap.ChunkBytes)
+			record.Chunks = len(names)
+		default:
+			err = fmt.Errorf("the log archive has no pvc, s3 or configMap")
+		}
+		if err != nil {
+			l.Error(err, "failed to archive log", "job", jobName, "container", container)
+			record.Error = err.Error()
+		}
+		records = append(records, record)
+	}
+
+	// the chunks are copied into the claim or uploaded to the bucket by a
+	// job, the controller can not mount the claim and does not wait for the
+	// upload
+	if len(chunks) > 0 {
+		if err := createArchiveJob(cli, reqCtx, scheme, owner, archive, jobName, chunks); err != nil {
+			l.Error(err, "failed to create archive job", "job", jobName)
+			for i := range records {
+				if records[i].Error == "" {
+					records[i].Error = err.Error()
+				}
+				if err := deleteArchiveConfigMaps(cli, reqCtx, owner, jobName, records[i].Container); err != nil {
+					l.Error(err, "failed to delete the staged log", "job", jobName, "container", records[i].Container)
+				}
+			}
+		}
+	}
+
+	return records
+}
+
+// SyncArchivedLogs records the location of the logs archived to a PVC or S3
+// once the job copying them succeeded, or the error once it failed, and then
+// deletes the ConfigMaps the logs were staged in. It returns true while a log
+// is still being copied.
+func SyncArchivedLogs(cli client.Client, reqCtx context.Context, owner client.Object, archive *v1alpha1.LogArchive, records *[]v1alpha1.ArchivedLog) (bool, error) {
+	if archive == nil || (archive.PVC == nil && archive.S3 == nil) {
+		return false, nil
+	}
+
+	pending := false
+	for i := range *records {
+		record := &(*records)[i]
+		if record.Location != "" || record.Error != "" {
+			continue
+		}
+
+		job := &batchv1.Job{}
+		err := cli.Get(reqCtx, client.ObjectKey{Namespace: owner.GetNamespace(), Name: archiveJobName(record.Job)}, job)
+		switch {
+		case apierrors.IsNotFound(err):
+			record.Error = fmt.Sprintf("the archive job %s is gone", archiveJobName(record.Job))
+		case err != nil:
+			return pending, err
+		case job.Status.Succeeded > 0:
+			file := archiveFile(archive, owner, record.Job, record.Container)
+			if archive.S3 != nil {
+				record.Location = fmt.Sprintf("s3://%s/%s", archive.S3.Bucket, file)
+			} else {
+				record.Location = fmt.Sprintf("pvc://%s/%s", archive.PVC.ClaimName, file)
+			}
+		case jobFinished(&job.Status):
+			record.Error = fmt.Sprintf("the archive job %s failed", job.Name)
+		default:
+			pending = true
+			continue
+		}
+
+		if err := deleteArchiveConfigMaps(cli, reqCtx, owner, record.Job, record.Container); err != nil {
+			return pending, err
+		}
+	}
+	return pending, nil
+}
+
+func setArchivedLog(records *[]v1alpha1.ArchivedLog, record v1alpha1.ArchivedLog) {
+	for i := range *records {
+		if (*records)[i].Job == record.Job && (*records)[i].Container == record.Container {
+			(*records)[i] = record
+			return
+		}
+	}
+	*records = append(*records, record)
+}
+
+// archiveFile is the path of the archived log in the claim or the key in the
+// bucket
+func archiveFile(archive *v1alpha1.LogArchive, owner client.Object, jobName, container string) string {
+	return path.Join(archive.Prefix, owner.GetName(), jobName, container+".log")
+}
+
+func archiveConfigMapPrefix(jobName, container string) string {
+	return fmt.Sprintf("%s-%s-log", jobName, container)
+}
+
+// archiveToConfigMaps splits the log into ConfigMaps owned by the benchmark
+// and returns their names in order
+func archiveToConfigMaps(cli client.Client, reqCtx context.Context, scheme *runtime.Scheme, owner client.Object, jobName, container, msg string, chunkBytes int) ([]string, error) {
+	names := make([]string, 0)
+	for i, chunk := range splitLog(msg, chunkBytes) {
+		cm := &corev1.ConfigMap{}
+		cm.Name = fmt.Sprintf("%s-%d", archiveConfigMapPrefix(jobName, container), i)
+		cm.Namespace = owner.GetNamespace()
+		cm.Labels = map[string]string{
+			constants.KubeBenchNameLabel: owner.GetName(),
+			"job-name":                   jobName,
+		}
+		cm.Data = map[string]string{"log": chunk}
+		if err := controllerutil.SetOwnerReference(owner, cm, scheme); err != nil {
+			return nil, err
+		}
+
+		err := cli.Create(reqCtx, cm)
+		if apierrors.IsAlreadyExists(err) {
+			err = cli.Update(reqCtx, cm)
+		}
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, cm.Name)
+	}
+	return names, nil
+}
+
+// deleteArchiveConfigMaps deletes the ConfigMaps the log of the container is
+// split into
+func deleteArchiveConfigMaps(cli client.Client, reqCtx context.Context, owner client.Object, jobName, container string) error {
+	cmList := &corev1.ConfigMapList{}
+	if err := cli.List(reqCtx, cmList, client.InNamespace(owner.GetNamespace()), client.MatchingLabels{
+		constants.KubeBenchNameLabel: owner.GetName(),
+		"job-name":                   jobName,
+	}); err != nil {
+		return err
+	}
+
+	prefix := archiveConfigMapPrefix(jobName, container) + "-"
+	for i := range cmList.Items {
+		if !strings.HasPrefix(cmList.Items[i].Name, prefix) {
+			continue
+		}
+		if err := cli.Delete(reqCtx, &cmList.Items[i]); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func archiveJobName(jobName string) string {
+	return fmt.Sprintf("%s-archive", jobName)
+}
+
+// splitLog splits the log into chunks of at most size bytes without cutting
+// a character in half, an empty log is a single empty chunk
+func splitLog(msg string, size int) []string {
+	if size <= 0 {
+		size = defaultArchiveChunkBytes
+	}
+	chunks := make([]string, 0, len(msg)/size+1)
+	for len(msg) > size {
+		end := size
+		for end > 0 && !utf8.RuneStart(msg[end]) {
+			end--
+		}
+		if end == 0 {
+			end = size
+		}
+		chunks = append(chunks, msg[:end])
+		msg = msg[end:]
+	}
+	return append(chunks, msg)
+}
+
+// createArchiveJob creates a job that concatenates the chunks of every
+// container into <prefix>/<benchmark>/<job>/<container>.log on the claim, or
+// uploads them as that key to the bucket
+func createArchiveJob(cli client.Client, reqCtx context.Context, scheme *runtime.Scheme, owner client.Object, archive *v1alpha1.LogArchive, jobName string, chunks map[string][]string) error {
+	name := archiveJobName(jobName)
+	existed, err := IsJobExisted(cli, reqCtx, name, owner.GetNamespace())
+	if err != nil || existed {
+		return err
+	}
+
+	job := JobTemplate(name, owner.GetNamespace())
+	spec := &job.Spec.Template.Spec
+	if archive.PVC != nil {
+		spec.Volumes = append(spec.Volumes, corev1.Volume{
+			Name: "archive",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: archive.PVC.ClaimName},
+			},
+		})
+	}
+
+	containers := make([]string, 0, len(chunks))
+	for container := range chunks {
+		containers = append(containers, container)
+	}
+	sort.Strings(containers)
+
+	for i, container := range containers {
+		volume := fmt.Sprintf("chunks-%d", i)
+		sources := make([]corev1.VolumeProjection, 0, len(chunks[container]))
+		files := make([]string, 0, len(chunks[container]))
+		for j, cm := range chunks[container] {
+			sources = append(sources, corev1.VolumeProjection{ConfigMap: &corev1.ConfigMapProjection{
+				LocalObjectReference: corev1.LocalObjectReference{Name: cm},
+				Items:                []corev1.KeyToPath{{Key: "log", Path: strconv.Itoa(j)}},
+			}})
+			files = append(files, path.Join("/chunks", strconv.Itoa(j)))
+		}
+
+		spec.Volumes = append(spec.Volumes, corev1.Volume{
+			Name:         volume,
+			VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{Sources: sources}},
+		})
+		c := corev1.Container{
+			Name:            container,
+			Image:           constants.GetBenchmarkImage(constants.KubebenchTools),
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Command:         []string{"/tools"},
+			VolumeMounts:    []corev1.VolumeMount{{Name: volume, MountPath: "/chunks"}},
+		}
+		file := archiveFile(archive, owner, jobName, container)
+		if archive.S3 != nil {
+			c.Args = append(s3PutArgs(archive.S3, file), files...)
+			c.Env = s3CredentialEnv(archive.S3.SecretName)
+		} else {
+			c.Args = append([]string{"concat", "--output", path.Join("/archive", file)}, files...)
+			c.VolumeMounts = append([]corev1.VolumeMount{{Name: "archive", MountPath: "/archive"}}, c.VolumeMounts...)
+		}
+		spec.Containers = append(spec.Containers, c)
+	}
+
+	AddLabelsToJobs([]*batchv1.Job{job}, map[string]string{constants.KubeBenchNameLabel: owner.GetName()})
+	if err := controllerutil.SetOwnerReference(owner, job, scheme); err != nil {
+		return err
+	}
+	return cli.Create(reqCtx, job)
+}
+
+func s3PutArgs(s3 *v1alpha1.S3Archive, key string) []string {
+	args := []string{"s3", "put", "--endpoint", s3.Endpoint, "--bucket", s3.Bucket, "--key", key}
+	if s3.Region != "" {
+		args = append(args, "--region", s3.Region)
+	}
+	if s3.InsecureSkipVerify {
+		args = append(args, "--insecure-skip-verify")
+	}
+	return args
+}
+
+// s3CredentialEnv passes the keys of the Secret to the tools image, the
+// controller does not read them
+func s3CredentialEnv(secretName string) []corev1.EnvVar {
+	env := make([]corev1.EnvVar, 0, 2)
+	for _, v := range [][2]string{{"AWS_ACCESS_KEY_ID", "accessKeyId"}, {"AWS_SECRET_ACCESS_KEY", "secretAccessKey"}} {
+		env = append(env, corev1.EnvVar{Name: v[0], ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+			Key:                  v[1],
+		}}})
+	}
+	return env
+}
@@ -0,0 +1,185 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	benchmarkv1alpha1 "github.com/apecloud/kubebench/api/v1alpha1"
+)
+
+func TestArchiveLogsToConfigMaps(t *testing.T) {
+	cli, scheme := newTestClient()
+	owner := &benchmarkv1alpha1.Sysbench{ObjectMeta: metav1.ObjectMeta{Name: "sb", Namespace: "default", UID: "uid"}}
+	archive := &benchmarkv1alpha1.LogArchive{ConfigMap: &benchmarkv1alpha1.ConfigMapArchive{ChunkBytes: 1024}}
+	log := strings.Repeat("é", 1000)
+
+	records := ArchiveLogs(cli, context.Background(), scheme, owner, archive, "sb-run-0", map[string]string{
+		"kubebench": log,
+		"metrics":   "",
+	})
+	if len(records) != 2 {
+		t.Fatalf("expected a record per container, got %+v", records)
+	}
+	if records[0].Container != "kubebench" || records[0].Location != "configmap://default/sb-run-0-kubebench-log" || records[0].Chunks != 2 || records[0].Error != "" {
+		t.Fatalf("unexpected record: %+v", records[0])
+	}
+	if records[1].Container != "metrics" || records[1].Chunks != 1 {
+		t.Fatalf("expected an empty log to be a single chunk, got %+v", records[1])
+	}
+
+	got := ""
+	for i := 0; i < records[0].Chunks; i++ {
+		cm := &corev1.ConfigMap{}
+		if err := cli.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: fmt.Sprintf("sb-run-0-kubebench-log-%d", i)}, cm); err != nil {
+			t.Fatal(err)
+		}
+		if len(cm.OwnerReferences) != 1 {
+			t.Fatalf("expected the chunk to be owned by the benchmark, got %+v", cm.OwnerReferences)
+		}
+		got += cm.Data["log"]
+	}
+	if got != log {
+		t.Fatal("expected the chunks to add up to the log without splitting characters")
+	}
+
+	// archiving again replaces the chunks
+	records = ArchiveLogs(cli, context.Background(), scheme, owner, archive, "sb-run-0", map[string]string{"kubebench": "short"})
+	if records[0].Error != "" || records[0].Chunks != 1 {
+		t.Fatalf("unexpected record: %+v", records[0])
+	}
+}
+
+func TestArchiveLogsToPVC(t *testing.T) {
+	cli, scheme := newTestClient()
+	owner := &benchmarkv1alpha1.Sysbench{ObjectMeta: metav1.ObjectMeta{Name: "sb", Namespace: "default", UID: "uid"}}
+	archive := &benchmarkv1alpha1.LogArchive{Prefix: "nightly", PVC: &benchmarkv1alpha1.PVCArchive{ClaimName: "logs"}}
+
+	records := ArchiveLogs(cli, context.Background(), scheme, owner, archive, "sb-run-0", map[string]string{"kubebench": "tps: 100"})
+	if len(records) != 1 || records[0].Location != "" || records[0].Error != "" {
+		t.Fatalf("expected no location before the log is copied, got %+v", records)
+	}
+
+	job := &batchv1.Job{}
+	if err := cli.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "sb-run-0-archive"}, job); err != nil {
+		t.Fatal(err)
+	}
+	claim := ""
+	for _, volume := range job.Spec.Template.Spec.Volumes {
+		if volume.Name == "archive" && volume.PersistentVolumeClaim != nil {
+			claim = volume.PersistentVolumeClaim.ClaimName
+		}
+	}
+	if claim != "logs" || len(job.Spec.Template.Spec.Containers) != 1 {
+		t.Fatalf("unexpected archive job: %+v", job.Spec.Template.Spec)
+	}
+	c := job.Spec.Template.Spec.Containers[0]
+	if strings.Join(c.Args, " ") != "concat --output /archive/nightly/sb/sb-run-0/kubebench.log /chunks/0" {
+		t.Fatalf("unexpected archive args: %v", c.Args)
+	}
+	if len(c.VolumeMounts) == 0 || c.VolumeMounts[0].Name != "archive" || c.VolumeMounts[0].MountPath != "/archive" {
+		t.Fatalf("expected the claim to be mounted, got %+v", c.VolumeMounts)
+	}
+
+	if archiving, err := SyncArchivedLogs(cli, context.Background(), owner, archive, &records); err != nil || !archiving || records[0].Location != "" {
+		t.Fatalf("expected the log to be copying, got %v %v %+v", archiving, err, records)
+	}
+
+	job.Status.Succeeded = 1
+	if err := cli.Status().Update(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+	if archiving, err := SyncArchivedLogs(cli, context.Background(), owner, archive, &records); err != nil || archiving {
+		t.Fatalf("expected the log to be copied, got %v %v", archiving, err)
+	}
+	if records[0].Location != "pvc://logs/nightly/sb/sb-run-0/kubebench.log" || records[0].Error != "" {
+		t.Fatalf("unexpected records: %+v", records)
+	}
+	cms := &corev1.ConfigMapList{}
+	if err := cli.List(context.Background(), cms, client.InNamespace("default")); err != nil {
+		t.Fatal(err)
+	}
+	if len(cms.Items) != 0 {
+		t.Fatalf("expected the staged log to be deleted, got %d ConfigMaps", len(cms.Items))
+	}
+}
+
+func TestArchiveLogsToS3(t *testing.T) {
+	cli, scheme := newTestClient()
+	owner := &benchmarkv1alpha1.Sysbench{ObjectMeta: metav1.ObjectMeta{Name: "sb", Namespace: "default", UID: "uid"}}
+	archive := &benchmarkv1alpha1.LogArchive{S3: &benchmarkv1alpha1.S3Archive{
+		Endpoint: "http://minio:9000", Bucket: "bench", Region: "eu-west-1", SecretName: "s3", InsecureSkipVerify: true,
+	}}
+
+	records := ArchiveLogs(cli, context.Background(), scheme, owner, archive, "sb-run-0", map[string]string{"kubebench": "tps: 100"})
+	if len(records) != 1 || records[0].Location != "" || records[0].Error != "" {
+		t.Fatalf("expected no location before the log is uploaded, got %+v", records)
+	}
+
+	job := &batchv1.Job{}
+	if err := cli.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "sb-run-0-archive"}, job); err != nil {
+		t.Fatal(err)
+	}
+	if len(job.Spec.Template.Spec.Containers) != 1 {
+		t.Fatalf("unexpected archive job: %+v", job.Spec.Template.Spec)
+	}
+	c := job.Spec.Template.Spec.Containers[0]
+	want := "s3 put --endpoint http://minio:9000 --bucket bench --key sb/sb-run-0/kubebench.log --region eu-west-1 --insecure-skip-verify /chunks/0"
+	if strings.Join(c.Args, " ") != want {
+		t.Fatalf("unexpected archive args: %v", c.Args)
+	}
+	if len(c.Env) != 2 || c.Env[0].ValueFrom.SecretKeyRef.Name != "s3" || c.Env[0].ValueFrom.SecretKeyRef.Key != "accessKeyId" {
+		t.Fatalf("expected the credentials to come from the secret, got %+v", c.Env)
+	}
+	for _, volume := range job.Spec.Template.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			t.Fatalf("expected no claim, got %+v", volume)
+		}
+	}
+
+	job.Status.Succeeded = 1
+	if err := cli.Status().Update(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+	if archiving, err := SyncArchivedLogs(cli, context.Background(), owner, archive, &records); err != nil || archiving {
+		t.Fatalf("expected the log to be uploaded, got %v %v", archiving, err)
+	}
+	if records[0].Location != "s3://bench/sb/sb-run-0/kubebench.log" || records[0].Error != "" {
+		t.Fatalf("unexpected records: %+v", records)
+	}
+}
+
+func TestWithPodLogs(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = io.WriteString(w, "tps: 100")
+	}))
+	defer server.Close()
+	restConfig := &rest.Config{Host: server.URL}
+
+	ctx := WithPodLogs(context.Background())
+	for i := 0; i < 2; i++ {
+		msg, err := GetContainerLogFromPod(restConfig, ctx, "sb-run-0-abc", "default", "kubebench")
+		if err != nil || msg != "tps: 100" {
+			t.Fatalf("unexpected log: %q %v", msg, err)
+		}
+	}
+	if requests != 1 {
+		t.Fatalf("expected the log to be fetched once, got %d requests", requests)
+	}
+
+	if _, err := GetContainerLogFromPod(restConfig, context.Background(), "sb-run-0-abc", "default", "kubebench"); err != nil || requests != 2 {
+		t.Fatalf("expected the log to be fetched without the cache, got %d requests %v", requests, err)
+	}
+}
@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	benchmarkv1alpha1 "github.com/apecloud/kubebench/api/v1alpha1"
+)
+
+// newTestClient returns a fake client that knows the core and the benchmark types, and its scheme.
+func newTestClient(objs ...client.Object) (client.Client, *runtime.Scheme) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = benchmarkv1alpha1.AddToScheme(scheme)
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(), scheme
+}
+
+func newTestTarget(driver string) benchmarkv1alpha1.Target {
+	return benchmarkv1alpha1.Target{
+		Driver:   driver,
+		Host:     "db.default.svc",
+		Port:     3306,
+		User:     "root",
+		Password: "secret",
+		Database: "kubebench",
+	}
+}
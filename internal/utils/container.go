@@ -131,3 +131,67 @@ func InitGaussdbDatabaseContainer(target v1alpha1.Target, database string) *core
 		Args:            args,
 	}
 }
+
+// InitTidbDatabaseContainer will create a database in tidb
+func InitTidbDatabaseContainer(target v1alpha1.Target, database string) *corev1.Container {
+	args := []string{
+		"tidb",
+		"create",
+		database,
+		"--host", target.Host,
+		"--port", strconv.Itoa(target.Port),
+		"--user", target.User,
+		"--password", target.Password,
+	}
+
+	return &corev1.Container{
+		Name:            "init",
+		Image:           constants.GetBenchmarkImage(constants.KubebenchTools),
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Command:         []string{"/tools"},
+		Args:            args,
+	}
+}
+
+// InitMssqlDatabaseContainer will create a database in sql server
+func InitMssqlDatabaseContainer(target v1alpha1.Target, database string) *corev1.Container {
+	args := []string{
+		"mssql",
+		"create",
+		database,
+		"--host", target.Host,
+		"--port", strconv.Itoa(target.Port),
+		"--user", target.User,
+		"--password", target.Password,
+	}
+
+	return &corev1.Container{
+		Name:            "init",
+		Image:           constants.GetBenchmarkImage(constants.KubebenchTools),
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Command:         []string{"/tools"},
+		Args:            args,
+	}
+}
+
+// InitOceanBaseOracleSchemaContainer will create a schema in an oceanbase
+// oracle tenant, the schema is the user of the same name
+func InitOceanBaseOracleSchemaContainer(target v1alpha1.Target, schema string) *corev1.Container {
+	args := []string{
+		"oceanbase-oracle",
+		"create",
+		schema,
+		"--host", target.Host,
+		"--port", strconv.Itoa(target.Port),
+		"--user", target.User,
+		"--password", target.Password,
+	}
+
+	return &corev1.Container{
+		Name:            "init",
+		Image:           constants.GetBenchmarkImage(constants.KubebenchTools),
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Command:         []string{"/tools"},
+		Args:            args,
+	}
+}
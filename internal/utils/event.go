@@ -0,0 +1,221 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/apecloud/kubebench/api/v1alpha1"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+// the reasons of the events of a benchmark
+const (
+	EventJobCreated      = "JobCreated"
+	EventJobStarted      = "JobStarted"
+	EventJobSucceeded    = "JobSucceeded"
+	EventJobFailed       = "JobFailed"
+	EventPreCheckFailed  = "PreCheckFailed"
+	EventPhaseTransition = "PhaseTransition"
+)
+
+// the annotations that mark the jobs whose start, end and metrics have
+// been recorded, a reconcile that is requeued does not record them again
+const (
+	jobStartedAnnotation  = "kubebench.apecloud.io/started"
+	jobFinishedAnnotation = "kubebench.apecloud.io/finished"
+	jobObservedAnnotation = "kubebench.apecloud.io/observed"
+)
+
+// maxEventParams keeps the event message below the limit of the API server
+const maxEventParams = 512
+
+// JobParams returns the env values and the command of the benchmark
+// container of the job with the secrets masked. The parameters of some
+// benchmarks, e.g. the CONFIGS of sysbench and tpch, live in the env.
+func JobParams(job *batchv1.Job, secrets ...string) string {
+	var params []string
+	for _, c := range job.Spec.Template.Spec.Containers {
+		if c.Name != constants.ContainerName {
+			continue
+		}
+		for _, env := range c.Env {
+			if env.ValueFrom != nil {
+				continue
+			}
+			value := maskFields(env.Value, ",", secrets)
+			if isPasswordKey(env.Name) {
+				value = maskedSecret
+			}
+			params = append(params, env.Name+"="+value)
+		}
+		for _, arg := range append(append([]string{}, c.Command...), c.Args...) {
+			params = append(params, maskFields(arg, " ", secrets))
+		}
+		break
+	}
+
+	result := strings.Join(params, " ")
+	if len(result) > maxEventParams {
+		result = result[:maxEventParams] + "..."
+	}
+	return result
+}
+
+const maskedSecret = "******"
+
+// maskFields masks the fields of the value split by sep that are a secret,
+// and the values of the key=value or key:value fields that are a secret or
+// whose key names a password. Only whole fields are masked, so a short
+// secret does not mangle the text around it.
+func maskFields(value, sep string, secrets []string) string {
+	fields := strings.Split(value, sep)
+	for i, field := range fields {
+		if isSecret(field, secrets) {
+			fields[i] = maskedSecret
+			continue
+		}
+		if j := strings.IndexAny(field, "=:"); j > 0 && (isPasswordKey(field[:j]) || isSecret(field[j+1:], secrets)) {
+			fields[i] = field[:j+1] + maskedSecret
+		}
+	}
+	return strings.Join(fields, sep)
+}
+
+func isSecret(field string, secrets []string) bool {
+	field = strings.Trim(field, `"'`)
+	for _, secret := range secrets {
+		if secret != "" && field == secret {
+			return true
+		}
+	}
+	return false
+}
+
+func isPasswordKey(key string) bool {
+	return strings.Contains(strings.ToLower(key), "password")
+}
+
+func RecordJobCreated(recorder record.EventRecorder, owner runtime.Object, job *batchv1.Job, secrets ...string) {
+	if recorder == nil {
+		return
+	}
+	recorder.Eventf(owner, corev1.EventTypeNormal, EventJobCreated, "Created job %s: %s", job.Name, JobParams(job, secrets...))
+}
+
+// RecordJobStarted records the start of the job once, the job is annotated
+// so later reconciles do not record it again
+func RecordJobStarted(cli client.Client, reqCtx context.Context, recorder record.EventRecorder, owner runtime.Object, jobName, namespace string) {
+	if recorder == nil {
+		return
+	}
+
+	job := &batchv1.Job{}
+	if err := cli.Get(reqCtx, client.ObjectKey{Namespace: namespace, Name: jobName}, job); err != nil {
+		return
+	}
+	if job.Status.StartTime == nil || !markJob(cli, reqCtx, job, jobStartedAnnotation) {
+		return
+	}
+	recorder.Eventf(owner, corev1.EventTypeNormal, EventJobStarted, "Job %s started", jobName)
+}
+
+// RecordJobFinished records once that the job succeeded or failed, a failed
+// precheck is recorded with the termination message of its pod
+func RecordJobFinished(cli client.Client, reqCtx context.Context, recorder record.EventRecorder, owner runtime.Object, job *batchv1.Job, succeeded bool) {
+	if recorder == nil {
+		return
+	}
+
+	existing := &batchv1.Job{}
+	if err := cli.Get(reqCtx, client.ObjectKeyFromObject(job), existing); err != nil {
+		return
+	}
+	if !markJob(cli, reqCtx, existing, jobFinishedAnnotation) {
+		return
+	}
+
+	if succeeded {
+		recorder.Eventf(owner, corev1.EventTypeNormal, EventJobSucceeded, "Job %s succeeded", job.Name)
+		return
+	}
+
+	reason := EventJobFailed
+	if job.Labels[constants.KubeBenchStepLabel] == constants.PreCheckStep {
+		reason = EventPreCheckFailed
+	}
+	msg := JobTerminationMessage(cli, reqCtx, job.Name, job.Namespace)
+	if msg == "" {
+		recorder.Eventf(owner, corev1.EventTypeWarning, reason, "Job %s failed", job.Name)
+		return
+	}
+	recorder.Eventf(owner, corev1.EventTypeWarning, reason, "Job %s failed: %s", job.Name, msg)
+}
+
+// markJob annotates the job with the annotation, it returns false if the
+// job has been annotated already or the annotation fails
+func markJob(cli client.Client, reqCtx context.Context, job *batchv1.Job, annotation string) bool {
+	if job.Annotations[annotation] != "" {
+		return false
+	}
+
+	patch := client.MergeFrom(job.DeepCopy())
+	if job.Annotations == nil {
+		job.Annotations = make(map[string]string)
+	}
+	job.Annotations[annotation] = "true"
+	if err := cli.Patch(reqCtx, job, patch); err != nil {
+		log.FromContext(reqCtx).Error(err, "failed to annotate job", "job", job.Name, "annotation", annotation)
+		return false
+	}
+	return true
+}
+
+// JobTerminationMessage returns the termination message of the first
+// container of the job's pods that failed
+func JobTerminationMessage(cli client.Client, reqCtx context.Context, jobName, namespace string) string {
+	podList, err := GetPodListFromJob(cli, reqCtx, jobName, namespace)
+	if err != nil {
+		return ""
+	}
+
+	for _, pod := range podList.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			terminated := status.State.Terminated
+			if terminated != nil && terminated.ExitCode != 0 {
+				msg := strings.TrimSpace(terminated.Message)
+				if msg == "" {
+					msg = fmt.Sprintf("container %s exited with %d", status.Name, terminated.ExitCode)
+				}
+				if len(msg) > maxEventParams {
+					msg = "..." + msg[len(msg)-maxEventParams:]
+				}
+				return msg
+			}
+		}
+	}
+	return ""
+}
+
+// RecordPhaseTransition records the change of the phase of the benchmark
+func RecordPhaseTransition(recorder record.EventRecorder, owner runtime.Object, from, to v1alpha1.BenchmarkPhase) {
+	if recorder == nil || from == to {
+		return
+	}
+
+	eventType := corev1.EventTypeNormal
+	if to == v1alpha1.Failed {
+		eventType = corev1.EventTypeWarning
+	}
+	if from == "" {
+		from = v1alpha1.Pending
+	}
+	recorder.Eventf(owner, eventType, EventPhaseTransition, "Phase changed from %s to %s", from, to)
+}
@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	benchmarkv1alpha1 "github.com/apecloud/kubebench/api/v1alpha1"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+func drainEvents(recorder *record.FakeRecorder) []string {
+	events := make([]string, 0)
+	for {
+		select {
+		case event := <-recorder.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestRecordJobEvents(t *testing.T) {
+	target := newTestTarget(constants.MySqlDriver)
+	precheck := NewPreCheckJob("sb", "default", constants.MySqlDriver, &target)
+	if precheck.Labels[constants.KubeBenchStepLabel] != constants.PreCheckStep {
+		t.Fatalf("expected the precheck job to be labelled, got %v", precheck.Labels)
+	}
+	if precheck.Spec.Template.Spec.Containers[0].TerminationMessagePolicy != corev1.TerminationMessageFallbackToLogsOnError {
+		t.Fatal("expected the precheck error to become the termination message")
+	}
+
+	started := JobTemplate("sb-run-0", "default")
+	now := metav1.Now()
+	started.Status.StartTime = &now
+	failedPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "sb-precheck-abc", Namespace: "default", Labels: map[string]string{"job-name": "sb-precheck"}},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			Name:  constants.ContainerName,
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Message: "dial tcp: connection refused\n"}},
+		}}},
+	}
+	cli, _ := newTestClient(precheck, started, failedPod)
+
+	recorder := record.NewFakeRecorder(10)
+	owner := &benchmarkv1alpha1.Sysbench{ObjectMeta: metav1.ObjectMeta{Name: "sb", Namespace: "default"}}
+	ctx := context.Background()
+
+	RecordJobCreated(recorder, owner, precheck, target.Password)
+	RecordJobStarted(cli, ctx, recorder, owner, "sb-run-0", "default")
+	RecordJobStarted(cli, ctx, recorder, owner, "sb-run-0", "default")
+	RecordJobFinished(cli, ctx, recorder, owner, precheck, false)
+	RecordJobFinished(cli, ctx, recorder, owner, started, true)
+	RecordJobFinished(cli, ctx, recorder, owner, started, true)
+	RecordPhaseTransition(recorder, owner, "", benchmarkv1alpha1.Running)
+	RecordPhaseTransition(recorder, owner, benchmarkv1alpha1.Running, benchmarkv1alpha1.Running)
+	RecordPhaseTransition(recorder, owner, benchmarkv1alpha1.Running, benchmarkv1alpha1.Failed)
+
+	events := drainEvents(recorder)
+	want := []string{
+		"Normal JobCreated Created job sb-precheck: /tools mysql ping --user root --password ****** --host",
+		"Normal JobStarted Job sb-run-0 started",
+		"Warning PreCheckFailed Job sb-precheck failed: dial tcp: connection refused",
+		"Normal JobSucceeded Job sb-run-0 succeeded",
+		"Normal PhaseTransition Phase changed from Pending to Running",
+		"Warning PhaseTransition Phase changed from Running to Failed",
+	}
+	if len(events) != len(want) {
+		t.Fatalf("unexpected events: %q", events)
+	}
+	for i := range want {
+		if !strings.HasPrefix(events[i], want[i]) {
+			t.Fatalf("unexpected event %d: %q, want %q", i, events[i], want[i])
+		}
+	}
+
+	job := &batchv1.Job{}
+	if err := cli.Get(ctx, client.ObjectKey{Namespace: "default", Name: "sb-run-0"}, job); err != nil {
+		t.Fatal(err)
+	}
+	if job.Annotations["kubebench.apecloud.io/started"] == "" || job.Annotations["kubebench.apecloud.io/finished"] == "" {
+		t.Fatalf("expected the job to be marked as started and finished, got %v", job.Annotations)
+	}
+
+	// reconcilers without a recorder stay quiet
+	RecordJobCreated(nil, owner, precheck)
+}
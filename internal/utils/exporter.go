@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/apecloud/kubebench/api/v1alpha1"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+// ExporterArgs returns the exporter flags that depend on the benchmark rather
+// than the job: the target label, the driver, the target host and the labels
+// of the benchmark for the run info, dumping the scraped file into the
+// container log when the logs are archived, and the OTLP endpoint
+func ExporterArgs(labels map[string]string, bench *v1alpha1.BenchCommon) []string {
+	args := make([]string, 0)
+	if target := labels[constants.KubeBenchTargetLabel]; target != "" {
+		args = append(args, "-target", target)
+	}
+	args = append(args, ExporterParamArgs(map[string]string{
+		"driver":      bench.Target.Driver,
+		"target_host": bench.Target.Host,
+	})...)
+	for _, name := range sortedKeys(labels) {
+		args = append(args, "-label", fmt.Sprintf("%s=%s", name, labels[name]))
+	}
+	if bench.LogArchive != nil {
+		args = append(args, "-dump-file")
+	}
+	if otlp := bench.OTLP; otlp != nil && otlp.Endpoint != "" {
+		args = append(args, "-otlp-endpoint", otlp.Endpoint)
+		if otlp.Protocol != "" {
+			args = append(args, "-otlp-protocol", otlp.Protocol)
+		}
+		if otlp.Insecure {
+			args = append(args, "-otlp-insecure")
+		}
+		if otlp.IntervalSeconds > 0 {
+			args = append(args, "-otlp-interval", fmt.Sprintf("%ds", otlp.IntervalSeconds))
+		}
+	}
+	return args
+}
+
+// ExporterParamArgs returns the flags of the params of the run job, e.g. the
+// threads and the type of a sysbench job, the empty params are left out
+func ExporterParamArgs(params map[string]string) []string {
+	args := make([]string, 0)
+	for _, name := range sortedKeys(params) {
+		if params[name] != "" {
+			args = append(args, "-param", fmt.Sprintf("%s=%s", name, params[name]))
+		}
+	}
+	return args
+}
+
+// ExporterDurationArgs returns the flag of the duration of the run job in
+// seconds, none if it is unknown
+func ExporterDurationArgs(seconds int) []string {
+	if seconds <= 0 {
+		return nil
+	}
+	return []string{"-duration", fmt.Sprintf("%ds", seconds)}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// NewExporterContainer returns the metrics container that scrapes the file
+// of the run job of the benchmark
+func NewExporterContainer(kind, file, benchName, jobName string, args ...string) corev1.Container {
+	return corev1.Container{
+		Name:            MetricsContainerName,
+		Image:           constants.GetBenchmarkImage(constants.KubebenchExporter),
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Ports: []corev1.ContainerPort{
+			{
+				ContainerPort: 9187,
+				Name:          "http-metrics",
+				Protocol:      corev1.ProtocolTCP,
+			},
+		},
+		Command: []string{"/exporter"},
+		Args: append([]string{
+			"-type", kind, "-file", file, "-bench", benchName, "-job", jobName,
+			"-termination-log", corev1.TerminationMessagePathDefault,
+		}, args...),
+		TerminationMessagePath:   corev1.TerminationMessagePathDefault,
+		TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: "log", MountPath: "/var/log"},
+		},
+	}
+}
@@ -0,0 +1,258 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/apecloud/kubebench/api/v1alpha1"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+// MarkRunJobs labels the jobs that measure the benchmark, faults are only
+// injected into them
+func MarkRunJobs(jobs []*batchv1.Job) []*batchv1.Job {
+	return MarkStepJobs(jobs, constants.RunStep)
+}
+
+// MarkStepJobs labels the jobs with the step they belong to
+func MarkStepJobs(jobs []*batchv1.Job, step string) []*batchv1.Job {
+	AddLabelsToJobs(jobs, map[string]string{constants.KubeBenchStepLabel: step})
+	return jobs
+}
+
+// JobStep returns the step of the job, empty for jobs of no step
+func JobStep(job *batchv1.Job) string {
+	return job.Labels[constants.KubeBenchStepLabel]
+}
+
+// cordonedByAnnotation marks the nodes cordoned by a fault with the
+// <namespace>/<name> of the run job, they are uncordoned once it finished
+const cordonedByAnnotation = "kubebench.apecloud.io/cordoned-by"
+
+func IsRunJob(job *batchv1.Job) bool {
+	return job.Labels[constants.KubeBenchStepLabel] == constants.RunStep
+}
+
+// InjectFaults injects the faults that are due into the running job and
+// records them, every fault is injected once per run job. A fault that can
+// not be injected is recorded as failed and not retried.
+func InjectFaults(cli client.Client, reqCtx context.Context, scheme *runtime.Scheme, owner client.Object, faults []v1alpha1.Fault, job *batchv1.Job, status *batchv1.JobStatus, records *[]v1alpha1.FaultRecord) {
+	if len(faults) == 0 || !IsRunJob(job) || status.StartTime == nil {
+		return
+	}
+
+	l := log.FromContext(reqCtx)
+	for _, fault := range faults {
+		if isFaultRecorded(*records, fault.Name, job.Name) {
+			continue
+		}
+		if time.Since(status.StartTime.Time) < time.Duration(fault.OffsetSeconds)*time.Second {
+			continue
+		}
+
+		record := v1alpha1.FaultRecord{Name: fault.Name, Job: job.Name, Time: metav1.Now()}
+		msg, err := injectFault(cli, reqCtx, scheme, owner, fault, job)
+		if err != nil {
+			l.Error(err, "failed to inject fault", "fault", fault.Name, "job", job.Name)
+			record.Failed = true
+			msg = err.Error()
+		} else {
+			l.Info("injected fault", "fault", fault.Name, "job", job.Name, "message", msg)
+		}
+		record.Message = msg
+		*records = append(*records, record)
+	}
+}
+
+func isFaultRecorded(records []v1alpha1.FaultRecord, name, job string) bool {
+	for _, record := range records {
+		if record.Name == name && record.Job == job {
+			return true
+		}
+	}
+	return false
+}
+
+func injectFault(cli client.Client, reqCtx context.Context, scheme *runtime.Scheme, owner client.Object, fault v1alpha1.Fault, job *batchv1.Job) (string, error) {
+	switch {
+	case fault.DeletePods != nil:
+		return deletePods(cli, reqCtx, owner.GetNamespace(), fault.DeletePods)
+	case fault.ScaleStatefulSet != nil:
+		return scaleStatefulSet(cli, reqCtx, owner.GetNamespace(), fault.ScaleStatefulSet)
+	case fault.CordonNode != nil:
+		return cordonNode(cli, reqCtx, fault.CordonNode.Name, job)
+	case fault.Container != nil:
+		return createFaultJob(cli, reqCtx, scheme, owner, fault, job)
+	default:
+		return "", fmt.Errorf("fault %s has no action", fault.Name)
+	}
+}
+
+// clusterFaultsAllowed returns true if the manager lets faults reach beyond
+// the namespace of the benchmark
+func clusterFaultsAllowed() bool {
+	return viper.GetBool(constants.CfgKeyAllowClusterFaults)
+}
+
+// faultNamespace returns the namespace the fault acts in, the namespace of
+// the benchmark unless the manager allows cluster faults
+func faultNamespace(namespace, faultNamespace string) (string, error) {
+	if faultNamespace == "" || faultNamespace == namespace {
+		return namespace, nil
+	}
+	if !clusterFaultsAllowed() {
+		return "", fmt.Errorf("faults are limited to the namespace %s of the benchmark, the manager does not allow cluster faults", namespace)
+	}
+	return faultNamespace, nil
+}
+
+func deletePods(cli client.Client, reqCtx context.Context, namespace string, fault *v1alpha1.DeletePodsFault) (string, error) {
+	namespace, err := faultNamespace(namespace, fault.Namespace)
+	if err != nil {
+		return "", err
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&fault.Selector)
+	if err != nil {
+		return "", err
+	}
+	if selector.Empty() {
+		return "", fmt.Errorf("refusing to delete pods with an empty selector")
+	}
+
+	pods := &corev1.PodList{}
+	if err := cli.List(reqCtx, pods, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return "", err
+	}
+	if len(pods.Items) == 0 {
+		return "", fmt.Errorf("no pods in %s match %s", namespace, selector)
+	}
+
+	opts := make([]client.DeleteOption, 0)
+	if fault.GracePeriodSeconds != nil {
+		opts = append(opts, client.GracePeriodSeconds(*fault.GracePeriodSeconds))
+	}
+	names := make([]string, 0, len(pods.Items))
+	for i := range pods.Items {
+		if err := cli.Delete(reqCtx, &pods.Items[i], opts...); client.IgnoreNotFound(err) != nil {
+			return "", err
+		}
+		names = append(names, pods.Items[i].Name)
+	}
+	return fmt.Sprintf("deleted pods %s", strings.Join(names, ", ")), nil
+}
+
+func scaleStatefulSet(cli client.Client, reqCtx context.Context, namespace string, fault *v1alpha1.ScaleStatefulSetFault) (string, error) {
+	namespace, err := faultNamespace(namespace, fault.Namespace)
+	if err != nil {
+		return "", err
+	}
+
+	sts := &appsv1.StatefulSet{}
+	if err := cli.Get(reqCtx, client.ObjectKey{Namespace: namespace, Name: fault.Name}, sts); err != nil {
+		return "", err
+	}
+	from := int32(1)
+	if sts.Spec.Replicas != nil {
+		from = *sts.Spec.Replicas
+	}
+
+	patch := client.MergeFrom(sts.DeepCopy())
+	replicas := fault.Replicas
+	sts.Spec.Replicas = &replicas
+	if err := cli.Patch(reqCtx, sts, patch); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("scaled statefulset %s from %d to %d replicas", fault.Name, from, replicas), nil
+}
+
+func cordonNode(cli client.Client, reqCtx context.Context, name string, job *batchv1.Job) (string, error) {
+	if !clusterFaultsAllowed() {
+		return "", fmt.Errorf("the manager does not allow cluster faults, nodes can not be cordoned")
+	}
+
+	node := &corev1.Node{}
+	if err := cli.Get(reqCtx, client.ObjectKey{Name: name}, node); err != nil {
+		return "", err
+	}
+	if node.Spec.Unschedulable {
+		// the node is left as it is when the job finished
+		return fmt.Sprintf("node %s is already cordoned", name), nil
+	}
+
+	patch := client.MergeFrom(node.DeepCopy())
+	node.Spec.Unschedulable = true
+	if node.Annotations == nil {
+		node.Annotations = make(map[string]string)
+	}
+	node.Annotations[cordonedByAnnotation] = job.Namespace + "/" + job.Name
+	if err := cli.Patch(reqCtx, node, patch); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("cordoned node %s", name), nil
+}
+
+// UncordonNodes uncordons the nodes that the faults cordoned during the run
+// job once the job finished
+func UncordonNodes(cli client.Client, reqCtx context.Context, faults []v1alpha1.Fault, job *batchv1.Job, status *batchv1.JobStatus) error {
+	if len(faults) == 0 || !IsRunJob(job) || (status.Succeeded == 0 && status.Failed == 0 && !jobFinished(status)) {
+		return nil
+	}
+
+	for _, fault := range faults {
+		if fault.CordonNode == nil {
+			continue
+		}
+		node := &corev1.Node{}
+		if err := cli.Get(reqCtx, client.ObjectKey{Name: fault.CordonNode.Name}, node); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if node.Annotations[cordonedByAnnotation] != job.Namespace+"/"+job.Name {
+			continue
+		}
+
+		patch := client.MergeFrom(node.DeepCopy())
+		node.Spec.Unschedulable = false
+		delete(node.Annotations, cordonedByAnnotation)
+		if err := cli.Patch(reqCtx, node, patch); err != nil {
+			return err
+		}
+		log.FromContext(reqCtx).Info("uncordoned node", "node", node.Name, "job", job.Name)
+	}
+	return nil
+}
+
+// createFaultJob runs the container next to the run job, it shares the labels
+// and tolerations of the run job
+func createFaultJob(cli client.Client, reqCtx context.Context, scheme *runtime.Scheme, owner client.Object, fault v1alpha1.Fault, job *batchv1.Job) (string, error) {
+	faultJob := NewContainerJob(fmt.Sprintf("%s-fault-%s", job.Name, fault.Name), job.Namespace, fault.Container)
+	faultJob.Spec.Template.Spec.Tolerations = job.Spec.Template.Spec.Tolerations
+	AddLabelsToJobs([]*batchv1.Job{faultJob}, map[string]string{
+		constants.KubeBenchNameLabel:  job.Labels[constants.KubeBenchNameLabel],
+		constants.KubeBenchTypeLabel:  job.Labels[constants.KubeBenchTypeLabel],
+		constants.KubeBenchFaultLabel: fault.Name,
+	})
+
+	if err := controllerutil.SetOwnerReference(owner, faultJob, scheme); err != nil {
+		return "", err
+	}
+	if err := cli.Create(reqCtx, faultJob); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("created job %s", faultJob.Name), nil
+}
@@ -0,0 +1,176 @@
+package utils
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	benchmarkv1alpha1 "github.com/apecloud/kubebench/api/v1alpha1"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+func TestInjectFaults(t *testing.T) {
+	viper.Set(constants.CfgKeyAllowClusterFaults, "true")
+	defer viper.Set(constants.CfgKeyAllowClusterFaults, "")
+
+	replicas := int32(3)
+	cli, scheme := newTestClient(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "mysql-0", Namespace: "db", Labels: map[string]string{"role": "primary"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "mysql-1", Namespace: "db", Labels: map[string]string{"role": "secondary"}}},
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "mysql", Namespace: "default"}, Spec: appsv1.StatefulSetSpec{Replicas: &replicas}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+	)
+
+	owner := &benchmarkv1alpha1.Sysbench{ObjectMeta: metav1.ObjectMeta{Name: "sb", Namespace: "default", UID: "uid"}}
+	faults := []benchmarkv1alpha1.Fault{
+		{Name: "kill-primary", OffsetSeconds: 10, DeletePods: &benchmarkv1alpha1.DeletePodsFault{
+			Namespace: "db",
+			Selector:  metav1.LabelSelector{MatchLabels: map[string]string{"role": "primary"}},
+		}},
+		{Name: "scale-in", OffsetSeconds: 20, ScaleStatefulSet: &benchmarkv1alpha1.ScaleStatefulSetFault{Name: "mysql", Replicas: 1}},
+		{Name: "cordon", OffsetSeconds: 20, CordonNode: &benchmarkv1alpha1.CordonNodeFault{Name: "node-2"}},
+		{Name: "chaos", OffsetSeconds: 20, Container: &benchmarkv1alpha1.ContainerHook{Image: "chaos:latest"}},
+		{Name: "later", OffsetSeconds: 600, CordonNode: &benchmarkv1alpha1.CordonNodeFault{Name: "node-1"}},
+	}
+
+	job := MarkRunJobs([]*batchv1.Job{JobTemplate("sb-run-0", "default")})[0]
+	job.Labels[constants.KubeBenchNameLabel] = "sb"
+	status := &batchv1.JobStatus{StartTime: &metav1.Time{Time: time.Now().Add(-30 * time.Second)}}
+	records := make([]benchmarkv1alpha1.FaultRecord, 0)
+
+	ctx := context.Background()
+	InjectFaults(cli, ctx, scheme, owner, faults, job, status, &records)
+	// every fault is injected once per run job
+	InjectFaults(cli, ctx, scheme, owner, faults, job, status, &records)
+
+	if len(records) != 4 {
+		t.Fatalf("expected the 4 due faults to be recorded, got %+v", records)
+	}
+	for i, want := range []struct {
+		name   string
+		failed bool
+	}{{"kill-primary", false}, {"scale-in", false}, {"cordon", true}, {"chaos", false}} {
+		if records[i].Name != want.name || records[i].Job != "sb-run-0" || records[i].Failed != want.failed || records[i].Time.IsZero() {
+			t.Fatalf("unexpected record %d: %+v", i, records[i])
+		}
+	}
+
+	pods := &corev1.PodList{}
+	if err := cli.List(ctx, pods, client.InNamespace("db")); err != nil {
+		t.Fatal(err)
+	}
+	if len(pods.Items) != 1 || pods.Items[0].Name != "mysql-1" {
+		t.Fatalf("expected only the primary to be deleted, got %v", pods.Items)
+	}
+
+	sts := &appsv1.StatefulSet{}
+	if err := cli.Get(ctx, client.ObjectKey{Namespace: "default", Name: "mysql"}, sts); err != nil {
+		t.Fatal(err)
+	}
+	if *sts.Spec.Replicas != 1 {
+		t.Fatalf("expected the statefulset to be scaled to 1, got %d", *sts.Spec.Replicas)
+	}
+
+	faultJob := &batchv1.Job{}
+	if err := cli.Get(ctx, client.ObjectKey{Namespace: "default", Name: "sb-run-0-fault-chaos"}, faultJob); err != nil {
+		t.Fatal(err)
+	}
+	if faultJob.Labels[constants.KubeBenchNameLabel] != "sb" || len(faultJob.OwnerReferences) != 1 {
+		t.Fatalf("expected the fault job to belong to the benchmark, got %+v", faultJob.ObjectMeta)
+	}
+
+	// jobs that are not marked as run jobs never get faults
+	records = records[:0]
+	InjectFaults(cli, ctx, scheme, owner, faults, JobTemplate("sb-prepare", "default"), status, &records)
+	if len(records) != 0 {
+		t.Fatalf("expected no faults outside the run jobs, got %+v", records)
+	}
+}
+
+func TestInjectFaultsStayInNamespace(t *testing.T) {
+	cli, scheme := newTestClient(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "mysql-0", Namespace: "db", Labels: map[string]string{"role": "primary"}}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+	)
+	owner := &benchmarkv1alpha1.Sysbench{ObjectMeta: metav1.ObjectMeta{Name: "sb", Namespace: "default", UID: "uid"}}
+	faults := []benchmarkv1alpha1.Fault{
+		{Name: "kill-primary", DeletePods: &benchmarkv1alpha1.DeletePodsFault{
+			Namespace: "db",
+			Selector:  metav1.LabelSelector{MatchLabels: map[string]string{"role": "primary"}},
+		}},
+		{Name: "scale-in", ScaleStatefulSet: &benchmarkv1alpha1.ScaleStatefulSetFault{Namespace: "db", Name: "mysql", Replicas: 1}},
+		{Name: "cordon", CordonNode: &benchmarkv1alpha1.CordonNodeFault{Name: "node-1"}},
+	}
+
+	job := MarkRunJobs([]*batchv1.Job{JobTemplate("sb-run-0", "default")})[0]
+	status := &batchv1.JobStatus{StartTime: &metav1.Time{Time: time.Now()}}
+	records := make([]benchmarkv1alpha1.FaultRecord, 0)
+	ctx := context.Background()
+	InjectFaults(cli, ctx, scheme, owner, faults, job, status, &records)
+
+	for _, record := range records {
+		if !record.Failed || !strings.Contains(record.Message, "cluster faults") {
+			t.Fatalf("expected the fault to be refused without cluster faults, got %+v", record)
+		}
+	}
+	if err := cli.Get(ctx, client.ObjectKey{Namespace: "db", Name: "mysql-0"}, &corev1.Pod{}); err != nil {
+		t.Fatalf("expected the pod of the other namespace to be kept, got %v", err)
+	}
+	node := &corev1.Node{}
+	if err := cli.Get(ctx, client.ObjectKey{Name: "node-1"}, node); err != nil || node.Spec.Unschedulable {
+		t.Fatalf("expected the node to stay schedulable, got %v %v", node.Spec.Unschedulable, err)
+	}
+}
+
+func TestUncordonNodes(t *testing.T) {
+	viper.Set(constants.CfgKeyAllowClusterFaults, "true")
+	defer viper.Set(constants.CfgKeyAllowClusterFaults, "")
+
+	cli, scheme := newTestClient(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}, Spec: corev1.NodeSpec{Unschedulable: true}},
+	)
+	owner := &benchmarkv1alpha1.Sysbench{ObjectMeta: metav1.ObjectMeta{Name: "sb", Namespace: "default", UID: "uid"}}
+	faults := []benchmarkv1alpha1.Fault{
+		{Name: "cordon", CordonNode: &benchmarkv1alpha1.CordonNodeFault{Name: "node-1"}},
+		{Name: "cordoned", CordonNode: &benchmarkv1alpha1.CordonNodeFault{Name: "node-2"}},
+	}
+	job := MarkRunJobs([]*batchv1.Job{JobTemplate("sb-run-0", "default")})[0]
+	status := &batchv1.JobStatus{StartTime: &metav1.Time{Time: time.Now()}}
+	records := make([]benchmarkv1alpha1.FaultRecord, 0)
+	ctx := context.Background()
+	InjectFaults(cli, ctx, scheme, owner, faults, job, status, &records)
+
+	unschedulable := func(name string) bool {
+		node := &corev1.Node{}
+		if err := cli.Get(ctx, client.ObjectKey{Name: name}, node); err != nil {
+			t.Fatal(err)
+		}
+		return node.Spec.Unschedulable
+	}
+	if err := UncordonNodes(cli, ctx, faults, job, status); err != nil {
+		t.Fatal(err)
+	}
+	if !unschedulable("node-1") {
+		t.Fatal("expected the node to stay cordoned while the job runs")
+	}
+
+	status.Failed = 1
+	if err := UncordonNodes(cli, ctx, faults, job, status); err != nil {
+		t.Fatal(err)
+	}
+	if unschedulable("node-1") {
+		t.Fatal("expected the node to be uncordoned once the job failed")
+	}
+	if !unschedulable("node-2") {
+		t.Fatal("expected the node that was cordoned before the fault to stay cordoned")
+	}
+}
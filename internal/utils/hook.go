@@ -0,0 +1,152 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/apecloud/kubebench/api/v1alpha1"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+// NewHookJobs create a job for every hook of the phase, the jobs are named
+// <name>-<phase>-<hook name> and keep the order of the hooks
+func NewHookJobs(name, namespace, phase string, hooks *v1alpha1.Hooks, target *v1alpha1.Target) []*batchv1.Job {
+	jobs := make([]*batchv1.Job, 0)
+	for _, hook := range phaseHooks(hooks, phase) {
+		jobName := fmt.Sprintf("%s-%s-%s", name, phase, hook.Name)
+
+		var job *batchv1.Job
+		switch {
+		case hook.Container != nil:
+			job = NewContainerJob(jobName, namespace, hook.Container)
+		case hook.SQL != nil:
+			job = newSQLHookJob(jobName, namespace, hook.SQL, target)
+		case hook.HTTP != nil:
+			job = newHTTPHookJob(jobName, namespace, hook.HTTP)
+		}
+		if job == nil {
+			klog.Errorf("Skip hook %s of %s, it has no action or the target driver %s does not support it", hook.Name, name, target.Driver)
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+
+	AddLabelsToJobs(jobs, map[string]string{constants.KubeBenchHookLabel: phase})
+	return MarkStepJobs(jobs, constants.HookStep)
+}
+
+// FailJob returns the phase of the benchmark whose current job failed. The
+// benchmark skips ahead to the afterAll hooks that did not run yet and stays
+// Running, it fails once they finished.
+func FailJob(jobs []*batchv1.Job, succeeded *int) v1alpha1.BenchmarkPhase {
+	for i := *succeeded + 1; i < len(jobs); i++ {
+		if isAfterAllHook(jobs[i]) {
+			*succeeded = i
+			return v1alpha1.Running
+		}
+	}
+	return v1alpha1.Failed
+}
+
+// isAfterAllHook returns true if the job runs an afterAll hook
+func isAfterAllHook(job *batchv1.Job) bool {
+	return job.Labels[constants.KubeBenchHookLabel] == constants.AfterAllHook
+}
+
+func phaseHooks(hooks *v1alpha1.Hooks, phase string) []v1alpha1.Hook {
+	if hooks == nil {
+		return nil
+	}
+
+	switch phase {
+	case constants.BeforePrepareHook:
+		return hooks.BeforePrepare
+	case constants.AfterPrepareHook:
+		return hooks.AfterPrepare
+	case constants.BeforeRunHook:
+		return hooks.BeforeRun
+	case constants.AfterRunHook:
+		return hooks.AfterRun
+	case constants.AfterAllHook:
+		return hooks.AfterAll
+	default:
+		return nil
+	}
+}
+
+// NewContainerJob create a job running the container of a hook or fault
+func NewContainerJob(name, namespace string, hook *v1alpha1.ContainerHook) *batchv1.Job {
+	job := JobTemplate(name, namespace)
+	job.Spec.Template.Spec.Containers = append(
+		job.Spec.Template.Spec.Containers,
+		corev1.Container{
+			Name:            constants.ContainerName,
+			Image:           hook.Image,
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Command:         hook.Command,
+			Args:            hook.Args,
+			Env:             hook.Env,
+		},
+	)
+	return job
+}
+
+func newSQLHookJob(name, namespace string, hook *v1alpha1.SQLHook, target *v1alpha1.Target) *batchv1.Job {
+	database := hook.Database
+	if database == "" {
+		database = target.Database
+	}
+
+	args := make([]string, 0)
+	if hook.Transaction != "" {
+		args = append(args, "--transaction", hook.Transaction)
+	}
+	if hook.ContinueOnError {
+		args = append(args, "--continue-on-error")
+	}
+
+	return NewSQLExecJob(name, namespace, target, database, hook.ConfigMap, args...)
+}
+
+func newHTTPHookJob(name, namespace string, hook *v1alpha1.HTTPHook) *batchv1.Job {
+	args := []string{"http", "--url", hook.URL}
+	if hook.Method != "" {
+		args = append(args, "--method", hook.Method)
+	}
+	// sort the headers to keep the job spec stable
+	headers := make([]string, 0, len(hook.Headers))
+	for k := range hook.Headers {
+		headers = append(headers, k)
+	}
+	sort.Strings(headers)
+	for _, k := range headers {
+		args = append(args, "--header", fmt.Sprintf("%s: %s", k, hook.Headers[k]))
+	}
+	if hook.Body != "" {
+		args = append(args, "--body", hook.Body)
+	}
+	if hook.TimeoutSeconds > 0 {
+		args = append(args, "--timeout", strconv.Itoa(hook.TimeoutSeconds)+"s")
+	}
+	if hook.InsecureSkipVerify {
+		args = append(args, "--insecure-skip-verify")
+	}
+
+	job := JobTemplate(name, namespace)
+	job.Spec.Template.Spec.Containers = append(
+		job.Spec.Template.Spec.Containers,
+		corev1.Container{
+			Name:            constants.ContainerName,
+			Image:           constants.GetBenchmarkImage(constants.KubebenchTools),
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Command:         []string{"/tools"},
+			Args:            args,
+		},
+	)
+	return job
+}
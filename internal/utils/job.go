@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/spf13/viper"
@@ -425,8 +426,46 @@ func NewGaussdbPreCheckJob(name string, namespace string, target v1alpha1.Target
 	return job
 }
 
-// NewPreCheckJob create a job to check the connection
+// newToolsPreCheckJob create a job that pings the target with the tools
+// subcommand of the given driver
+func newToolsPreCheckJob(name, namespace, tool string, target v1alpha1.Target) *batchv1.Job {
+	job := JobTemplate(fmt.Sprintf("%s-precheck", name), namespace)
+	job.Spec.Template.Spec.Containers = append(
+		job.Spec.Template.Spec.Containers,
+		corev1.Container{
+			Name:            constants.ContainerName,
+			Image:           constants.GetBenchmarkImage(constants.KubebenchTools),
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Command:         []string{"/tools"},
+			Args: []string{tool, "ping",
+				"--host", target.Host,
+				"--port", fmt.Sprintf("%d", target.Port),
+				"--user", target.User,
+				"--password", target.Password,
+			},
+		},
+	)
+
+	return job
+}
+
+// NewPreCheckJob create a job to check the connection, the job is labelled
+// with the precheck step and the error of the check becomes the termination
+// message of its pod
 func NewPreCheckJob(name, namespace string, driver string, target *v1alpha1.Target) *batchv1.Job {
+	job := newPreCheckJob(name, namespace, driver, target)
+	if job == nil {
+		return nil
+	}
+
+	AddLabelsToJobs([]*batchv1.Job{job}, map[string]string{constants.KubeBenchStepLabel: constants.PreCheckStep})
+	for i := range job.Spec.Template.Spec.Containers {
+		job.Spec.Template.Spec.Containers[i].TerminationMessagePolicy = corev1.TerminationMessageFallbackToLogsOnError
+	}
+	return job
+}
+
+func newPreCheckJob(name, namespace string, driver string, target *v1alpha1.Target) *batchv1.Job {
 	switch driver {
 	case constants.MySqlDriver:
 		return NewMysqlPreCheckJob(name, namespace, *target)
@@ -438,6 +477,9 @@ func NewPreCheckJob(name, namespace string, driver string, target *v1alpha1.Targ
 		return NewMongodbPreCheckJob(name, namespace, *target)
 	case constants.ElasticsearchDriver:
 		return NewElasticsearchPreCheckJob(name, namespace, *target)
+	case constants.TidbDriver, constants.MssqlDriver, constants.OceanBaseOracleTenantDriver:
+		return newToolsPreCheckJob(name, namespace, driver, *target)
+	// dameng has no precheck, the tools have no Dameng driver to log in with
 	// TODO: achieve in next kubebench version
 	//case constants.RedisDriver:
 	//	return NewRedisPreCheckJob(name, namespace, *target)
@@ -445,3 +487,114 @@ func NewPreCheckJob(name, namespace string, driver string, target *v1alpha1.Targ
 		return nil
 	}
 }
+
+// NewVerifyJob create a job to verify the prepared data, expect maps every
+// table (or collection) to the number of rows it should hold after prepare
+func NewVerifyJob(name, namespace string, driver string, target *v1alpha1.Target, database string, expect map[string]int64) *batchv1.Job {
+	tool := getToolsDriver(driver)
+	if tool == "" || len(expect) == 0 {
+		return nil
+	}
+
+	args := []string{tool, "verify",
+		"--user", target.User,
+		"--password", target.Password,
+		"--host", target.Host,
+		"--port", fmt.Sprintf("%d", target.Port),
+		"--database", database,
+	}
+
+	// keep the args stable so the job spec does not change between reconciles
+	tables := make([]string, 0, len(expect))
+	for table := range expect {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		args = append(args, "--expect", fmt.Sprintf("%s=%d", table, expect[table]))
+	}
+
+	job := JobTemplate(fmt.Sprintf("%s-verify", name), namespace)
+	job.Spec.Template.Spec.Containers = append(
+		job.Spec.Template.Spec.Containers,
+		corev1.Container{
+			Name:            constants.ContainerName,
+			Image:           constants.GetBenchmarkImage(constants.KubebenchTools),
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Command:         []string{"/tools"},
+			Args:            args,
+		},
+	)
+
+	return job
+}
+
+// SQLScriptMountPath is where NewSQLExecJob mounts the script ConfigMap
+const SQLScriptMountPath = "/etc/kubebench/sql"
+
+// NewSQLExecJob create a job running every *.sql file of the ConfigMap against
+// the target with `tools sql exec`, extra args such as --transaction are passed
+// through. nil is returned for drivers the sql tool can not handle.
+func NewSQLExecJob(name, namespace string, target *v1alpha1.Target, database, configMap string, extraArgs ...string) *batchv1.Job {
+	switch target.Driver {
+	case constants.MySqlDriver, constants.TidbDriver, constants.PostgreSqlDriver, constants.GaussDBDriver:
+	default:
+		return nil
+	}
+
+	args := []string{"sql", "exec",
+		"--driver", target.Driver,
+		"--user", target.User,
+		"--password", target.Password,
+		"--host", target.Host,
+		"--port", fmt.Sprintf("%d", target.Port),
+		"--database", database,
+		"--dir", SQLScriptMountPath,
+	}
+	args = append(args, extraArgs...)
+
+	job := JobTemplate(name, namespace)
+	job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, corev1.Volume{
+		Name: "sql",
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: configMap},
+			},
+		},
+	})
+	job.Spec.Template.Spec.Containers = append(
+		job.Spec.Template.Spec.Containers,
+		corev1.Container{
+			Name:            constants.ContainerName,
+			Image:           constants.GetBenchmarkImage(constants.KubebenchTools),
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Command:         []string{"/tools"},
+			Args:            args,
+			VolumeMounts: []corev1.VolumeMount{
+				{Name: "sql", MountPath: SQLScriptMountPath, ReadOnly: true},
+			},
+		},
+	)
+
+	return job
+}
+
+// getToolsDriver returns the tools subcommand that handles the driver
+func getToolsDriver(driver string) string {
+	switch driver {
+	case constants.MySqlDriver:
+		return "mysql"
+	case constants.PostgreSqlDriver:
+		return "postgresql"
+	case constants.GaussDBDriver:
+		return "gaussdb"
+	case constants.MongoDbDriver:
+		return "mongodb"
+	case constants.TidbDriver:
+		return "tidb"
+	case constants.MssqlDriver:
+		return "mssql"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,234 @@
+package utils
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/apecloud/kubebench/api/v1alpha1"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+// the reasons of kubebench_benchmark_failures_total
+const (
+	FailureJobFailed      = "JobFailed"
+	FailurePreCheckFailed = "PreCheckFailed"
+)
+
+var durationBuckets = prometheus.ExponentialBuckets(1, 2, 16)
+
+var (
+	jobDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kubebench_job_duration_seconds",
+		Help:    "Duration of the benchmark jobs from start to completion",
+		Buckets: durationBuckets,
+	}, []string{"kind", "step", "result"})
+
+	stepDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kubebench_step_duration_seconds",
+		Help:    "Duration of the benchmark steps from the start of their first job to the completion of their last job",
+		Buckets: durationBuckets,
+	}, []string{"kind", "step"})
+
+	preCheckDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kubebench_precheck_duration_seconds",
+		Help:    "Time spent in the precheck of the benchmarks",
+		Buckets: prometheus.ExponentialBuckets(0.5, 2, 12),
+	}, []string{"kind"})
+
+	queueWait = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kubebench_job_queue_wait_seconds",
+		Help:    "Time from the creation of a benchmark job until its pod was scheduled",
+		Buckets: prometheus.ExponentialBuckets(0.5, 2, 14),
+	}, []string{"kind", "step"})
+
+	failures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kubebench_benchmark_failures_total",
+		Help: "Number of failed benchmarks by reason",
+	}, []string{"kind", "reason"})
+
+	benchmarksDesc = prometheus.NewDesc("kubebench_benchmarks", "Number of benchmarks by kind and phase", []string{"kind", "phase"}, nil)
+)
+
+func init() {
+	metrics.Registry.MustRegister(jobDuration, stepDuration, preCheckDuration, queueWait, failures)
+}
+
+// ObserveJobFinished observes once the duration and queue wait of
+// jobs[index], the duration of its step if it is the last job of the step and
+// the failure if the job failed
+func ObserveJobFinished(cli client.Client, reqCtx context.Context, kind string, jobs []*batchv1.Job, index int, succeeded bool) {
+	l := log.FromContext(reqCtx)
+	job := &batchv1.Job{}
+	if err := cli.Get(reqCtx, client.ObjectKeyFromObject(jobs[index]), job); err != nil {
+		l.Error(err, "failed to get job for metrics", "job", jobs[index].Name)
+		return
+	}
+	if !markJob(cli, reqCtx, job, jobObservedAnnotation) {
+		return
+	}
+	step := JobStep(job)
+	stepName := step
+	if stepName == "" {
+		stepName = "other"
+	}
+
+	result := "succeeded"
+	if !succeeded {
+		result = "failed"
+		reason := FailureJobFailed
+		if step == constants.PreCheckStep {
+			reason = FailurePreCheckFailed
+		}
+		FailureCounter(kind, reason).Inc()
+	}
+
+	if d, ok := jobRunTime(job); ok {
+		jobDuration.WithLabelValues(kind, stepName, result).Observe(d.Seconds())
+		if step == constants.PreCheckStep {
+			preCheckDuration.WithLabelValues(kind).Observe(d.Seconds())
+		}
+	}
+
+	if pods, err := GetPodListFromJob(cli, reqCtx, job.Name, job.Namespace); err == nil {
+		for _, pod := range pods.Items {
+			for _, cond := range pod.Status.Conditions {
+				if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionTrue {
+					queueWait.WithLabelValues(kind, stepName).Observe(cond.LastTransitionTime.Sub(job.CreationTimestamp.Time).Seconds())
+				}
+			}
+		}
+	}
+
+	// the step ends with its last job, or with a failed job
+	if step == "" || step == constants.HookStep {
+		return
+	}
+	if succeeded && index+1 < len(jobs) && JobStep(jobs[index+1]) == step {
+		return
+	}
+	observeStep(cli, reqCtx, kind, job, step)
+}
+
+// FailureCounter returns the counter of the failed benchmarks of the kind
+// for the reason
+func FailureCounter(kind, reason string) prometheus.Counter {
+	return failures.WithLabelValues(kind, reason)
+}
+
+func observeStep(cli client.Client, reqCtx context.Context, kind string, job *batchv1.Job, step string) {
+	stepJobs := &batchv1.JobList{}
+	if err := cli.List(reqCtx, stepJobs, client.InNamespace(job.Namespace), client.MatchingLabels{
+		constants.KubeBenchNameLabel: job.Labels[constants.KubeBenchNameLabel],
+		constants.KubeBenchStepLabel: step,
+	}); err != nil {
+		return
+	}
+
+	var start, end time.Time
+	for i := range stepJobs.Items {
+		j := &stepJobs.Items[i]
+		if j.Status.StartTime != nil && (start.IsZero() || j.Status.StartTime.Time.Before(start)) {
+			start = j.Status.StartTime.Time
+		}
+		if finished := jobFinishTime(j); finished.After(end) {
+			end = finished
+		}
+	}
+	if !start.IsZero() && end.After(start) {
+		stepDuration.WithLabelValues(kind, step).Observe(end.Sub(start).Seconds())
+	}
+}
+
+func jobRunTime(job *batchv1.Job) (time.Duration, bool) {
+	if job.Status.StartTime == nil {
+		return 0, false
+	}
+	end := jobFinishTime(job)
+	if end.IsZero() {
+		return 0, false
+	}
+	return end.Sub(job.Status.StartTime.Time), true
+}
+
+// jobFinishTime returns the completion time of a succeeded job, or the time
+// a failed job was marked as failed
+func jobFinishTime(job *batchv1.Job) time.Time {
+	if job.Status.CompletionTime != nil {
+		return job.Status.CompletionTime.Time
+	}
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == corev1.ConditionTrue {
+			return cond.LastTransitionTime.Time
+		}
+	}
+	return time.Time{}
+}
+
+// BenchmarkCollector counts the benchmarks of every kind of the benchmark API
+// by phase when it is scraped
+type BenchmarkCollector struct {
+	cli    client.Reader
+	scheme *runtime.Scheme
+}
+
+func NewBenchmarkCollector(cli client.Reader, scheme *runtime.Scheme) *BenchmarkCollector {
+	return &BenchmarkCollector{cli: cli, scheme: scheme}
+}
+
+func (c *BenchmarkCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- benchmarksDesc
+}
+
+func (c *BenchmarkCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for gvk := range c.scheme.AllKnownTypes() {
+		if gvk.GroupVersion() != v1alpha1.GroupVersion || !strings.HasSuffix(gvk.Kind, "List") {
+			continue
+		}
+		obj, err := c.scheme.New(gvk)
+		if err != nil {
+			continue
+		}
+		list, ok := obj.(client.ObjectList)
+		if !ok || c.cli.List(ctx, list) != nil {
+			continue
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			continue
+		}
+
+		counts := map[v1alpha1.BenchmarkPhase]int{v1alpha1.Pending: 0, v1alpha1.Running: 0, v1alpha1.Completed: 0, v1alpha1.Failed: 0}
+		for _, item := range items {
+			counts[benchmarkPhase(item)]++
+		}
+		kind := strings.ToLower(strings.TrimSuffix(gvk.Kind, "List"))
+		for phase, count := range counts {
+			ch <- prometheus.MustNewConstMetric(benchmarksDesc, prometheus.GaugeValue, float64(count), kind, string(phase))
+		}
+	}
+}
+
+func benchmarkPhase(obj runtime.Object) v1alpha1.BenchmarkPhase {
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return v1alpha1.Pending
+	}
+	status, _ := object["status"].(map[string]interface{})
+	if phase, _ := status["phase"].(string); phase != "" {
+		return v1alpha1.BenchmarkPhase(phase)
+	}
+	return v1alpha1.Pending
+}
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	benchmarkv1alpha1 "github.com/apecloud/kubebench/api/v1alpha1"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+func TestBenchmarkCollector(t *testing.T) {
+	cli, scheme := newTestClient(
+		&benchmarkv1alpha1.Sysbench{ObjectMeta: metav1.ObjectMeta{Name: "new", Namespace: "default"}},
+		&benchmarkv1alpha1.Sysbench{ObjectMeta: metav1.ObjectMeta{Name: "done", Namespace: "default"}, Status: benchmarkv1alpha1.SysbenchStatus{Phase: benchmarkv1alpha1.Completed}},
+		&benchmarkv1alpha1.Pgbench{ObjectMeta: metav1.ObjectMeta{Name: "pg", Namespace: "default"}, Status: benchmarkv1alpha1.PgbenchStatus{Phase: benchmarkv1alpha1.Running}},
+	)
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(NewBenchmarkCollector(cli, scheme))
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]float64)
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			labels := make(map[string]string)
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			got[labels["kind"]+"/"+labels["phase"]] = m.GetGauge().GetValue()
+		}
+	}
+	want := map[string]float64{
+		"sysbench/Pending":   1,
+		"sysbench/Completed": 1,
+		"sysbench/Running":   0,
+		"pgbench/Running":    1,
+		"pgbench/Failed":     0,
+		"tpcc/Pending":       0,
+	}
+	for key, value := range want {
+		if v, ok := got[key]; !ok || v != value {
+			t.Fatalf("expected %s to be %v, got %v", key, value, got)
+		}
+	}
+}
+
+func TestObserveJobFinished(t *testing.T) {
+	start := metav1.NewTime(time.Now().Add(-time.Minute))
+	end := metav1.NewTime(start.Add(30 * time.Second))
+	newJob := func(name, step string) *batchv1.Job {
+		job := JobTemplate(name, "default")
+		job.Labels = map[string]string{constants.KubeBenchNameLabel: "sb", constants.KubeBenchStepLabel: step}
+		job.CreationTimestamp = start
+		job.Status.StartTime = &start
+		job.Status.CompletionTime = &end
+		return job
+	}
+	precheck := newJob("sb-precheck", constants.PreCheckStep)
+	run0 := newJob("sb-run-0", constants.RunStep)
+	run1 := newJob("sb-run-1", constants.RunStep)
+	run1.Status.CompletionTime = nil
+	run1.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, LastTransitionTime: end}}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "sb-precheck-abc", Namespace: "default", Labels: map[string]string{"job-name": "sb-precheck"}},
+		Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{
+			Type: corev1.PodScheduled, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(start.Add(2 * time.Second)),
+		}}},
+	}
+	cli, _ := newTestClient(precheck, run0, run1, pod)
+	jobs := []*batchv1.Job{precheck, run0, run1}
+	ctx := context.Background()
+
+	count := func(name string) int {
+		n, err := testutil.GatherAndCount(metrics.Registry, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	failures := func() float64 {
+		return testutil.ToFloat64(FailureCounter(constants.SysbenchType, FailureJobFailed))
+	}
+	before := failures()
+
+	ObserveJobFinished(cli, ctx, constants.SysbenchType, jobs, 0, true)
+	if count("kubebench_precheck_duration_seconds") == 0 || count("kubebench_job_queue_wait_seconds") == 0 {
+		t.Fatal("expected the precheck and its queue wait to be observed")
+	}
+
+	// the step is not over until its last job finished
+	ObserveJobFinished(cli, ctx, constants.SysbenchType, jobs, 1, true)
+	if n := count("kubebench_step_duration_seconds"); n != 1 {
+		t.Fatalf("expected only the precheck step to be observed, got %d series", n)
+	}
+
+	ObserveJobFinished(cli, ctx, constants.SysbenchType, jobs, 2, false)
+	if n := count("kubebench_step_duration_seconds"); n != 2 {
+		t.Fatalf("expected the run step to be observed, got %d series", n)
+	}
+	if failures() != before+1 {
+		t.Fatal("expected the failure to be counted")
+	}
+
+	// a requeued reconcile does not count the job again
+	ObserveJobFinished(cli, ctx, constants.SysbenchType, jobs, 2, false)
+	if failures() != before+1 {
+		t.Fatal("expected the failure to be counted once")
+	}
+}
@@ -18,6 +18,8 @@ package utils
 
 import (
 	"context"
+	"path"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	corev1 "k8s.io/api/core/v1"
@@ -25,7 +27,21 @@ import (
 	"k8s.io/client-go/rest"
 )
 
+type podLogsKey struct{}
+
+// WithPodLogs returns a context that keeps the logs fetched with it, the
+// summary and the archive of a job then fetch the log of a container once
+func WithPodLogs(ctx context.Context) context.Context {
+	return context.WithValue(ctx, podLogsKey{}, make(map[string]string))
+}
+
 func GetLogFromPod(rsc *rest.Config, reqCtx context.Context, podName string, namespace string) (string, error) {
+	return GetContainerLogFromPod(rsc, reqCtx, podName, namespace, "")
+}
+
+// GetContainerLogFromPod get the log of the container, the kubebench
+// container or the first container if the container is empty
+func GetContainerLogFromPod(rsc *rest.Config, reqCtx context.Context, podName string, namespace string, container string) (string, error) {
 	clientset, err := corev1client.NewForConfig(rsc)
 	if err != nil {
 		return "", err
@@ -33,22 +49,31 @@ func GetLogFromPod(rsc *rest.Config, reqCtx context.Context, podName string, nam
 
 	// get log like kubeclt logs -f
 	logOptions := &corev1.PodLogOptions{
-		Follow: true,
+		Follow:    true,
+		Container: container,
 	}
 
 	// if don't have container name, get log from first container
-	pod, err := clientset.Pods(namespace).Get(reqCtx, podName, metav1.GetOptions{})
-	if err != nil {
-		return "", err
-	}
-	for _, container := range pod.Spec.Containers {
-		if container.Name == "kubebench" {
-			logOptions.Container = container.Name
-			break
+	if logOptions.Container == "" {
+		pod, err := clientset.Pods(namespace).Get(reqCtx, podName, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		for _, container := range pod.Spec.Containers {
+			if container.Name == "kubebench" {
+				logOptions.Container = container.Name
+				break
+			}
+		}
+		if logOptions.Container == "" {
+			logOptions.Container = pod.Spec.Containers[0].Name
 		}
 	}
-	if logOptions.Container == "" {
-		logOptions.Container = pod.Spec.Containers[0].Name
+
+	logs, _ := reqCtx.Value(podLogsKey{}).(map[string]string)
+	key := path.Join(namespace, podName, logOptions.Container)
+	if msg, ok := logs[key]; ok {
+		return msg, nil
 	}
 
 	req := clientset.Pods(namespace).GetLogs(podName, logOptions)
@@ -58,5 +83,8 @@ func GetLogFromPod(rsc *rest.Config, reqCtx context.Context, podName string, nam
 		return "", err
 	}
 
+	if logs != nil {
+		logs[key] = string(data)
+	}
 	return string(data), nil
 }
@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/apecloud/kubebench/internal/exporter"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+// MetricsContainerName is the name of the exporter container of the run jobs
+const MetricsContainerName = "metrics"
+
+// ReadResult reads the result the exporter wrote to the termination message
+// of the metrics container of the job, it returns nil if there is none
+func ReadResult(cli client.Client, reqCtx context.Context, jobName, namespace string) (*exporter.Result, error) {
+	podList, err := GetPodListFromJob(cli, reqCtx, jobName, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pod := range podList.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name != MetricsContainerName || status.State.Terminated == nil {
+				continue
+			}
+			// the exporters of older images leave no result
+			if result, err := exporter.ParseResult([]byte(status.State.Terminated.Message)); err == nil {
+				return result, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// RecordJobSummary records the summary of the job that succeeded in the
+// conditions. The summary is the message of the result in the termination
+// message of the exporter, the log of the job is only downloaded and
+// summarized when the job has no result or its message did not fit.
+func RecordJobSummary(cli client.Client, restConfig *rest.Config, reqCtx context.Context, jobName, namespace string, conditions *[]metav1.Condition, kind string) error {
+	result, err := ReadResult(cli, reqCtx, jobName, namespace)
+	if err != nil {
+		return err
+	}
+	if result != nil && !result.Truncated {
+		RecordSuccessfulLogToCond(conditions, trimTooLongLog(result.Message))
+		return nil
+	}
+	return LogJobPodToCond(cli, restConfig, reqCtx, jobName, namespace, conditions, exporter.SummaryFunc(kind))
+}
+
+// ReadResultReport reads the report of the result files of the job into
+// report, it returns false if there is none. The report is read from the
+// termination message of the metrics container, and from its log if it did
+// not fit.
+func ReadResultReport(cli client.Client, restConfig *rest.Config, reqCtx context.Context, jobName, namespace string, report any) (bool, error) {
+	result, err := ReadResult(cli, reqCtx, jobName, namespace)
+	if err != nil {
+		return false, err
+	}
+	if result != nil && !result.Truncated {
+		if len(result.Report) == 0 {
+			return false, nil
+		}
+		return true, json.Unmarshal(result.Report, report)
+	}
+
+	podList, err := GetPodListFromJob(cli, reqCtx, jobName, namespace)
+	if err != nil {
+		return false, err
+	}
+
+	for _, pod := range podList.Items {
+		msg, err := GetContainerLogFromPod(restConfig, reqCtx, pod.Name, namespace, MetricsContainerName)
+		if err != nil {
+			return false, err
+		}
+		if ok, err := ParseResultReport(msg, report); ok || err != nil {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+// ParseResultReport unmarshals the last report in the log into report, it
+// returns false if the log holds none
+func ParseResultReport(log string, report any) (bool, error) {
+	lines := strings.Split(log, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		index := strings.Index(lines[i], constants.ResultReportMarker)
+		if index < 0 {
+			continue
+		}
+		data := strings.TrimSpace(lines[i][index+len(constants.ResultReportMarker):])
+		if err := json.Unmarshal([]byte(data), report); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	return false, nil
+}
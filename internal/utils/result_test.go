@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/apecloud/kubebench/internal/exporter"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+func newResultTestPod(message string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "tpch-run-x", Namespace: "default", Labels: map[string]string{"job-name": "tpch-run"}},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "kubebench", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: "done"}}},
+				{Name: MetricsContainerName, State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: message}}},
+			},
+		},
+	}
+}
+
+func TestReadResultReportFromTerminationMessage(t *testing.T) {
+	message := fmt.Sprintf(`{"version":%d,"kind":"tpch","benchmark":"tpch","job":"tpch-run","complete":true,"report":{"scale":1,"duration":8,"failed":0,"power":450,"throughput":450,"queries":[{"query":1,"duration":8,"rows":4}]}}`, constants.ResultFileVersion)
+	cli, _ := newTestClient(newResultTestPod(message))
+
+	result, err := ReadResult(cli, context.Background(), "tpch-run", "default")
+	if err != nil || result == nil || !result.Complete || result.Kind != "tpch" {
+		t.Fatalf("expected the result of the termination message, got %+v %v", result, err)
+	}
+
+	report := &exporter.QueryRunResult{}
+	// the termination message holds the report, the log is not read
+	ok, err := ReadResultReport(cli, nil, context.Background(), "tpch-run", "default", report)
+	if err != nil || !ok || report.Power != 450 || len(report.Queries) != 1 {
+		t.Fatalf("expected the report of the termination message, got %+v %v", report, err)
+	}
+}
+
+func TestReadResultWithoutTerminationMessage(t *testing.T) {
+	cli, _ := newTestClient(newResultTestPod(""))
+
+	result, err := ReadResult(cli, context.Background(), "tpch-run", "default")
+	if err != nil || result != nil {
+		t.Fatalf("expected no result, got %+v %v", result, err)
+	}
+}
+
+func TestRecordJobSummaryFromTerminationMessage(t *testing.T) {
+	message := fmt.Sprintf(`{"version":%d,"kind":"sysbench","benchmark":"sb","job":"tpch-run","complete":true,"message":"SQL statistics:\n    queries performed: 100"}`, constants.ResultFileVersion)
+	cli, _ := newTestClient(newResultTestPod(message))
+	conditions := make([]metav1.Condition, 0)
+
+	// the log is not read, there is no rest config to read it with
+	if err := RecordJobSummary(cli, nil, context.Background(), "tpch-run", "default", &conditions, constants.SysbenchType); err != nil {
+		t.Fatal(err)
+	}
+	if len(conditions) != 1 || conditions[0].Type != "Successful" || conditions[0].Message != "SQL statistics:\nqueries performed: 100" {
+		t.Fatalf("expected the message of the result as summary, got %+v", conditions)
+	}
+}
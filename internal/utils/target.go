@@ -0,0 +1,211 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/apecloud/kubebench/api/v1alpha1"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+var clusterGVK = schema.GroupVersionKind{Group: "apps.kubeblocks.io", Version: "v1alpha1", Kind: "Cluster"}
+
+// clusterDrivers maps the cluster definition or component definition of a
+// KubeBlocks cluster to the driver, the first match wins
+var clusterDrivers = []struct {
+	keyword string
+	driver  string
+}{
+	{"tidb", constants.TidbDriver},
+	{"mysql", constants.MySqlDriver},
+	{"postgresql", constants.PostgreSqlDriver},
+	{"mongodb", constants.MongoDbDriver},
+	{"redis", constants.RedisDriver},
+	{"elasticsearch", constants.ElasticsearchDriver},
+	{"minio", constants.MinioDriver},
+	{"mssql", constants.MssqlDriver},
+	{"sqlserver", constants.MssqlDriver},
+}
+
+// ResolveTarget fills target from the targetRef and returns what it resolved
+// to. A reference is resolved only once, later calls pass the returned record
+// back as resolved so every job of the benchmark runs against the same
+// endpoint. The password is read from the Secret on every call and never
+// recorded.
+func ResolveTarget(cli client.Client, reqCtx context.Context, namespace string, ref *v1alpha1.TargetRef, resolved *v1alpha1.ResolvedTarget, target *v1alpha1.Target) (*v1alpha1.ResolvedTarget, error) {
+	if ref == nil {
+		return resolved, nil
+	}
+	if ref.Namespace != "" {
+		namespace = ref.Namespace
+	}
+
+	if resolved == nil {
+		var err error
+		switch ref.Kind {
+		case "Service":
+			resolved, err = resolveService(cli, reqCtx, namespace, ref.Name, ref.PortName)
+		case "Cluster", "":
+			resolved, err = resolveCluster(cli, reqCtx, namespace, ref)
+		default:
+			err = fmt.Errorf("unsupported targetRef kind %s", ref.Kind)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if ref.SecretName != "" {
+			resolved.SecretName = ref.SecretName
+		}
+		resolved.ResolvedAt = metav1.Now()
+	}
+
+	target.Host = resolved.Host
+	target.Port = resolved.Port
+	if target.Driver == "" {
+		target.Driver = resolved.Driver
+	}
+	if target.Database == "" {
+		target.Database = "kubebench"
+	}
+	if resolved.SecretName == "" {
+		return resolved, nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := cli.Get(reqCtx, client.ObjectKey{Namespace: namespace, Name: resolved.SecretName}, secret); err != nil {
+		return nil, err
+	}
+	if user := string(secret.Data["username"]); user != "" {
+		resolved.User = user
+		target.User = user
+	}
+	target.Password = string(secret.Data["password"])
+
+	return resolved, nil
+}
+
+// resolveService resolves the port by name, or the only port of the service
+func resolveService(cli client.Client, reqCtx context.Context, namespace, name, portName string) (*v1alpha1.ResolvedTarget, error) {
+	svc := &corev1.Service{}
+	if err := cli.Get(reqCtx, client.ObjectKey{Namespace: namespace, Name: name}, svc); err != nil {
+		return nil, err
+	}
+
+	var port *corev1.ServicePort
+	for i := range svc.Spec.Ports {
+		if svc.Spec.Ports[i].Name == portName || (portName == "" && i == 0) {
+			port = &svc.Spec.Ports[i]
+			break
+		}
+	}
+	if port == nil {
+		return nil, fmt.Errorf("service %s/%s has no port %q", namespace, name, portName)
+	}
+
+	return &v1alpha1.ResolvedTarget{
+		Host: fmt.Sprintf("%s.%s.svc", name, namespace),
+		Port: int(port.Port),
+	}, nil
+}
+
+// resolveCluster resolves the service of the component that KubeBlocks
+// creates and the connection credential the cluster publishes
+func resolveCluster(cli client.Client, reqCtx context.Context, namespace string, ref *v1alpha1.TargetRef) (*v1alpha1.ResolvedTarget, error) {
+	cluster := &unstructured.Unstructured{}
+	cluster.SetGroupVersionKind(clusterGVK)
+	if err := cli.Get(reqCtx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, cluster); err != nil {
+		return nil, err
+	}
+
+	component, definitions, err := clusterComponent(cluster, ref.Component)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := resolveService(cli, reqCtx, namespace, fmt.Sprintf("%s-%s", ref.Name, component), ref.PortName)
+	if err != nil {
+		return nil, err
+	}
+	resolved.Driver = clusterDriver(definitions)
+
+	if ref.SecretName == "" {
+		resolved.SecretName, err = clusterSecret(cli, reqCtx, namespace, ref.Name, component)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return resolved, nil
+}
+
+// clusterComponent returns the name of the component and the definitions it
+// and the cluster refer to, the first component is used when none is given
+func clusterComponent(cluster *unstructured.Unstructured, name string) (string, []string, error) {
+	definitions := make([]string, 0)
+	for _, field := range []string{"clusterDefinitionRef", "clusterDef"} {
+		if def, _, _ := unstructured.NestedString(cluster.Object, "spec", field); def != "" {
+			definitions = append(definitions, def)
+		}
+	}
+
+	components, _, _ := unstructured.NestedSlice(cluster.Object, "spec", "componentSpecs")
+	for _, c := range components {
+		spec, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		component, _, _ := unstructured.NestedString(spec, "name")
+		if name != "" && component != name {
+			continue
+		}
+		for _, field := range []string{"componentDefRef", "componentDef"} {
+			if def, _, _ := unstructured.NestedString(spec, field); def != "" {
+				definitions = append([]string{def}, definitions...)
+			}
+		}
+		return component, definitions, nil
+	}
+
+	if name != "" {
+		return "", nil, fmt.Errorf("cluster %s has no component %s", cluster.GetName(), name)
+	}
+	return "", nil, fmt.Errorf("cluster %s has no components", cluster.GetName())
+}
+
+func clusterDriver(definitions []string) string {
+	for _, def := range definitions {
+		for _, d := range clusterDrivers {
+			if strings.Contains(def, d.keyword) {
+				return d.driver
+			}
+		}
+	}
+	return ""
+}
+
+// clusterSecret finds the connection credential of the cluster, older
+// KubeBlocks releases publish <cluster>-conn-credential, newer ones the root
+// account of the component
+func clusterSecret(cli client.Client, reqCtx context.Context, namespace, cluster, component string) (string, error) {
+	names := []string{
+		fmt.Sprintf("%s-conn-credential", cluster),
+		fmt.Sprintf("%s-%s-account-root", cluster, component),
+	}
+	for _, name := range names {
+		err := cli.Get(reqCtx, client.ObjectKey{Namespace: namespace, Name: name}, &corev1.Secret{})
+		if err == nil {
+			return name, nil
+		}
+		if !apierrors.IsNotFound(err) {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("cluster %s publishes none of the secrets %s, set secretName", cluster, strings.Join(names, ", "))
+}
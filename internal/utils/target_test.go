@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	benchmarkv1alpha1 "github.com/apecloud/kubebench/api/v1alpha1"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+func TestResolveTargetCluster(t *testing.T) {
+	cluster := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps.kubeblocks.io/v1alpha1",
+		"kind":       "Cluster",
+		"metadata":   map[string]interface{}{"name": "mycluster", "namespace": "db"},
+		"spec": map[string]interface{}{
+			"clusterDefinitionRef": "apecloud-mysql",
+			"componentSpecs": []interface{}{
+				map[string]interface{}{"name": "mysql", "componentDefRef": "mysql"},
+			},
+		},
+	}}
+	cli, _ := newTestClient(
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "mycluster-mysql", Namespace: "db"},
+			Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "mysql", Port: 3306}, {Name: "paxos", Port: 13306}}},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "mycluster-conn-credential", Namespace: "db"},
+			Data:       map[string][]byte{"username": []byte("root"), "password": []byte("secret")},
+		},
+		cluster,
+	)
+
+	ref := &benchmarkv1alpha1.TargetRef{Kind: "Cluster", Name: "mycluster", Namespace: "db", PortName: "mysql"}
+	target := benchmarkv1alpha1.Target{Database: "sbtest"}
+	resolved, err := ResolveTarget(cli, context.Background(), "default", ref, nil, &target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resolved.Driver != constants.MySqlDriver || resolved.Host != "mycluster-mysql.db.svc" || resolved.Port != 3306 ||
+		resolved.User != "root" || resolved.SecretName != "mycluster-conn-credential" || resolved.ResolvedAt.IsZero() {
+		t.Fatalf("unexpected resolved target: %+v", resolved)
+	}
+	want := benchmarkv1alpha1.Target{Driver: constants.MySqlDriver, Host: "mycluster-mysql.db.svc", Port: 3306, User: "root", Password: "secret", Database: "sbtest"}
+	if target != want {
+		t.Fatalf("unexpected target: %+v", target)
+	}
+
+	// a recorded target is reused even when the service changed
+	target = benchmarkv1alpha1.Target{}
+	again, err := ResolveTarget(cli, context.Background(), "default", ref, &benchmarkv1alpha1.ResolvedTarget{
+		Driver: constants.MySqlDriver, Host: "old.db.svc", Port: 3307, SecretName: "mycluster-conn-credential",
+	}, &target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Host != "old.db.svc" || target.Host != "old.db.svc" || target.Port != 3307 || target.Password != "secret" || target.Database != "kubebench" {
+		t.Fatalf("expected the recorded target to be used, got %+v %+v", again, target)
+	}
+}
+
+func TestResolveTargetService(t *testing.T) {
+	cli, _ := newTestClient(&corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "pg", Namespace: "default"},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "tcp-postgresql", Port: 5432}}},
+	})
+
+	target := benchmarkv1alpha1.Target{Driver: constants.PostgreSqlDriver, User: "postgres", Password: "pw"}
+	resolved, err := ResolveTarget(cli, context.Background(), "default", &benchmarkv1alpha1.TargetRef{Kind: "Service", Name: "pg"}, nil, &target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved.Host != "pg.default.svc" || resolved.Port != 5432 || resolved.SecretName != "" {
+		t.Fatalf("unexpected resolved target: %+v", resolved)
+	}
+	if target.Host != "pg.default.svc" || target.Port != 5432 || target.User != "postgres" || target.Password != "pw" {
+		t.Fatalf("expected the credentials of the target to be kept, got %+v", target)
+	}
+
+	if _, err := ResolveTarget(cli, context.Background(), "default", &benchmarkv1alpha1.TargetRef{Kind: "Service", Name: "pg", PortName: "http"}, nil, &target); err == nil {
+		t.Fatal("expected an error for a missing port")
+	}
+	if _, err := ResolveTarget(cli, context.Background(), "default", &benchmarkv1alpha1.TargetRef{Kind: "Cluster", Name: "missing"}, nil, &target); err == nil {
+		t.Fatal("expected an error for a missing cluster")
+	}
+}
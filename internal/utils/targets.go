@@ -0,0 +1,168 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/apecloud/kubebench/api/v1alpha1"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+const ParallelTargets = "Parallel"
+
+// ReconcileTargets runs the benchmark against every target as a benchmark of
+// the same kind named <name>-<target name>, owned by the benchmark and
+// labelled with the target. With the Sequential policy the benchmark of a
+// target is created once the ones before it finished, with Parallel all of
+// them are created at once. It returns the phase of the targets as a whole
+// and the number of targets that completed.
+func ReconcileTargets(cli client.Client, reqCtx context.Context, scheme *runtime.Scheme, owner client.Object, targets []v1alpha1.BenchTarget, policy string, statuses *[]v1alpha1.TargetStatus) (v1alpha1.BenchmarkPhase, int, error) {
+	l := log.FromContext(reqCtx)
+
+	gvk, err := apiutil.GVKForObject(owner, scheme)
+	if err != nil {
+		return "", 0, err
+	}
+
+	result := make([]v1alpha1.TargetStatus, 0, len(targets))
+	finished := true
+	succeeded, failed := 0, 0
+	for _, target := range targets {
+		status := v1alpha1.TargetStatus{
+			Name:      target.Name,
+			Benchmark: fmt.Sprintf("%s-%s", owner.GetName(), target.Name),
+			Phase:     v1alpha1.Pending,
+		}
+
+		bench := &unstructured.Unstructured{}
+		bench.SetGroupVersionKind(gvk)
+		err := cli.Get(reqCtx, client.ObjectKey{Namespace: owner.GetNamespace(), Name: status.Benchmark}, bench)
+		switch {
+		case apierrors.IsNotFound(err):
+			if policy != ParallelTargets && !finished {
+				break
+			}
+			if bench, err = newTargetBenchmark(owner, gvk.GroupVersion().String(), gvk.Kind, target); err != nil {
+				return "", 0, err
+			}
+			if err := controllerutil.SetOwnerReference(owner, bench, scheme); err != nil {
+				return "", 0, err
+			}
+			if err := cli.Create(reqCtx, bench); err != nil {
+				return "", 0, err
+			}
+			l.Info("created benchmark of target", "target", target.Name, "benchmark", status.Benchmark)
+		case err != nil:
+			return "", 0, err
+		default:
+			phase, _, _ := unstructured.NestedString(bench.Object, "status", "phase")
+			if phase != "" {
+				status.Phase = v1alpha1.BenchmarkPhase(phase)
+			}
+			status.Completions, _, _ = unstructured.NestedString(bench.Object, "status", "completions")
+		}
+
+		switch status.Phase {
+		case v1alpha1.Completed:
+			succeeded++
+		case v1alpha1.Failed:
+			failed++
+		default:
+			finished = false
+		}
+		result = append(result, status)
+	}
+	*statuses = result
+
+	switch {
+	case !finished:
+		return v1alpha1.Running, succeeded, nil
+	case failed > 0:
+		return v1alpha1.Failed, succeeded, nil
+	default:
+		return v1alpha1.Completed, succeeded, nil
+	}
+}
+
+// newTargetBenchmark copies the spec of the benchmark, replacing the targets
+// with the single target. The ttl is left to the benchmark, it collects the
+// jobs of its targets and a target that deleted itself would be run again.
+func newTargetBenchmark(owner client.Object, apiVersion, kind string, target v1alpha1.BenchTarget) (*unstructured.Unstructured, error) {
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(owner)
+	if err != nil {
+		return nil, err
+	}
+	spec, _, _ := unstructured.NestedMap(object, "spec")
+	delete(spec, "targets")
+	delete(spec, "targetsPolicy")
+	delete(spec, "target")
+	delete(spec, "targetRef")
+	delete(spec, "ttlSecondsAfterFinished")
+	delete(spec, "deleteAfterTTL")
+	if target.Target != nil {
+		if spec["target"], err = runtime.DefaultUnstructuredConverter.ToUnstructured(target.Target); err != nil {
+			return nil, err
+		}
+	}
+	if target.TargetRef != nil {
+		if spec["targetRef"], err = runtime.DefaultUnstructuredConverter.ToUnstructured(target.TargetRef); err != nil {
+			return nil, err
+		}
+	}
+
+	labels := make(map[string]string)
+	for k, v := range owner.GetLabels() {
+		labels[k] = v
+	}
+	labels[constants.KubeBenchTargetLabel] = target.Name
+
+	bench := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	bench.SetAPIVersion(apiVersion)
+	bench.SetKind(kind)
+	bench.SetName(fmt.Sprintf("%s-%s", owner.GetName(), target.Name))
+	bench.SetNamespace(owner.GetNamespace())
+	bench.SetLabels(labels)
+	return bench, nil
+}
+
+// targetBenchmarkUIDs returns the uids of the benchmarks that run the
+// benchmark against its targets
+func targetBenchmarkUIDs(cli client.Client, reqCtx context.Context, owner client.Object) (map[types.UID]bool, error) {
+	gvk, err := apiutil.GVKForObject(owner, cli.Scheme())
+	if err != nil {
+		return nil, err
+	}
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err := cli.List(reqCtx, list, client.InNamespace(owner.GetNamespace()), client.HasLabels{constants.KubeBenchTargetLabel}); err != nil {
+		return nil, err
+	}
+
+	uids := make(map[types.UID]bool)
+	for i := range list.Items {
+		for _, ref := range list.Items[i].GetOwnerReferences() {
+			if ref.UID == owner.GetUID() {
+				uids[list.Items[i].GetUID()] = true
+				break
+			}
+		}
+	}
+	return uids, nil
+}
+
+// isTargetBenchmark returns true if the benchmark runs another benchmark
+// against one of its targets
+func isTargetBenchmark(bench client.Object) bool {
+	_, ok := bench.GetLabels()[constants.KubeBenchTargetLabel]
+	return ok && len(bench.GetOwnerReferences()) > 0
+}
@@ -0,0 +1,169 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	batchv1 "k8s.io/api/batch/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	benchmarkv1alpha1 "github.com/apecloud/kubebench/api/v1alpha1"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+func newTargetsTestSysbench(policy string) *benchmarkv1alpha1.Sysbench {
+	mysql := newTestTarget(constants.MySqlDriver)
+	tidb := newTestTarget(constants.TidbDriver)
+	return &benchmarkv1alpha1.Sysbench{
+		ObjectMeta: metav1.ObjectMeta{Name: "sb", Namespace: "default", UID: "uid", Labels: map[string]string{"team": "db"}},
+		Spec: benchmarkv1alpha1.SysbenchSpec{
+			Threads: []int{4},
+			Types:   []string{"oltp_read_write"},
+			BenchCommon: benchmarkv1alpha1.BenchCommon{
+				Step: constants.AllStep,
+				Targets: []benchmarkv1alpha1.BenchTarget{
+					{Name: "mysql", Target: &mysql},
+					{Name: "tidb", Target: &tidb},
+				},
+				TargetsPolicy: policy,
+			},
+		},
+	}
+}
+
+func TestReconcileTargetsSequential(t *testing.T) {
+	cli, scheme := newTestClient()
+	ctx := context.Background()
+
+	owner := newTargetsTestSysbench("Sequential")
+	statuses := make([]benchmarkv1alpha1.TargetStatus, 0)
+	reconcile := func() (benchmarkv1alpha1.BenchmarkPhase, int) {
+		phase, succeeded, err := ReconcileTargets(cli, ctx, scheme, owner, owner.Spec.Targets, owner.Spec.TargetsPolicy, &statuses)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return phase, succeeded
+	}
+
+	if phase, _ := reconcile(); phase != benchmarkv1alpha1.Running {
+		t.Fatalf("expected the targets to be running, got %s", phase)
+	}
+	list := &benchmarkv1alpha1.SysbenchList{}
+	if err := cli.List(ctx, list, client.InNamespace("default")); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 1 {
+		t.Fatalf("expected only the first target to start, got %d benchmarks", len(list.Items))
+	}
+
+	child := list.Items[0]
+	if child.Name != "sb-mysql" || child.Labels[constants.KubeBenchTargetLabel] != "mysql" || child.Labels["team"] != "db" {
+		t.Fatalf("unexpected benchmark of the target: %+v", child.ObjectMeta)
+	}
+	if len(child.Spec.Targets) != 0 || child.Spec.Target.Driver != constants.MySqlDriver || child.Spec.Threads[0] != 4 {
+		t.Fatalf("expected the spec to be copied with the single target, got %+v", child.Spec)
+	}
+	if len(child.OwnerReferences) != 1 || child.OwnerReferences[0].Name != "sb" {
+		t.Fatalf("expected the benchmark to be owned, got %+v", child.OwnerReferences)
+	}
+
+	child.Status.Phase = benchmarkv1alpha1.Failed
+	if err := cli.Status().Update(ctx, &child); err != nil {
+		t.Fatal(err)
+	}
+	reconcile()
+	tidb := &benchmarkv1alpha1.Sysbench{}
+	if err := cli.Get(ctx, client.ObjectKey{Namespace: "default", Name: "sb-tidb"}, tidb); err != nil {
+		t.Fatalf("expected the next target to start once the first finished: %v", err)
+	}
+
+	tidb.Status.Phase = benchmarkv1alpha1.Completed
+	tidb.Status.Completions = "4/4"
+	if err := cli.Status().Update(ctx, tidb); err != nil {
+		t.Fatal(err)
+	}
+	phase, succeeded := reconcile()
+	if phase != benchmarkv1alpha1.Failed || succeeded != 1 {
+		t.Fatalf("expected a failed target to fail the benchmark, got %s %d", phase, succeeded)
+	}
+	if statuses[1].Name != "tidb" || statuses[1].Benchmark != "sb-tidb" || statuses[1].Phase != benchmarkv1alpha1.Completed || statuses[1].Completions != "4/4" {
+		t.Fatalf("unexpected target status: %+v", statuses)
+	}
+}
+
+func TestReconcileTargetsParallel(t *testing.T) {
+	cli, scheme := newTestClient()
+	ctx := context.Background()
+
+	owner := newTargetsTestSysbench(ParallelTargets)
+	statuses := make([]benchmarkv1alpha1.TargetStatus, 0)
+	if _, _, err := ReconcileTargets(cli, ctx, scheme, owner, owner.Spec.Targets, owner.Spec.TargetsPolicy, &statuses); err != nil {
+		t.Fatal(err)
+	}
+
+	list := &benchmarkv1alpha1.SysbenchList{}
+	if err := cli.List(ctx, list, client.MatchingLabels{"team": "db"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 2 {
+		t.Fatalf("expected every target to start at once, got %d benchmarks", len(list.Items))
+	}
+	if len(statuses) != 2 || statuses[0].Phase != benchmarkv1alpha1.Pending || statuses[1].Phase != benchmarkv1alpha1.Pending {
+		t.Fatalf("unexpected target statuses: %+v", statuses)
+	}
+}
+
+func TestTargetBenchmarksLeaveTTLToOwner(t *testing.T) {
+	viper.Set(constants.CfgKeyTTLSecondsAfterFinished, "0")
+	defer viper.Set(constants.CfgKeyTTLSecondsAfterFinished, "")
+	cli, scheme := newTestClient()
+	ctx := context.Background()
+
+	owner := newTargetsTestSysbench(ParallelTargets)
+	owner.Spec.TTLSecondsAfterFinished = ptrInt32(0)
+	owner.Spec.DeleteAfterTTL = true
+	statuses := make([]benchmarkv1alpha1.TargetStatus, 0)
+	if _, _, err := ReconcileTargets(cli, ctx, scheme, owner, owner.Spec.Targets, owner.Spec.TargetsPolicy, &statuses); err != nil {
+		t.Fatal(err)
+	}
+
+	child := &benchmarkv1alpha1.Sysbench{}
+	if err := cli.Get(ctx, client.ObjectKey{Namespace: "default", Name: "sb-mysql"}, child); err != nil {
+		t.Fatal(err)
+	}
+	if child.Spec.TTLSecondsAfterFinished != nil || child.Spec.DeleteAfterTTL {
+		t.Fatalf("expected the ttl to be left to the owner, got %+v", child.Spec.BenchCommon)
+	}
+	child.UID = "child"
+	if err := cli.Update(ctx, child); err != nil {
+		t.Fatal(err)
+	}
+	finished := metav1.NewTime(time.Now().Add(-time.Minute))
+	if err := cli.Create(ctx, newTTLTestJob("sb-mysql-run-0", child, &finished)); err != nil {
+		t.Fatal(err)
+	}
+
+	// the benchmark of a target is not collected on its own, even with the ttl of the manager
+	if _, err := CollectFinished(cli, ctx, child, &child.Spec.BenchCommon, &finished); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.Get(ctx, client.ObjectKey{Namespace: "default", Name: "sb-mysql-run-0"}, &batchv1.Job{}); err != nil {
+		t.Fatalf("expected the job of the target to be kept, got %v", err)
+	}
+
+	// the owner collects the jobs of its targets
+	owner.Spec.DeleteAfterTTL = false
+	if _, err := CollectFinished(cli, ctx, owner, &owner.Spec.BenchCommon, &finished); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.Get(ctx, client.ObjectKey{Namespace: "default", Name: "sb-mysql-run-0"}, &batchv1.Job{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the job of the target to be deleted, got %v", err)
+	}
+	if err := cli.Get(ctx, client.ObjectKeyFromObject(child), &benchmarkv1alpha1.Sysbench{}); err != nil {
+		t.Fatalf("expected the benchmark of the target to be kept, got %v", err)
+	}
+}
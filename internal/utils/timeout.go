@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"context"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/apecloud/kubebench/api/v1alpha1"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+const (
+	// TimedOutReason is the reason of benchmarks that ran out of time
+	TimedOutReason = "TimedOut"
+
+	// JobFailedReason is the reason of benchmarks whose job failed
+	JobFailedReason = "JobFailed"
+
+	// TotalTimeout is the step recorded when the benchmark as a whole ran
+	// out of time
+	TotalTimeout = "total"
+)
+
+// the reason of the job condition when the job passed its deadline
+const jobDeadlineExceeded = "DeadlineExceeded"
+
+// StepTimeout returns the timeout of the step in seconds, zero for none
+func StepTimeout(timeouts *v1alpha1.Timeouts, step string) int {
+	if timeouts == nil {
+		return 0
+	}
+	switch step {
+	case constants.PreCheckStep:
+		return timeouts.PreCheckSeconds
+	case constants.CleanupStep:
+		return timeouts.CleanupSeconds
+	case constants.PrepareStep:
+		return timeouts.PrepareSeconds
+	case constants.RunStep:
+		return timeouts.RunSeconds
+	}
+	return 0
+}
+
+// SetJobDeadlines sets the timeout of the step of every job as its deadline
+func SetJobDeadlines(jobs []*batchv1.Job, timeouts *v1alpha1.Timeouts) []*batchv1.Job {
+	for _, job := range jobs {
+		if timeout := StepTimeout(timeouts, JobStep(job)); timeout > 0 {
+			deadline := int64(timeout)
+			job.Spec.ActiveDeadlineSeconds = &deadline
+		}
+	}
+	return jobs
+}
+
+// TimedOutStep returns the step of the job if the job passed its deadline or
+// ran longer than the timeout of its step, TotalTimeout if the benchmark ran
+// longer than its total timeout, and empty otherwise. The afterAll hooks never
+// time out, they clean up after the benchmark even when it ran out of time.
+func TimedOutStep(owner metav1.Object, timeouts *v1alpha1.Timeouts, job *batchv1.Job, status *batchv1.JobStatus) string {
+	if isAfterAllHook(job) {
+		return ""
+	}
+	step := JobStep(job)
+	for _, cond := range status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == corev1.ConditionTrue && cond.Reason == jobDeadlineExceeded {
+			return step
+		}
+	}
+	if timeouts == nil {
+		return ""
+	}
+
+	created := owner.GetCreationTimestamp()
+	if timeouts.TotalSeconds > 0 && !created.IsZero() &&
+		time.Since(created.Time) > time.Duration(timeouts.TotalSeconds)*time.Second {
+		return TotalTimeout
+	}
+	if timeout := StepTimeout(timeouts, step); timeout > 0 && status.StartTime != nil &&
+		time.Since(status.StartTime.Time) > time.Duration(timeout)*time.Second {
+		return step
+	}
+	return ""
+}
+
+// StopTimedOutJob stops the job that ran out of time by moving its deadline to
+// now, the job controller then terminates its pods. It returns true once the
+// job finished, the step is then recorded as timed out and the log of the job
+// can be read without waiting for it
+func StopTimedOutJob(cli client.Client, reqCtx context.Context, recorder record.EventRecorder, owner runtime.Object, kind string, job *batchv1.Job, step string) (bool, error) {
+	current := &batchv1.Job{}
+	if err := cli.Get(reqCtx, client.ObjectKeyFromObject(job), current); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return false, err
+		}
+	} else if !jobFinished(&current.Status) {
+		if current.Spec.ActiveDeadlineSeconds != nil && *current.Spec.ActiveDeadlineSeconds == 1 {
+			return false, nil
+		}
+		patch := client.MergeFrom(current.DeepCopy())
+		deadline := int64(1)
+		current.Spec.ActiveDeadlineSeconds = &deadline
+		return false, cli.Patch(reqCtx, current, patch)
+	}
+
+	FailureCounter(kind, TimedOutReason).Inc()
+	if recorder != nil {
+		recorder.Eventf(owner, corev1.EventTypeWarning, TimedOutReason, "Job %s timed out in step %s", job.Name, step)
+	}
+	return true, nil
+}
+
+// jobFinished returns true if the job completed or failed
+func jobFinished(status *batchv1.JobStatus) bool {
+	if status.CompletionTime != nil {
+		return true
+	}
+	for _, cond := range status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
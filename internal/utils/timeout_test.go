@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	benchmarkv1alpha1 "github.com/apecloud/kubebench/api/v1alpha1"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+func TestTimedOutStep(t *testing.T) {
+	owner := &benchmarkv1alpha1.Sysbench{ObjectMeta: metav1.ObjectMeta{
+		Name: "sb", Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-10 * time.Minute)),
+	}}
+	job := MarkStepJobs([]*batchv1.Job{JobTemplate("sb-run-0", "default")}, constants.RunStep)[0]
+	started := metav1.NewTime(time.Now().Add(-2 * time.Minute))
+	running := &batchv1.JobStatus{StartTime: &started}
+	expired := &batchv1.JobStatus{StartTime: &started, Conditions: []batchv1.JobCondition{{
+		Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "DeadlineExceeded",
+	}}}
+
+	for _, tc := range []struct {
+		name     string
+		timeouts *benchmarkv1alpha1.Timeouts
+		status   *batchv1.JobStatus
+		want     string
+	}{
+		{"no timeouts", nil, running, ""},
+		{"within the timeouts", &benchmarkv1alpha1.Timeouts{RunSeconds: 600, TotalSeconds: 3600}, running, ""},
+		{"step", &benchmarkv1alpha1.Timeouts{RunSeconds: 60}, running, constants.RunStep},
+		{"total", &benchmarkv1alpha1.Timeouts{RunSeconds: 60, TotalSeconds: 300}, running, TotalTimeout},
+		{"deadline exceeded", nil, expired, constants.RunStep},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := TimedOutStep(owner, tc.timeouts, job, tc.status); got != tc.want {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+
+	// the afterAll hooks run after the benchmark ran out of time, they are not stopped
+	hooks := &benchmarkv1alpha1.Hooks{AfterAll: []benchmarkv1alpha1.Hook{{Name: "uncordon", Container: &benchmarkv1alpha1.ContainerHook{Image: "kubectl"}}}}
+	hook := NewHookJobs("sb", "default", constants.AfterAllHook, hooks, &benchmarkv1alpha1.Target{})[0]
+	now := metav1.Now()
+	if got := TimedOutStep(owner, &benchmarkv1alpha1.Timeouts{TotalSeconds: 300}, hook, &batchv1.JobStatus{StartTime: &now}); got != "" {
+		t.Fatalf("expected the afterAll hook not to time out, got %q", got)
+	}
+}
+
+func TestStopTimedOutJob(t *testing.T) {
+	job := JobTemplate("sb-run-0", "default")
+	cli, _ := newTestClient(job)
+	recorder := record.NewFakeRecorder(10)
+	owner := &benchmarkv1alpha1.Sysbench{ObjectMeta: metav1.ObjectMeta{Name: "sb", Namespace: "default"}}
+	ctx := context.Background()
+
+	done, err := StopTimedOutJob(cli, ctx, recorder, owner, constants.SysbenchType, job, constants.RunStep)
+	if err != nil || done {
+		t.Fatalf("expected the running job to be stopped first, got %v %v", done, err)
+	}
+	if events := drainEvents(recorder); len(events) != 0 {
+		t.Fatalf("expected no events before the job failed: %q", events)
+	}
+	stopped := &batchv1.Job{}
+	if err := cli.Get(ctx, client.ObjectKeyFromObject(job), stopped); err != nil {
+		t.Fatal(err)
+	}
+	if stopped.Spec.ActiveDeadlineSeconds == nil || *stopped.Spec.ActiveDeadlineSeconds != 1 {
+		t.Fatalf("expected the deadline of the job to be moved, got %v", stopped.Spec.ActiveDeadlineSeconds)
+	}
+
+	stopped.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "DeadlineExceeded"}}
+	if err := cli.Status().Update(ctx, stopped); err != nil {
+		t.Fatal(err)
+	}
+	if done, err := StopTimedOutJob(cli, ctx, recorder, owner, constants.SysbenchType, job, constants.RunStep); err != nil || !done {
+		t.Fatalf("expected the failed job to be done, got %v %v", done, err)
+	}
+	if events := drainEvents(recorder); len(events) != 1 || events[0] != "Warning TimedOut Job sb-run-0 timed out in step run" {
+		t.Fatalf("unexpected events: %q", events)
+	}
+}
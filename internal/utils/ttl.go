@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/spf13/viper"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/apecloud/kubebench/api/v1alpha1"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+// collectRetryDuration is how long to wait for the jobs that archive the
+// logs before the jobs are collected
+const collectRetryDuration = 5 * time.Second
+
+// TTLSecondsAfterFinished returns the ttl of the benchmark, or the one of the
+// manager if the benchmark has none, nil if neither is set
+func TTLSecondsAfterFinished(bench *v1alpha1.BenchCommon) *int32 {
+	if bench.TTLSecondsAfterFinished != nil {
+		return bench.TTLSecondsAfterFinished
+	}
+	ttl, err := strconv.ParseInt(viper.GetString(constants.CfgKeyTTLSecondsAfterFinished), 10, 32)
+	if err != nil || ttl < 0 {
+		return nil
+	}
+	result := int32(ttl)
+	return &result
+}
+
+// CollectFinished deletes the jobs of the finished benchmark, or the
+// benchmark itself with deleteAfterTTL, once its ttl expired. The ttl counts
+// from completionTimestamp, or from the last job that finished. It returns
+// how long to wait before collecting, zero when there is nothing left to do.
+// The jobs of the benchmarks of the targets are collected with the jobs of
+// the benchmark, those benchmarks are not collected on their own.
+func CollectFinished(cli client.Client, reqCtx context.Context, owner client.Object, bench *v1alpha1.BenchCommon, completion *metav1.Time) (time.Duration, error) {
+	l := log.FromContext(reqCtx)
+
+	ttl := TTLSecondsAfterFinished(bench)
+	if ttl == nil || isTargetBenchmark(owner) {
+		return 0, nil
+	}
+
+	jobs, err := ownedJobs(cli, reqCtx, owner)
+	if err != nil {
+		return 0, err
+	}
+
+	var finished time.Time
+	if completion != nil {
+		finished = completion.Time
+	}
+	for _, job := range jobs {
+		if _, ok := job.Labels[constants.KubeBenchFaultLabel]; ok {
+			// a fault may never finish on its own, it is deleted with the
+			// other jobs
+			continue
+		}
+		end := jobFinishTime(job)
+		if end.IsZero() {
+			// results and logs are not captured until every job finished,
+			// e.g. the job archiving the logs to a PVC
+			return collectRetryDuration, nil
+		}
+		if completion == nil && end.After(finished) {
+			finished = end
+		}
+	}
+	if finished.IsZero() {
+		finished = owner.GetCreationTimestamp().Time
+	}
+
+	if wait := time.Until(finished.Add(time.Duration(*ttl) * time.Second)); wait > 0 {
+		return wait, nil
+	}
+
+	policy := client.PropagationPolicy(metav1.DeletePropagationBackground)
+	if bench.DeleteAfterTTL {
+		l.Info("deleting the finished benchmark", "benchmark", owner.GetName())
+		return 0, client.IgnoreNotFound(cli.Delete(reqCtx, owner, policy))
+	}
+	for _, job := range jobs {
+		l.Info("deleting the job of the finished benchmark", "job", job.Name)
+		if err := cli.Delete(reqCtx, job, policy); client.IgnoreNotFound(err) != nil {
+			return 0, err
+		}
+	}
+	return 0, nil
+}
+
+// ownedJobs returns the jobs of the namespace of the owner that it or the
+// benchmarks of its targets own
+func ownedJobs(cli client.Client, reqCtx context.Context, owner client.Object) ([]*batchv1.Job, error) {
+	owners, err := targetBenchmarkUIDs(cli, reqCtx, owner)
+	if err != nil {
+		return nil, err
+	}
+	owners[owner.GetUID()] = true
+
+	jobList := &batchv1.JobList{}
+	if err := cli.List(reqCtx, jobList, client.InNamespace(owner.GetNamespace())); err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*batchv1.Job, 0)
+	for i := range jobList.Items {
+		for _, ref := range jobList.Items[i].OwnerReferences {
+			if owners[ref.UID] {
+				jobs = append(jobs, &jobList.Items[i])
+				break
+			}
+		}
+	}
+	return jobs, nil
+}
@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	batchv1 "k8s.io/api/batch/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	benchmarkv1alpha1 "github.com/apecloud/kubebench/api/v1alpha1"
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+func newTTLTestJob(name string, owner *benchmarkv1alpha1.Sysbench, finished *metav1.Time) *batchv1.Job {
+	job := JobTemplate(name, owner.Namespace)
+	job.OwnerReferences = []metav1.OwnerReference{{APIVersion: "benchmark.apecloud.io/v1alpha1", Kind: "Sysbench", Name: owner.Name, UID: owner.UID}}
+	job.Status.CompletionTime = finished
+	return job
+}
+
+func TestCollectFinished(t *testing.T) {
+	finished := metav1.NewTime(time.Now().Add(-time.Hour))
+	owner := &benchmarkv1alpha1.Sysbench{ObjectMeta: metav1.ObjectMeta{Name: "sb", Namespace: "default", UID: "uid"}}
+	other := &benchmarkv1alpha1.Sysbench{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default", UID: "other"}}
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		name        string
+		ttl         *int32
+		defaultTTL  string
+		archiving   bool
+		faulting    bool
+		wantDeleted bool
+		wantRequeue bool
+	}{
+		{name: "no ttl"},
+		{name: "ttl expired", ttl: ptrInt32(60), wantDeleted: true},
+		{name: "ttl not expired", ttl: ptrInt32(7200), wantRequeue: true},
+		{name: "default ttl of the manager", defaultTTL: "0", wantDeleted: true},
+		{name: "benchmark ttl overrides the default", ttl: ptrInt32(7200), defaultTTL: "0", wantRequeue: true},
+		{name: "logs still archived", ttl: ptrInt32(0), archiving: true, wantRequeue: true},
+		{name: "fault still running", ttl: ptrInt32(0), faulting: true, wantDeleted: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			viper.Set(constants.CfgKeyTTLSecondsAfterFinished, tc.defaultTTL)
+			defer viper.Set(constants.CfgKeyTTLSecondsAfterFinished, "")
+
+			objs := []client.Object{
+				newTTLTestJob("sb-run-0", owner, &finished),
+				newTTLTestJob("other-run-0", other, &finished),
+			}
+			if tc.archiving {
+				objs = append(objs, newTTLTestJob("sb-run-0-archive", owner, nil))
+			}
+			if tc.faulting {
+				fault := newTTLTestJob("sb-run-0-fault-chaos", owner, nil)
+				fault.Labels = map[string]string{constants.KubeBenchFaultLabel: "chaos"}
+				objs = append(objs, fault)
+			}
+			cli, _ := newTestClient(objs...)
+			bench := &benchmarkv1alpha1.BenchCommon{TTLSecondsAfterFinished: tc.ttl}
+
+			requeueAfter, err := CollectFinished(cli, ctx, owner, bench, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (requeueAfter > 0) != tc.wantRequeue {
+				t.Fatalf("unexpected requeue after %v", requeueAfter)
+			}
+			err = cli.Get(ctx, client.ObjectKey{Namespace: "default", Name: "sb-run-0"}, &batchv1.Job{})
+			if deleted := apierrors.IsNotFound(err); deleted != tc.wantDeleted {
+				t.Fatalf("expected the job to be deleted: %v, got %v", tc.wantDeleted, err)
+			}
+			if tc.faulting {
+				if err := cli.Get(ctx, client.ObjectKey{Namespace: "default", Name: "sb-run-0-fault-chaos"}, &batchv1.Job{}); !apierrors.IsNotFound(err) {
+					t.Fatalf("expected the fault job to be deleted, got %v", err)
+				}
+			}
+			if err := cli.Get(ctx, client.ObjectKey{Namespace: "default", Name: "other-run-0"}, &batchv1.Job{}); err != nil {
+				t.Fatalf("expected the jobs of other benchmarks to be kept, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCollectFinishedDeletesBenchmark(t *testing.T) {
+	owner := &benchmarkv1alpha1.Sysbench{ObjectMeta: metav1.ObjectMeta{Name: "sb", Namespace: "default"}}
+	cli, _ := newTestClient(owner)
+	ctx := context.Background()
+	if err := cli.Get(ctx, client.ObjectKeyFromObject(owner), owner); err != nil {
+		t.Fatal(err)
+	}
+
+	completion := metav1.NewTime(time.Now().Add(-time.Minute))
+	bench := &benchmarkv1alpha1.BenchCommon{TTLSecondsAfterFinished: ptrInt32(30), DeleteAfterTTL: true}
+	if _, err := CollectFinished(cli, ctx, owner, bench, &completion); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.Get(ctx, client.ObjectKeyFromObject(owner), owner); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the benchmark to be deleted, got %v", err)
+	}
+}
+
+func ptrInt32(v int32) *int32 {
+	return &v
+}
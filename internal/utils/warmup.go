@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apecloud/kubebench/pkg/constants"
+)
+
+// ParseWarmup returns the warmup as a duration, or as a fraction of the
+// measured pass when it is a percentage. Both are zero when no warmup is set
+// or it can not be parsed.
+func ParseWarmup(warmup string) (time.Duration, float64) {
+	warmup = strings.TrimSpace(warmup)
+	if warmup == "" {
+		return 0, 0
+	}
+
+	if percent, ok := strings.CutSuffix(warmup, "%"); ok {
+		value, err := strconv.ParseFloat(percent, 64)
+		if err != nil || value <= 0 || value >= 100 {
+			return 0, 0
+		}
+		return 0, value / 100
+	}
+
+	d, err := time.ParseDuration(warmup)
+	if err != nil || d <= 0 {
+		return 0, 0
+	}
+	return d, 0
+}
+
+// WarmupSeconds returns the length of the warmup pass in seconds, a
+// percentage is taken of duration
+func WarmupSeconds(warmup string, duration int) int {
+	d, fraction := ParseWarmup(warmup)
+	if fraction > 0 {
+		return WarmupCount(fraction, duration)
+	}
+	return int(math.Ceil(d.Seconds()))
+}
+
+// WarmupCount returns the fraction of total, at least 1
+func WarmupCount(fraction float64, total int) int {
+	if fraction <= 0 || total <= 0 {
+		return 0
+	}
+	return int(math.Max(1, math.Ceil(fraction*float64(total))))
+}
+
+// WarmupCommand runs cmd between the warmup markers, everything is appended
+// to logFile so the exporter tailing it skips the warmup output. Without a
+// logFile the output goes to stdout.
+func WarmupCommand(cmd, logFile string) string {
+	if logFile == "" {
+		return fmt.Sprintf("echo '%s'; %s; echo '%s'; ", constants.WarmupStartMarker, cmd, constants.WarmupEndMarker)
+	}
+	return fmt.Sprintf("echo '%s' | tee -a %s; %s 2>&1 | tee -a %s; echo '%s' | tee -a %s; ",
+		constants.WarmupStartMarker, logFile, cmd, logFile, constants.WarmupEndMarker, logFile)
+}
@@ -5,8 +5,12 @@ const (
 )
 
 const (
-	KubeBenchNameLabel = "kubebench.apecloud.io/name"
-	KubeBenchTypeLabel = "kubebench.apecloud.io/type"
+	KubeBenchNameLabel   = "kubebench.apecloud.io/name"
+	KubeBenchTypeLabel   = "kubebench.apecloud.io/type"
+	KubeBenchStepLabel   = "kubebench.apecloud.io/step"
+	KubeBenchTargetLabel = "kubebench.apecloud.io/target"
+	KubeBenchFaultLabel  = "kubebench.apecloud.io/fault"
+	KubeBenchHookLabel   = "kubebench.apecloud.io/hook"
 )
 
 const (
@@ -36,10 +40,50 @@ const (
 )
 
 const (
-	CleanupStep = "cleanup"
-	PrepareStep = "prepare"
-	RunStep     = "run"
-	AllStep     = "all"
+	CleanupStep  = "cleanup"
+	PrepareStep  = "prepare"
+	RunStep      = "run"
+	AllStep      = "all"
+	PreCheckStep = "precheck"
+	HookStep     = "hook"
+)
+
+const (
+	BeforePrepareHook = "before-prepare"
+	AfterPrepareHook  = "after-prepare"
+	BeforeRunHook     = "before-run"
+	AfterRunHook      = "after-run"
+	AfterAllHook      = "after-all"
+)
+
+// the run jobs print the markers around the warmup pass, the exporter skips
+// everything between them
+const (
+	WarmupStartMarker = "==== kubebench warmup start ===="
+	WarmupEndMarker   = "==== kubebench warmup end ===="
+)
+
+// the run jobs of the query benchmarks print the marker once the runner
+// exited, the exporter parses the queries then
+const RunEndMarker = "==== kubebench run end ===="
+
+// the exporter prints the report of the result files after the marker, the
+// controller reads it back from the log of the metrics container
+const ResultReportMarker = "==== kubebench result report ===="
+
+// the exporter writes the result file with the version next to the scraped
+// file once scraping finished, its summary becomes the termination message
+// of the metrics container
+const (
+	ResultFileName    = "result.json"
+	ResultFileVersion = 1
+)
+
+// pgbench writes the transaction logs with the prefixes to the log volume,
+// the aggregated logs hold a line for every aggregate interval
+const (
+	PgbenchLogPrefix          = "pgbench_log"
+	PgbenchAggregateLogPrefix = "pgbench_agg"
 )
 
 const (
@@ -62,3 +106,8 @@ const (
 	EsrallyWorkloadMixed  = "mixed"
 	EsrallyWorkloadAll    = "all"
 )
+
+const (
+	EsrallyReportFormatCSV  = "csv"
+	EsrallyReportFormatJSON = "json"
+)
@@ -23,6 +23,15 @@ const (
 
 const (
 	CfgKeyCtrlrMgrTolerations = "CM_TOLERATIONS"
+
+	// CfgKeyTTLSecondsAfterFinished is the default ttl of the jobs of
+	// finished benchmarks, empty to keep them
+	CfgKeyTTLSecondsAfterFinished = "KUBEBENCH_TTL_SECONDS_AFTER_FINISHED"
+
+	// CfgKeyAllowClusterFaults lets faults delete pods and scale
+	// StatefulSets in other namespaces than the one of the benchmark, and
+	// cordon nodes
+	CfgKeyAllowClusterFaults = "KUBEBENCH_ALLOW_CLUSTER_FAULTS"
 )
 
 const (
@@ -42,6 +51,8 @@ func init() {
 	viper.SetDefault(KubebenchExporter, fmt.Sprintf("%s/apecloud/kubebench:0.0.14", DefaultImageRegistry))
 	viper.SetDefault(KubebenchTools, fmt.Sprintf("%s/apecloud/kubebench:0.0.14", DefaultImageRegistry))
 	viper.SetDefault(CfgKeyCtrlrMgrTolerations, os.Getenv(CfgKeyCtrlrMgrTolerations))
+	viper.SetDefault(CfgKeyTTLSecondsAfterFinished, os.Getenv(CfgKeyTTLSecondsAfterFinished))
+	viper.SetDefault(CfgKeyAllowClusterFaults, os.Getenv(CfgKeyAllowClusterFaults))
 }
 
 // GetBenchmarkImage get benchmark image
@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+func NewConcatCmd() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "concat [files...]",
+		Short: "Concatenate files into one",
+		Long:  "Concatenate the files in order into the output file, used to copy archived logs into a volume",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := Concat(output, args); err != nil {
+				log.Fatalf("failed to write %s: %v", output, err)
+			}
+			log.Printf("wrote %s", output)
+		},
+	}
+
+	cmd.Flags().StringVar(&output, "output", "", "the file to write, its directory is created")
+	_ = cmd.MarkFlagRequired("output")
+
+	return cmd
+}
+
+// Concat writes the files one after another into output, replacing it
+func Concat(output string, files []string) error {
+	if err := os.MkdirAll(filepath.Dir(output), 0o755); err != nil {
+		return err
+	}
+	out, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	for _, file := range files {
+		in, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(out, in)
+		in.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return out.Close()
+}
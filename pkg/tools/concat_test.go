@@ -0,0 +1,35 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConcat(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "0")
+	second := filepath.Join(dir, "1")
+	if err := os.WriteFile(first, []byte("hello "), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(second, []byte("world"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(dir, "bench", "job", "kubebench.log")
+	if err := Concat(output, []string{first, second}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hello world" {
+		t.Fatalf("unexpected output: %q", data)
+	}
+
+	if err := Concat(output, []string{filepath.Join(dir, "missing")}); err == nil {
+		t.Fatal("expected an error for a missing file")
+	}
+}
@@ -1,12 +1,14 @@
 package tools
 
 import (
+	"bytes"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
@@ -21,6 +23,7 @@ type ElasticsearchClient struct {
 	Scheme             string
 	Path               string
 	InsecureSkipVerify bool
+	Timeout            time.Duration
 
 	client *http.Client
 }
@@ -32,6 +35,7 @@ func NewElasticsearchCmd() *cobra.Command {
 	}
 
 	cmd.AddCommand(newPingElasticsearchCmd())
+	cmd.AddCommand(newDeleteIndexElasticsearchCmd())
 
 	return cmd
 }
@@ -59,6 +63,36 @@ func newPingElasticsearchCmd() *cobra.Command {
 	return cmd
 }
 
+func newDeleteIndexElasticsearchCmd() *cobra.Command {
+	client := &ElasticsearchClient{}
+
+	cmd := &cobra.Command{
+		Use:   "delete-index [index name]",
+		Short: "Delete indices",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := client.InitClient(); err != nil {
+				log.Fatalf("Failed to init client: %v", err)
+			}
+
+			for _, index := range args {
+				deleted, err := client.DeleteIndex(index)
+				if err != nil {
+					log.Fatalf("Failed to delete index %s: %v", index, err)
+				}
+				if deleted {
+					fmt.Printf("Index %s deleted\n", index)
+				} else {
+					fmt.Printf("Index %s does not exist\n", index)
+				}
+			}
+		},
+	}
+
+	addElasticsearchFlags(cmd, client)
+
+	return cmd
+}
+
 func addElasticsearchFlags(cmd *cobra.Command, client *ElasticsearchClient) {
 	cmd.Flags().StringVar(&client.Host, "host", "localhost", "Elasticsearch server host")
 	cmd.Flags().IntVar(&client.Port, "port", 9200, "Elasticsearch server port")
@@ -88,19 +122,30 @@ func (c *ElasticsearchClient) InitClient() error {
 	if c.Scheme == "https" && c.InsecureSkipVerify {
 		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
 	}
-	c.client = &http.Client{Timeout: 10 * time.Second, Transport: transport}
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+	c.client = &http.Client{Timeout: c.Timeout, Transport: transport}
 	return nil
 }
 
-func (c *ElasticsearchClient) CheckConnection() error {
+// do sends a request to the cluster and returns the status code and body
+func (c *ElasticsearchClient) do(method, path string, body []byte) (int, []byte, error) {
 	if c.client == nil {
-		return fmt.Errorf("http client is not initialized")
+		return 0, nil, fmt.Errorf("http client is not initialized")
 	}
 
-	url := fmt.Sprintf("%s://%s:%d%s", c.Scheme, c.Host, c.Port, c.Path)
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	endpoint := fmt.Sprintf("%s://%s:%d%s", c.Scheme, c.Host, c.Port, path)
+	req, err := http.NewRequest(method, endpoint, reader)
 	if err != nil {
-		return err
+		return 0, nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
 	}
 	if c.Username != "" && c.Password != "" {
 		req.SetBasicAuth(c.Username, c.Password)
@@ -108,17 +153,43 @@ func (c *ElasticsearchClient) CheckConnection() error {
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return err
+		return 0, nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
-		return fmt.Errorf("unexpected status %d from elasticsearch: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, err
+	}
+	return resp.StatusCode, data, nil
+}
+
+func statusError(status int, body []byte) error {
+	if len(body) > 1024 {
+		body = body[:1024]
+	}
+	return fmt.Errorf("unexpected status %d from elasticsearch: %s", status, strings.TrimSpace(string(body)))
+}
+
+func isSuccess(status int) bool {
+	return status >= http.StatusOK && status < http.StatusMultipleChoices
+}
+
+func indexPath(index string) string {
+	return "/" + url.PathEscape(index)
+}
+
+func (c *ElasticsearchClient) CheckConnection() error {
+	status, body, err := c.do(http.MethodGet, c.Path, nil)
+	if err != nil {
+		return err
+	}
+	if !isSuccess(status) {
+		return statusError(status, body)
 	}
 
 	var payload map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&payload); err == nil {
+	if err := json.Unmarshal(body, &payload); err == nil {
 		if status, ok := payload["status"].(string); ok {
 			fmt.Printf("Elasticsearch cluster health status: %s\n", status)
 		}
@@ -126,3 +197,18 @@ func (c *ElasticsearchClient) CheckConnection() error {
 
 	return nil
 }
+
+// DeleteIndex deletes the index, a missing index is not an error
+func (c *ElasticsearchClient) DeleteIndex(index string) (bool, error) {
+	status, resp, err := c.do(http.MethodDelete, indexPath(index), nil)
+	if err != nil {
+		return false, err
+	}
+	if status == http.StatusNotFound {
+		return false, nil
+	}
+	if !isSuccess(status) {
+		return false, statusError(status, resp)
+	}
+	return true, nil
+}
@@ -5,6 +5,8 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -89,6 +91,53 @@ func TestElasticsearchInitClientRejectsUnsupportedScheme(t *testing.T) {
 	}
 }
 
+// fakeElasticsearch keeps just enough index state to exercise delete-index
+type fakeElasticsearch struct {
+	mu      sync.Mutex
+	indices map[string]bool
+	calls   []string
+}
+
+func (f *fakeElasticsearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, r.Method+" "+r.URL.RequestURI())
+
+	index := strings.Trim(r.URL.Path, "/")
+	switch {
+	case r.Method != http.MethodDelete:
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+	case !f.indices[index]:
+		http.Error(w, `{"error":{"type":"index_not_found_exception"}}`, http.StatusNotFound)
+	default:
+		delete(f.indices, index)
+		_, _ = w.Write([]byte(`{"acknowledged":true}`))
+	}
+}
+
+func TestElasticsearchDeleteIndex(t *testing.T) {
+	fake := &fakeElasticsearch{indices: map[string]bool{"logs": true}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client := elasticsearchClientFromURL(t, server.URL)
+	if err := client.InitClient(); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, err := client.DeleteIndex("logs")
+	if err != nil || !deleted {
+		t.Fatalf("expected index to be deleted, got %t, %v", deleted, err)
+	}
+	deleted, err = client.DeleteIndex("logs")
+	if err != nil || deleted {
+		t.Fatalf("expected missing index to be ignored, got %t, %v", deleted, err)
+	}
+	if len(fake.calls) != 2 || fake.calls[0] != "DELETE /logs" {
+		t.Fatalf("unexpected calls: %v", fake.calls)
+	}
+}
+
 func elasticsearchClientFromURL(t *testing.T, rawURL string) *ElasticsearchClient {
 	t.Helper()
 	u, err := url.Parse(rawURL)
@@ -21,6 +21,7 @@ type GaussDBClient struct {
 	Port     int
 	Username string
 	Password string
+	Database string
 
 	db *sql.DB
 }
@@ -34,6 +35,7 @@ func NewGaussdbCmd() *cobra.Command {
 	cmd.AddCommand(newCreateGaussdbDatabaseCmd())
 	cmd.AddCommand(newDropGaussdbDatabaseCmd())
 	cmd.AddCommand(newPingGaussdbDatabaseCmd())
+	cmd.AddCommand(newVerifyGaussdbDatabaseCmd())
 
 	return cmd
 }
@@ -115,9 +117,35 @@ func newPingGaussdbDatabaseCmd() *cobra.Command {
 	return cmd
 }
 
+func newVerifyGaussdbDatabaseCmd() *cobra.Command {
+	client := &GaussDBClient{}
+	expect := map[string]int64{}
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify prepared tables and their row counts",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := client.InitClient(); err != nil {
+				log.Fatalf("Failed to init client: %v", err)
+			}
+			defer client.Close()
+
+			if err := client.VerifyTables(expect); err != nil {
+				log.Fatalf("Failed to verify prepared data in database %s: %v", client.Database, err)
+			}
+			fmt.Println("Prepared data verified")
+		},
+	}
+
+	addGaussdbFlags(cmd, client)
+	addVerifyFlags(cmd, &client.Database, &expect)
+
+	return cmd
+}
+
 func (c *GaussDBClient) InitClient() error {
 	connStr := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
-		c.Host, c.Port, c.Username, c.Password, DefaultGaussDBDatabase)
+		c.Host, c.Port, c.Username, c.Password, c.database())
 
 	db, err := sql.Open("opengauss", connStr)
 	if err != nil {
@@ -170,6 +198,28 @@ func (c *GaussDBClient) CheckConnection() error {
 	return c.db.Ping()
 }
 
+// database returns the database to connect to, falling back to the default
+// one used for creating and dropping databases.
+func (c *GaussDBClient) database() string {
+	if c.Database != "" {
+		return c.Database
+	}
+	return DefaultGaussDBDatabase
+}
+
+// VerifyTables checks the row counts of tables in the current schema.
+func (c *GaussDBClient) VerifyTables(expect map[string]int64) error {
+	if c.db == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+
+	return VerifyTables(&sqlTableChecker{
+		db:          c.db,
+		existsQuery: "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = $1",
+		quote:       func(table string) string { return fmt.Sprintf("\"%s\"", table) },
+	}, expect)
+}
+
 func addGaussdbFlags(cmd *cobra.Command, client *GaussDBClient) {
 	cmd.Flags().StringVar(&client.Host, "host", "localhost", "GaussDB host")
 	cmd.Flags().IntVar(&client.Port, "port", 5432, "GaussDB port")
@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// maxHTTPResponseBody limits how much of the response is printed
+const maxHTTPResponseBody = 4096
+
+// HTTPRequest is a single request sent by the http command, used by the http
+// hooks of the benchmarks
+type HTTPRequest struct {
+	URL                string
+	Method             string
+	Headers            []string
+	Body               string
+	Timeout            time.Duration
+	InsecureSkipVerify bool
+}
+
+func NewHTTPCmd() *cobra.Command {
+	request := &HTTPRequest{}
+
+	cmd := &cobra.Command{
+		Use:   "http",
+		Short: "Send an http request",
+		Long:  "Send an http request and fail unless the response status is 2xx",
+		Run: func(cmd *cobra.Command, args []string) {
+			status, body, err := request.Do()
+			if err != nil {
+				log.Fatalf("failed to send %s %s: %v", request.Method, request.URL, err)
+			}
+			fmt.Printf("%s %s: %s\n", request.Method, request.URL, status)
+			if body != "" {
+				fmt.Println(body)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&request.URL, "url", "", "the url to call")
+	cmd.Flags().StringVar(&request.Method, "method", http.MethodGet, "the http method")
+	cmd.Flags().StringArrayVar(&request.Headers, "header", nil, "a request header as 'Name: value', can be repeated")
+	cmd.Flags().StringVar(&request.Body, "body", "", "the request body")
+	cmd.Flags().DurationVar(&request.Timeout, "timeout", time.Minute, "the request timeout")
+	cmd.Flags().BoolVar(&request.InsecureSkipVerify, "insecure-skip-verify", false, "skip the verification of the server certificate")
+	_ = cmd.MarkFlagRequired("url")
+
+	return cmd
+}
+
+// Do sends the request and returns the response status and the start of the
+// body, a status other than 2xx is an error
+func (r *HTTPRequest) Do() (string, string, error) {
+	var body io.Reader
+	if r.Body != "" {
+		body = strings.NewReader(r.Body)
+	}
+	req, err := http.NewRequest(strings.ToUpper(r.Method), r.URL, body)
+	if err != nil {
+		return "", "", err
+	}
+	for _, header := range r.Headers {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok {
+			return "", "", fmt.Errorf("invalid header %q, expected 'Name: value'", header)
+		}
+		req.Header.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+
+	client := &http.Client{
+		Timeout: r.Timeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: r.InsecureSkipVerify},
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPResponseBody))
+	if err != nil {
+		return resp.Status, "", err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.Status, string(data), fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	return resp.Status, string(data), nil
+}
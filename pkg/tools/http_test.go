@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPRequestDo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPost || r.URL.Path != "/flush" || string(body) != `{"all":true}` {
+			t.Fatalf("unexpected request: %s %s %s", r.Method, r.URL.Path, body)
+		}
+		if r.Header.Get("X-Token") != "secret" {
+			t.Fatalf("unexpected headers: %v", r.Header)
+		}
+		_, _ = w.Write([]byte("flushed"))
+	}))
+	defer server.Close()
+
+	request := &HTTPRequest{
+		URL:     server.URL + "/flush",
+		Method:  "post",
+		Headers: []string{"X-Token: secret"},
+		Body:    `{"all":true}`,
+		Timeout: time.Second,
+	}
+	status, body, err := request.Do()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != "200 OK" || body != "flushed" {
+		t.Fatalf("unexpected response: %s %s", status, body)
+	}
+}
+
+func TestHTTPRequestDoFailsOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "busy", http.StatusConflict)
+	}))
+	defer server.Close()
+
+	request := &HTTPRequest{URL: server.URL, Method: http.MethodGet, Timeout: time.Second}
+	if _, _, err := request.Do(); err == nil {
+		t.Fatal("expected status error")
+	}
+
+	request.Headers = []string{"missing-colon"}
+	if _, _, err := request.Do(); err == nil {
+		t.Fatal("expected invalid header error")
+	}
+}
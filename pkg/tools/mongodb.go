@@ -7,6 +7,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -16,6 +17,7 @@ type MongoDBClient struct {
 	Port     int
 	Username string
 	Password string
+	Database string
 
 	client *mongo.Client
 }
@@ -30,6 +32,7 @@ func NewMongoDBCmd() *cobra.Command {
 	cmd.AddCommand(newCreateMongoDBCmd())
 	cmd.AddCommand(newDropMongoDBCmd())
 	cmd.AddCommand(newPingMongoDBCmd())
+	cmd.AddCommand(newVerifyMongoDBCmd())
 
 	return cmd
 }
@@ -110,6 +113,32 @@ func newPingMongoDBCmd() *cobra.Command {
 	return cmd
 }
 
+func newVerifyMongoDBCmd() *cobra.Command {
+	client := &MongoDBClient{}
+	expect := map[string]int64{}
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify prepared collections and their document counts",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := client.InitClient(); err != nil {
+				log.Fatalf("failed to init client: %v", err)
+			}
+			defer client.Close()
+
+			if err := VerifyTables(client, expect); err != nil {
+				log.Fatalf("failed to verify prepared data in database %s: %v", client.Database, err)
+			}
+			fmt.Println("Prepared data verified")
+		},
+	}
+
+	addMongoDBFlags(cmd, client)
+	addVerifyFlags(cmd, &client.Database, &expect)
+
+	return cmd
+}
+
 func (c *MongoDBClient) InitClient() error {
 	mongodbURI := fmt.Sprintf("mongodb://%s:%s@%s:%d", c.Username, c.Password, c.Host, c.Port)
 
@@ -139,6 +168,20 @@ func (c *MongoDBClient) CheckConnection() error {
 	return c.client.Ping(context.Background(), nil)
 }
 
+// TableExists reports whether the collection exists in the current database.
+func (c *MongoDBClient) TableExists(collection string) (bool, error) {
+	names, err := c.client.Database(c.Database).ListCollectionNames(context.Background(), bson.D{{Key: "name", Value: collection}})
+	if err != nil {
+		return false, err
+	}
+	return len(names) > 0, nil
+}
+
+// CountRows returns the number of documents in the collection.
+func (c *MongoDBClient) CountRows(collection string) (int64, error) {
+	return c.client.Database(c.Database).Collection(collection).CountDocuments(context.Background(), bson.D{})
+}
+
 func addMongoDBFlags(cmd *cobra.Command, client *MongoDBClient) {
 	cmd.Flags().StringVarP(&client.Host, "host", "", "localhost", "MongoDB host")
 	cmd.Flags().IntVarP(&client.Port, "port", "", 27017, "MongoDB port")
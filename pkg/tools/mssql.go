@@ -0,0 +1,229 @@
+package tools
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	_ "github.com/microsoft/go-mssqldb"
+	"github.com/spf13/cobra"
+)
+
+const DefaultMssqlDatabase = "master"
+
+type MssqlClient struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	Database string
+
+	db *sql.DB
+}
+
+func NewMssqlCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mssql",
+		Short: "SQL Server tools",
+		Long:  "SQL Server tools for creating and dropping databases",
+	}
+
+	cmd.AddCommand(newCreateMssqlDatabaseCmd())
+	cmd.AddCommand(newDropMssqlDatabaseCmd())
+	cmd.AddCommand(newPingMssqlCmd())
+	cmd.AddCommand(newVerifyMssqlCmd())
+
+	return cmd
+}
+
+func newCreateMssqlDatabaseCmd() *cobra.Command {
+	client := &MssqlClient{}
+
+	cmd := &cobra.Command{
+		Use:   "create [database name]",
+		Short: "Create new databases",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := client.InitClient(); err != nil {
+				log.Fatalf("failed to connect to SQL Server: %v", err)
+			}
+			defer client.Close()
+
+			for _, name := range args {
+				if err := client.CreateDatabase(name); err != nil {
+					log.Fatalf("failed to create database %s: %v", name, err)
+				}
+				fmt.Printf("Database %s created\n", name)
+			}
+		},
+	}
+
+	addMssqlFlags(cmd, client)
+
+	return cmd
+}
+
+func newDropMssqlDatabaseCmd() *cobra.Command {
+	client := &MssqlClient{}
+
+	cmd := &cobra.Command{
+		Use:   "drop [database name]",
+		Short: "Drop databases",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := client.InitClient(); err != nil {
+				log.Fatalf("failed to connect to SQL Server: %v", err)
+			}
+			defer client.Close()
+
+			for _, name := range args {
+				if err := client.DropDatabase(name); err != nil {
+					log.Fatalf("failed to drop database %s: %v", name, err)
+				}
+				fmt.Printf("Database %s dropped\n", name)
+			}
+		},
+	}
+
+	addMssqlFlags(cmd, client)
+
+	return cmd
+}
+
+func newPingMssqlCmd() *cobra.Command {
+	client := &MssqlClient{}
+
+	cmd := &cobra.Command{
+		Use:   "ping",
+		Short: "Ping SQL Server",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := client.InitClient(); err != nil {
+				log.Fatalf("failed to connect to SQL Server: %v", err)
+			}
+			defer client.Close()
+
+			if err := client.CheckConnection(); err != nil {
+				log.Fatalf("failed to ping SQL Server: %v", err)
+			}
+			fmt.Println("Pong")
+		},
+	}
+
+	addMssqlFlags(cmd, client)
+
+	return cmd
+}
+
+func newVerifyMssqlCmd() *cobra.Command {
+	client := &MssqlClient{}
+	expect := map[string]int64{}
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify prepared tables and their row counts",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := client.InitClient(); err != nil {
+				log.Fatalf("failed to connect to SQL Server: %v", err)
+			}
+			defer client.Close()
+
+			if err := client.VerifyTables(expect); err != nil {
+				log.Fatalf("failed to verify prepared data in database %s: %v", client.database(), err)
+			}
+			fmt.Println("Prepared data verified")
+		},
+	}
+
+	addMssqlFlags(cmd, client)
+	addVerifyFlags(cmd, &client.Database, &expect)
+
+	return cmd
+}
+
+func (c *MssqlClient) database() string {
+	if c.Database == "" {
+		return DefaultMssqlDatabase
+	}
+	return c.Database
+}
+
+// connString builds a sqlserver:// URL, the certificate is trusted the same
+// way the TPC-C driver does it since SQL Server ships a self-signed one.
+func (c *MssqlClient) connString() string {
+	query := url.Values{}
+	query.Set("database", c.database())
+	query.Set("encrypt", "true")
+	query.Set("TrustServerCertificate", "true")
+
+	u := &url.URL{
+		Scheme:   "sqlserver",
+		User:     url.UserPassword(c.Username, c.Password),
+		Host:     fmt.Sprintf("%s:%d", c.Host, c.Port),
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
+
+func (c *MssqlClient) InitClient() error {
+	var err error
+	c.db, err = sql.Open("sqlserver", c.connString())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *MssqlClient) Close() error {
+	return c.db.Close()
+}
+
+func (c *MssqlClient) Exec(query string) error {
+	_, err := c.db.Exec(query)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *MssqlClient) CheckConnection() error {
+	return c.db.Ping()
+}
+
+func (c *MssqlClient) CreateDatabase(name string) error {
+	// SQL Server has no CREATE DATABASE IF NOT EXISTS
+	query := fmt.Sprintf("IF DB_ID(%s) IS NULL CREATE DATABASE %s", mssqlLiteral(name), mssqlIdentifier(name))
+	return c.Exec(query)
+}
+
+func (c *MssqlClient) DropDatabase(name string) error {
+	// kick out open sessions first, otherwise the drop fails while the
+	// database is in use
+	query := fmt.Sprintf("IF DB_ID(%[1]s) IS NOT NULL BEGIN ALTER DATABASE %[2]s SET SINGLE_USER WITH ROLLBACK IMMEDIATE; DROP DATABASE %[2]s; END",
+		mssqlLiteral(name), mssqlIdentifier(name))
+	return c.Exec(query)
+}
+
+// VerifyTables checks the row counts of tables in the default schema.
+func (c *MssqlClient) VerifyTables(expect map[string]int64) error {
+	return VerifyTables(&sqlTableChecker{
+		db:          c.db,
+		existsQuery: "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = SCHEMA_NAME() AND table_name = @p1",
+		quote:       mssqlIdentifier,
+	}, expect)
+}
+
+func mssqlIdentifier(name string) string {
+	return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+}
+
+func mssqlLiteral(value string) string {
+	return "N'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func addMssqlFlags(cmd *cobra.Command, client *MssqlClient) {
+	cmd.Flags().StringVar(&client.Host, "host", "localhost", "SQL Server host")
+	cmd.Flags().IntVar(&client.Port, "port", 1433, "SQL Server port")
+	cmd.Flags().StringVar(&client.Username, "user", "sa", "SQL Server username")
+	cmd.Flags().StringVar(&client.Password, "password", "", "SQL Server password")
+}
@@ -0,0 +1,33 @@
+package tools
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestMssqlQuoting(t *testing.T) {
+	if got := mssqlIdentifier("bench]db"); got != "[bench]]db]" {
+		t.Fatalf("unexpected identifier: %s", got)
+	}
+	if got := mssqlLiteral("it's"); got != "N'it''s'" {
+		t.Fatalf("unexpected literal: %s", got)
+	}
+}
+
+func TestMssqlConnString(t *testing.T) {
+	client := &MssqlClient{Host: "db", Port: 1433, Username: "sa", Password: "p@ss:word"}
+
+	u, err := url.Parse(client.connString())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Host != "db:1433" || u.User.Username() != "sa" {
+		t.Fatalf("unexpected connection string: %s", u)
+	}
+	if password, _ := u.User.Password(); password != "p@ss:word" {
+		t.Fatalf("unexpected password: %s", password)
+	}
+	if database := u.Query().Get("database"); database != DefaultMssqlDatabase {
+		t.Fatalf("expected default database, got %s", database)
+	}
+}
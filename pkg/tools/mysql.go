@@ -14,6 +14,7 @@ type MySQLClient struct {
 	Port     int
 	Username string
 	Password string
+	Database string
 
 	db *sql.DB
 }
@@ -28,6 +29,7 @@ func NewMySQLCmd() *cobra.Command {
 	cmd.AddCommand(newCreateMysqlDatabaseCmd())
 	cmd.AddCommand(newDropMysqlDatabaseCmd())
 	cmd.AddCommand(newPingMysqlCmd())
+	cmd.AddCommand(newVerifyMysqlCmd())
 
 	return cmd
 }
@@ -108,9 +110,35 @@ func newPingMysqlCmd() *cobra.Command {
 	return cmd
 }
 
+func newVerifyMysqlCmd() *cobra.Command {
+	client := &MySQLClient{}
+	expect := map[string]int64{}
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify prepared tables and their row counts",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := client.InitClient(); err != nil {
+				log.Fatalf("failed to connect to MySQL server: %v", err)
+			}
+			defer client.Close()
+
+			if err := client.VerifyTables(expect); err != nil {
+				log.Fatalf("failed to verify prepared data in database %s: %v", client.Database, err)
+			}
+			fmt.Println("Prepared data verified")
+		},
+	}
+
+	addMysqlFlags(cmd, client)
+	addVerifyFlags(cmd, &client.Database, &expect)
+
+	return cmd
+}
+
 func (c *MySQLClient) InitClient() error {
 	var err error
-	c.db, err = sql.Open("mysql", fmt.Sprintf("%s:%s@tcp(%s:%d)/", c.Username, c.Password, c.Host, c.Port))
+	c.db, err = sql.Open("mysql", fmt.Sprintf("%s:%s@tcp(%s:%d)/%s", c.Username, c.Password, c.Host, c.Port, c.Database))
 	if err != nil {
 		return err
 	}
@@ -146,6 +174,15 @@ func (c *MySQLClient) DropDatabase(name string) error {
 	return c.Exec(query)
 }
 
+// VerifyTables checks the row counts of tables in the current database.
+func (c *MySQLClient) VerifyTables(expect map[string]int64) error {
+	return VerifyTables(&sqlTableChecker{
+		db:          c.db,
+		existsQuery: "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?",
+		quote:       func(table string) string { return fmt.Sprintf("`%s`", table) },
+	}, expect)
+}
+
 func addMysqlFlags(cmd *cobra.Command, client *MySQLClient) {
 	cmd.Flags().StringVar(&client.Host, "host", "localhost", "MySQL host")
 	cmd.Flags().IntVar(&client.Port, "port", 3306, "MySQL port")
@@ -0,0 +1,230 @@
+package tools
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/spf13/cobra"
+)
+
+// OceanBaseOracleClient talks to an OceanBase Oracle tenant through the MySQL
+// wire protocol that obproxy exposes. Oracle tenants have no databases, a
+// schema belongs to the user of the same name, so creating and dropping a
+// "database" creates and drops that user.
+type OceanBaseOracleClient struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+
+	db *sql.DB
+}
+
+func NewOceanBaseOracleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "oceanbase-oracle",
+		Short: "OceanBase Oracle tenant tools",
+		Long:  "OceanBase Oracle tenant tools for creating and dropping schemas",
+	}
+
+	cmd.AddCommand(newCreateOceanBaseOracleSchemaCmd())
+	cmd.AddCommand(newDropOceanBaseOracleSchemaCmd())
+	cmd.AddCommand(newPingOceanBaseOracleCmd())
+
+	return cmd
+}
+
+func newCreateOceanBaseOracleSchemaCmd() *cobra.Command {
+	client := &OceanBaseOracleClient{}
+
+	cmd := &cobra.Command{
+		Use:   "create [schema name]",
+		Short: "Create new schemas",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := client.InitClient(); err != nil {
+				log.Fatalf("failed to connect to OceanBase server: %v", err)
+			}
+			defer client.Close()
+
+			for _, name := range args {
+				if err := client.CreateDatabase(name); err != nil {
+					log.Fatalf("failed to create schema %s: %v", name, err)
+				}
+				fmt.Printf("Schema %s created\n", name)
+			}
+		},
+	}
+
+	addOceanBaseOracleFlags(cmd, client)
+
+	return cmd
+}
+
+func newDropOceanBaseOracleSchemaCmd() *cobra.Command {
+	client := &OceanBaseOracleClient{}
+
+	cmd := &cobra.Command{
+		Use:   "drop [schema name]",
+		Short: "Drop schemas",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := client.InitClient(); err != nil {
+				log.Fatalf("failed to connect to OceanBase server: %v", err)
+			}
+			defer client.Close()
+
+			for _, name := range args {
+				if err := client.DropDatabase(name); err != nil {
+					log.Fatalf("failed to drop schema %s: %v", name, err)
+				}
+				fmt.Printf("Schema %s dropped\n", name)
+			}
+		},
+	}
+
+	addOceanBaseOracleFlags(cmd, client)
+
+	return cmd
+}
+
+func newPingOceanBaseOracleCmd() *cobra.Command {
+	client := &OceanBaseOracleClient{}
+
+	cmd := &cobra.Command{
+		Use:   "ping",
+		Short: "Ping OceanBase server",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := client.InitClient(); err != nil {
+				log.Fatalf("failed to connect to OceanBase server: %v", err)
+			}
+			defer client.Close()
+
+			if err := client.CheckConnection(); err != nil {
+				log.Fatalf("failed to ping OceanBase server: %v", err)
+			}
+			fmt.Println("Pong")
+		},
+	}
+
+	addOceanBaseOracleFlags(cmd, client)
+
+	return cmd
+}
+
+func (c *OceanBaseOracleClient) InitClient() error {
+	var err error
+	// interpolateParams keeps the driver away from server side prepared
+	// statements, their placeholders differ in Oracle mode
+	c.db, err = sql.Open("mysql", fmt.Sprintf("%s:%s@tcp(%s:%d)/?interpolateParams=true", c.Username, c.Password, c.Host, c.Port))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *OceanBaseOracleClient) Close() error {
+	return c.db.Close()
+}
+
+func (c *OceanBaseOracleClient) Exec(query string) error {
+	_, err := c.db.Exec(query)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *OceanBaseOracleClient) CheckConnection() error {
+	return c.db.Ping()
+}
+
+func (c *OceanBaseOracleClient) schemaExists(name string) (bool, error) {
+	var count int
+	if err := c.db.QueryRow("SELECT COUNT(*) FROM ALL_USERS WHERE USERNAME = UPPER(?)", name).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (c *OceanBaseOracleClient) CreateDatabase(name string) error {
+	user, err := oracleIdentifier(name)
+	if err != nil {
+		return err
+	}
+	password, err := oracleQuote(c.Password)
+	if err != nil {
+		return err
+	}
+
+	// create schema if not exists
+	exists, err := c.schemaExists(name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	if err := c.Exec(fmt.Sprintf("CREATE USER %s IDENTIFIED BY %s", user, password)); err != nil {
+		return err
+	}
+	return c.Exec(fmt.Sprintf("GRANT CONNECT, RESOURCE TO %s", user))
+}
+
+func (c *OceanBaseOracleClient) DropDatabase(name string) error {
+	// the login user owns a schema as well, never drop it from under us
+	if strings.EqualFold(name, oceanBaseUser(c.Username)) {
+		return fmt.Errorf("schema %s belongs to the connected user", name)
+	}
+
+	user, err := oracleIdentifier(name)
+	if err != nil {
+		return err
+	}
+
+	// drop schema if exists
+	exists, err := c.schemaExists(name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+	return c.Exec(fmt.Sprintf("DROP USER %s CASCADE", user))
+}
+
+// oceanBaseUser strips the tenant and cluster from a user@tenant#cluster login
+func oceanBaseUser(username string) string {
+	if i := strings.IndexAny(username, "@#"); i >= 0 {
+		return username[:i]
+	}
+	return username
+}
+
+// oracleIdentifier quotes the name as an identifier. The name is upper cased
+// first, so it names the same user as unquoted and as schemaExists looks up.
+// Quoted identifiers cannot hold double quotes, such names are rejected
+func oracleIdentifier(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, "\"\x00") {
+		return "", fmt.Errorf("invalid schema name %q", name)
+	}
+	return `"` + strings.ToUpper(name) + `"`, nil
+}
+
+// oracleQuote quotes the password for IDENTIFIED BY, which takes a quoted
+// identifier and therefore no double quotes
+func oracleQuote(value string) (string, error) {
+	if value == "" || strings.ContainsAny(value, "\"\x00") {
+		return "", fmt.Errorf("the password must be non empty and must not contain double quotes")
+	}
+	return `"` + value + `"`, nil
+}
+
+func addOceanBaseOracleFlags(cmd *cobra.Command, client *OceanBaseOracleClient) {
+	cmd.Flags().StringVar(&client.Host, "host", "localhost", "OceanBase host")
+	cmd.Flags().IntVar(&client.Port, "port", 2881, "OceanBase port")
+	cmd.Flags().StringVar(&client.Username, "user", "SYS", "OceanBase username, e.g. user@tenant")
+	cmd.Flags().StringVar(&client.Password, "password", "", "OceanBase password")
+}
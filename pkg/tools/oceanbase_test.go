@@ -0,0 +1,30 @@
+package tools
+
+import "testing"
+
+func TestOceanBaseUser(t *testing.T) {
+	for login, want := range map[string]string{
+		"bench":                  "bench",
+		"bench@oracle_tenant":    "bench",
+		"bench@oracle#obcluster": "bench",
+	} {
+		if got := oceanBaseUser(login); got != want {
+			t.Fatalf("oceanBaseUser(%s) = %s, want %s", login, got, want)
+		}
+	}
+}
+
+func TestOracleQuoting(t *testing.T) {
+	if got, err := oracleIdentifier("bench"); err != nil || got != `"BENCH"` {
+		t.Fatalf("unexpected identifier: %s %v", got, err)
+	}
+	if _, err := oracleIdentifier(`bench"; DROP USER sys`); err == nil {
+		t.Fatal("expected a name with double quotes to be rejected")
+	}
+	if got, err := oracleQuote("p@ss word"); err != nil || got != `"p@ss word"` {
+		t.Fatalf("unexpected password: %s %v", got, err)
+	}
+	if _, err := oracleQuote(`pa"ss`); err == nil {
+		t.Fatal("expected a password with double quotes to be rejected")
+	}
+}
@@ -17,6 +17,7 @@ type PostgreSQLClient struct {
 	Port     int
 	Username string
 	Password string
+	Database string
 
 	db *sql.DB
 }
@@ -30,6 +31,7 @@ func NewPostgreSQLCmd() *cobra.Command {
 	cmd.AddCommand(newCreatePgDatabaseCmd())
 	cmd.AddCommand(newDropPgDatabaseCmd())
 	cmd.AddCommand(newPingPgDatabaseCmd())
+	cmd.AddCommand(newVerifyPgDatabaseCmd())
 
 	return cmd
 }
@@ -111,10 +113,36 @@ func newPingPgDatabaseCmd() *cobra.Command {
 	return cmd
 }
 
+func newVerifyPgDatabaseCmd() *cobra.Command {
+	client := &PostgreSQLClient{}
+	expect := map[string]int64{}
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify prepared tables and their row counts",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := client.InitClient(); err != nil {
+				log.Fatalf("Failed to init client: %v", err)
+			}
+			defer client.Close()
+
+			if err := client.VerifyTables(expect); err != nil {
+				log.Fatalf("Failed to verify prepared data in database %s: %v", client.Database, err)
+			}
+			fmt.Println("Prepared data verified")
+		},
+	}
+
+	addPostgreSQLFlags(cmd, client)
+	addVerifyFlags(cmd, &client.Database, &expect)
+
+	return cmd
+}
+
 func (c *PostgreSQLClient) InitClient() error {
 	// create connection string with default pg database
 	connStr := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
-		c.Host, c.Port, c.Username, c.Password, DefaultPGDatabase)
+		c.Host, c.Port, c.Username, c.Password, c.database())
 
 	// open connection
 	db, err := sql.Open("postgres", connStr)
@@ -168,6 +196,28 @@ func (c *PostgreSQLClient) CheckConnection() error {
 	return c.db.Ping()
 }
 
+// database returns the database to connect to, falling back to the default
+// one used for creating and dropping databases.
+func (c *PostgreSQLClient) database() string {
+	if c.Database != "" {
+		return c.Database
+	}
+	return DefaultPGDatabase
+}
+
+// VerifyTables checks the row counts of tables in the current schema.
+func (c *PostgreSQLClient) VerifyTables(expect map[string]int64) error {
+	if c.db == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+
+	return VerifyTables(&sqlTableChecker{
+		db:          c.db,
+		existsQuery: "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = $1",
+		quote:       func(table string) string { return fmt.Sprintf("\"%s\"", table) },
+	}, expect)
+}
+
 func addPostgreSQLFlags(cmd *cobra.Command, client *PostgreSQLClient) {
 	cmd.Flags().StringVar(&client.Host, "host", "localhost", "PostgreSQL host")
 	cmd.Flags().IntVar(&client.Port, "port", 5432, "PostgreSQL port")
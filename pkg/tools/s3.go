@@ -0,0 +1,119 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/spf13/cobra"
+)
+
+// S3Object is a file uploaded by the s3 put command, used to archive the logs
+// of the benchmarks. The credentials are read from AWS_ACCESS_KEY_ID and
+// AWS_SECRET_ACCESS_KEY.
+type S3Object struct {
+	Endpoint           string
+	Region             string
+	Bucket             string
+	Key                string
+	Timeout            time.Duration
+	InsecureSkipVerify bool
+}
+
+func NewS3Cmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "s3",
+		Short: "S3 compatible object storage",
+	}
+	cmd.AddCommand(newS3PutCmd())
+	return cmd
+}
+
+func newS3PutCmd() *cobra.Command {
+	object := &S3Object{}
+
+	cmd := &cobra.Command{
+		Use:   "put [files...]",
+		Short: "Upload files as one object",
+		Long:  "Upload the files in order as one object, used to upload archived logs to a bucket",
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := context.WithTimeout(context.Background(), object.Timeout)
+			defer cancel()
+
+			if err := object.Put(ctx, os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"), args); err != nil {
+				log.Fatalf("failed to upload s3://%s/%s: %v", object.Bucket, object.Key, err)
+			}
+			log.Printf("uploaded s3://%s/%s", object.Bucket, object.Key)
+		},
+	}
+
+	cmd.Flags().StringVar(&object.Endpoint, "endpoint", "", "the endpoint of the service, e.g. https://s3.amazonaws.com")
+	cmd.Flags().StringVar(&object.Region, "region", "us-east-1", "the region of the bucket")
+	cmd.Flags().StringVar(&object.Bucket, "bucket", "", "the bucket to upload to")
+	cmd.Flags().StringVar(&object.Key, "key", "", "the key of the object")
+	cmd.Flags().DurationVar(&object.Timeout, "timeout", 10*time.Minute, "the upload timeout")
+	cmd.Flags().BoolVar(&object.InsecureSkipVerify, "insecure-skip-verify", false, "skip the verification of the server certificate")
+	_ = cmd.MarkFlagRequired("endpoint")
+	_ = cmd.MarkFlagRequired("bucket")
+	_ = cmd.MarkFlagRequired("key")
+
+	return cmd
+}
+
+// Put uploads the files one after another as the object. The bucket is
+// addressed in the path so it works with MinIO and other S3 compatible
+// services as well.
+func (o *S3Object) Put(ctx context.Context, accessKey, secretKey string, files []string) error {
+	u, err := url.Parse(o.Endpoint)
+	if err != nil {
+		return err
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid endpoint %q, expected e.g. https://s3.amazonaws.com", o.Endpoint)
+	}
+	secure := u.Scheme == "https"
+
+	transport, err := minio.DefaultTransport(secure)
+	if err != nil {
+		return err
+	}
+	if transport.TLSClientConfig != nil {
+		transport.TLSClientConfig.InsecureSkipVerify = o.InsecureSkipVerify
+	}
+	client, err := minio.New(u.Host, &minio.Options{
+		Creds:        credentials.NewStaticV4(accessKey, secretKey, ""),
+		Secure:       secure,
+		Region:       o.Region,
+		BucketLookup: minio.BucketLookupPath,
+		Transport:    transport,
+	})
+	if err != nil {
+		return err
+	}
+
+	readers := make([]io.Reader, 0, len(files))
+	size := int64(0)
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		readers = append(readers, f)
+		size += info.Size()
+	}
+
+	_, err = client.PutObject(ctx, o.Bucket, strings.TrimPrefix(o.Key, "/"), io.MultiReader(readers...), size, minio.PutObjectOptions{ContentType: "text/plain"})
+	return err
+}
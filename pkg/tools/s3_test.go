@@ -0,0 +1,51 @@
+package tools
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestS3Put(t *testing.T) {
+	var path, auth, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		path, auth, body = r.URL.Path, r.Header.Get("Authorization"), string(data)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	first := filepath.Join(dir, "0")
+	second := filepath.Join(dir, "1")
+	if err := os.WriteFile(first, []byte("tps: "), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(second, []byte("100"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	object := &S3Object{Endpoint: server.URL, Region: "us-east-1", Bucket: "bench", Key: "sb/sb-run-0/kubebench.log"}
+	if err := object.Put(context.Background(), "AKID", "secret", []string{first, second}); err != nil {
+		t.Fatal(err)
+	}
+	// the body is signed in chunks over http
+	if path != "/bench/sb/sb-run-0/kubebench.log" || !strings.Contains(body, "tps: 100") {
+		t.Fatalf("unexpected upload: %s %q", path, body)
+	}
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(auth, "/us-east-1/s3/aws4_request") {
+		t.Fatalf("expected a signed request, got %q", auth)
+	}
+
+	if err := object.Put(context.Background(), "AKID", "secret", []string{filepath.Join(dir, "missing")}); err == nil {
+		t.Fatal("expected an error for a missing file")
+	}
+	object.Endpoint = "minio:9000"
+	if err := object.Put(context.Background(), "AKID", "secret", []string{first}); err == nil {
+		t.Fatal("expected an error for an endpoint without a scheme")
+	}
+}
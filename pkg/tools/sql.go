@@ -0,0 +1,444 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// SQLTransactionNone runs every statement in autocommit mode
+	SQLTransactionNone = "none"
+	// SQLTransactionFile wraps every script in its own transaction
+	SQLTransactionFile = "file"
+	// SQLTransactionAll wraps all scripts in a single transaction
+	SQLTransactionAll = "all"
+)
+
+// SQLScript is a named list of statements
+type SQLScript struct {
+	Name       string
+	Statements []string
+}
+
+// SQLExecOptions controls how scripts are executed
+type SQLExecOptions struct {
+	Transaction string
+	Isolation   sql.IsolationLevel
+	StopOnError bool
+}
+
+// SQLExecResult counts the executed and failed statements
+type SQLExecResult struct {
+	Executed int
+	Failed   int
+}
+
+type SQLExecClient struct {
+	Driver   string
+	Host     string
+	Port     int
+	Username string
+	Password string
+	Database string
+
+	db *sql.DB
+}
+
+func NewSQLCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sql",
+		Short: "SQL tools",
+		Long:  "SQL tools for running scripts against MySQL, PostgreSQL and GaussDB",
+	}
+
+	cmd.AddCommand(newSQLExecCmd())
+
+	return cmd
+}
+
+func newSQLExecCmd() *cobra.Command {
+	client := &SQLExecClient{}
+	opts := SQLExecOptions{}
+	var dirs []string
+	var isolation string
+	var continueOnError bool
+
+	cmd := &cobra.Command{
+		Use:   "exec [script file]",
+		Short: "Execute SQL scripts",
+		Long: "Execute the statements of SQL script files in order. Statements are separated by semicolons, " +
+			"every *.sql file of a --dir (e.g. a mounted ConfigMap) is executed in name order after the given files.",
+		Run: func(cmd *cobra.Command, args []string) {
+			level, err := parseIsolation(isolation)
+			if err != nil {
+				log.Fatalf("Invalid isolation level: %v", err)
+			}
+			opts.Isolation = level
+			opts.StopOnError = !continueOnError
+
+			files, err := sqlScriptFiles(args, dirs)
+			if err != nil {
+				log.Fatalf("Failed to list SQL scripts: %v", err)
+			}
+			scripts, err := LoadSQLScripts(files, client.mysqlDialect())
+			if err != nil {
+				log.Fatalf("Failed to load SQL scripts: %v", err)
+			}
+
+			if err := client.InitClient(); err != nil {
+				log.Fatalf("Failed to connect to %s server: %v", client.Driver, err)
+			}
+			defer client.Close()
+
+			start := time.Now()
+			result, err := ExecSQLScripts(context.Background(), client.db, scripts, opts)
+			fmt.Printf("Executed %d statements, %d failed, took %s\n", result.Executed, result.Failed, time.Since(start).Round(time.Millisecond))
+			if err != nil {
+				log.Fatalf("Failed to execute SQL scripts: %v", err)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&client.Driver, "driver", "mysql", "database driver, one of mysql, postgresql, gaussdb")
+	cmd.Flags().StringVar(&client.Host, "host", "localhost", "database host")
+	cmd.Flags().IntVar(&client.Port, "port", 3306, "database port")
+	cmd.Flags().StringVar(&client.Username, "user", "root", "database username")
+	cmd.Flags().StringVar(&client.Password, "password", "", "database password")
+	cmd.Flags().StringVar(&client.Database, "database", "", "database to run the scripts in")
+	cmd.Flags().StringSliceVar(&dirs, "dir", nil, "directory whose *.sql files are executed, e.g. a mounted ConfigMap")
+	cmd.Flags().StringVar(&opts.Transaction, "transaction", SQLTransactionNone, "transaction mode, one of none, file, all")
+	cmd.Flags().StringVar(&isolation, "isolation", "", "transaction isolation level, e.g. read-committed, repeatable-read, serializable")
+	cmd.Flags().BoolVar(&continueOnError, "continue-on-error", false, "keep executing after a statement fails")
+
+	return cmd
+}
+
+// InitClient opens the connection with the client of the driver, so the
+// connection settings match the other tools commands
+func (c *SQLExecClient) InitClient() error {
+	switch c.Driver {
+	case "mysql", "tidb":
+		client := &MySQLClient{Host: c.Host, Port: c.Port, Username: c.Username, Password: c.Password, Database: c.Database}
+		if err := client.InitClient(); err != nil {
+			return err
+		}
+		c.db = client.db
+	case "postgresql":
+		client := &PostgreSQLClient{Host: c.Host, Port: c.Port, Username: c.Username, Password: c.Password, Database: c.Database}
+		if err := client.InitClient(); err != nil {
+			return err
+		}
+		c.db = client.db
+	case "gaussdb":
+		client := &GaussDBClient{Host: c.Host, Port: c.Port, Username: c.Username, Password: c.Password, Database: c.Database}
+		if err := client.InitClient(); err != nil {
+			return err
+		}
+		c.db = client.db
+	default:
+		return fmt.Errorf("unsupported driver %q", c.Driver)
+	}
+
+	return c.db.Ping()
+}
+
+func (c *SQLExecClient) mysqlDialect() bool {
+	return c.Driver == "mysql" || c.Driver == "tidb"
+}
+
+func (c *SQLExecClient) Close() error {
+	if c.db == nil {
+		return nil
+	}
+	return c.db.Close()
+}
+
+// sqlScriptFiles returns the given files followed by the *.sql files of dirs.
+// Hidden entries are skipped, a mounted ConfigMap keeps its data in them.
+func sqlScriptFiles(files []string, dirs []string) ([]string, error) {
+	result := append([]string{}, files...)
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			if strings.HasPrefix(entry.Name(), ".") || filepath.Ext(entry.Name()) != ".sql" {
+				continue
+			}
+			names = append(names, entry.Name())
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			result = append(result, filepath.Join(dir, name))
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no SQL scripts given")
+	}
+	return result, nil
+}
+
+// LoadSQLScripts reads and splits the script files
+func LoadSQLScripts(files []string, mysql bool) ([]SQLScript, error) {
+	scripts := make([]SQLScript, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		scripts = append(scripts, SQLScript{Name: file, Statements: SplitSQLStatements(string(data), mysql)})
+	}
+	return scripts, nil
+}
+
+// ExecSQLScripts runs the scripts on a single connection, so session settings
+// made by one statement apply to the following ones
+func ExecSQLScripts(ctx context.Context, db *sql.DB, scripts []SQLScript, opts SQLExecOptions) (SQLExecResult, error) {
+	result := SQLExecResult{}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer conn.Close()
+
+	switch opts.Transaction {
+	case "", SQLTransactionNone:
+		var firstErr error
+		for _, script := range scripts {
+			err := execStatements(ctx, conn, script, opts.StopOnError, &result)
+			if err != nil && opts.StopOnError {
+				return result, err
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		return result, firstErr
+	case SQLTransactionFile:
+		var firstErr error
+		for _, script := range scripts {
+			err := execInTransaction(ctx, conn, []SQLScript{script}, opts, &result)
+			if err != nil && opts.StopOnError {
+				return result, err
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		return result, firstErr
+	case SQLTransactionAll:
+		return result, execInTransaction(ctx, conn, scripts, opts, &result)
+	default:
+		return result, fmt.Errorf("unsupported transaction mode %q", opts.Transaction)
+	}
+}
+
+// execInTransaction commits when every statement succeeded and rolls back
+// otherwise, a failed transaction is never partially committed
+func execInTransaction(ctx context.Context, conn *sql.Conn, scripts []SQLScript, opts SQLExecOptions, result *SQLExecResult) error {
+	tx, err := conn.BeginTx(ctx, &sql.TxOptions{Isolation: opts.Isolation})
+	if err != nil {
+		return err
+	}
+
+	for _, script := range scripts {
+		if err := execStatements(ctx, tx, script, true, result); err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				return fmt.Errorf("%v, rollback failed: %v", err, rbErr)
+			}
+			fmt.Printf("Rolled back %s\n", script.Name)
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+type sqlExecer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func execStatements(ctx context.Context, execer sqlExecer, script SQLScript, stopOnError bool, result *SQLExecResult) error {
+	var firstErr error
+	for i, statement := range script.Statements {
+		start := time.Now()
+		res, err := execer.ExecContext(ctx, statement)
+		elapsed := time.Since(start).Round(time.Microsecond)
+		result.Executed++
+
+		if err != nil {
+			result.Failed++
+			fmt.Printf("%s:%d FAILED %s [%s]: %v\n", script.Name, i+1, elapsed, statementSummary(statement), err)
+			err = fmt.Errorf("%s statement %d: %v", script.Name, i+1, err)
+			if stopOnError {
+				return err
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		affected := ""
+		if rows, err := res.RowsAffected(); err == nil {
+			affected = fmt.Sprintf(", %d rows", rows)
+		}
+		fmt.Printf("%s:%d OK %s%s [%s]\n", script.Name, i+1, elapsed, affected, statementSummary(statement))
+	}
+	return firstErr
+}
+
+// statementSummary shortens a statement to a single line for the output
+func statementSummary(statement string) string {
+	summary := strings.Join(strings.Fields(statement), " ")
+	if len(summary) > 80 {
+		summary = summary[:77] + "..."
+	}
+	return summary
+}
+
+func parseIsolation(level string) (sql.IsolationLevel, error) {
+	switch strings.ToLower(strings.ReplaceAll(level, "_", "-")) {
+	case "", "default":
+		return sql.LevelDefault, nil
+	case "read-uncommitted":
+		return sql.LevelReadUncommitted, nil
+	case "read-committed":
+		return sql.LevelReadCommitted, nil
+	case "repeatable-read":
+		return sql.LevelRepeatableRead, nil
+	case "serializable":
+		return sql.LevelSerializable, nil
+	default:
+		return sql.LevelDefault, fmt.Errorf("unknown isolation level %q", level)
+	}
+}
+
+// SplitSQLStatements splits a script on semicolons outside of quotes and
+// comments. The MySQL dialect knows backslash escapes and # comments, the
+// PostgreSQL one dollar quoted bodies. Empty statements are dropped.
+func SplitSQLStatements(script string, mysql bool) []string {
+	statements := make([]string, 0)
+	var current strings.Builder
+
+	flush := func() {
+		if statement := strings.TrimSpace(current.String()); !isOnlyComments(statement, mysql) {
+			statements = append(statements, statement)
+		}
+		current.Reset()
+	}
+	// copyUntil copies the script up to end and moves i to its last byte
+	i := 0
+	copyUntil := func(end int) {
+		if end > len(script) {
+			end = len(script)
+		}
+		current.WriteString(script[i:end])
+		i = end - 1
+	}
+
+	for ; i < len(script); i++ {
+		ch := script[i]
+		switch {
+		case ch == '\'' || ch == '"' || ch == '`' && mysql:
+			copyUntil(closingQuote(script, i, ch, mysql && ch != '`'))
+		case strings.HasPrefix(script[i:], "--"), ch == '#' && mysql:
+			copyUntil(lineEnd(script, i))
+		case strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				copyUntil(len(script))
+				continue
+			}
+			copyUntil(i + 2 + end + 2)
+		case ch == '$' && !mysql && dollarTag(script[i:]) != "":
+			tag := dollarTag(script[i:])
+			end := strings.Index(script[i+len(tag):], tag)
+			if end < 0 {
+				copyUntil(len(script))
+				continue
+			}
+			copyUntil(i + len(tag) + end + len(tag))
+		case ch == ';':
+			flush()
+		default:
+			current.WriteByte(ch)
+		}
+	}
+	flush()
+
+	return statements
+}
+
+// closingQuote returns the index after the quote closing the one at start,
+// doubled quotes and, if enabled, backslash escapes stay inside the literal
+func closingQuote(script string, start int, quote byte, backslash bool) int {
+	for i := start + 1; i < len(script); i++ {
+		switch script[i] {
+		case '\\':
+			if backslash {
+				i++
+			}
+		case quote:
+			if i+1 < len(script) && script[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(script)
+}
+
+func lineEnd(script string, start int) int {
+	if end := strings.IndexByte(script[start:], '\n'); end >= 0 {
+		return start + end
+	}
+	return len(script)
+}
+
+// dollarTag returns the $tag$ opening a dollar quoted string, or ""
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		ch := s[i]
+		if ch == '$' {
+			return s[:i+1]
+		}
+		if !(ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || i > 1 && ch >= '0' && ch <= '9') {
+			return ""
+		}
+	}
+	return ""
+}
+
+func isOnlyComments(statement string, mysql bool) bool {
+	rest := strings.TrimSpace(statement)
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "--"), mysql && strings.HasPrefix(rest, "#"):
+			rest = rest[lineEnd(rest, 0):]
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest, "*/")
+			if end < 0 {
+				return true
+			}
+			rest = rest[end+2:]
+		default:
+			return false
+		}
+		rest = strings.TrimSpace(rest)
+	}
+	return true
+}
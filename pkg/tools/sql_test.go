@@ -0,0 +1,253 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSplitSQLStatements(t *testing.T) {
+	script := `-- create the index first
+CREATE INDEX idx_k ON sbtest1 (k);
+INSERT INTO notes VALUES ('a;b', "c;d", 'it''s');
+/* analyze; everything */ ANALYZE TABLE sbtest1;
+-- trailing comment only;
+`
+	want := []string{
+		"-- create the index first\nCREATE INDEX idx_k ON sbtest1 (k)",
+		`INSERT INTO notes VALUES ('a;b', "c;d", 'it''s')`,
+		"/* analyze; everything */ ANALYZE TABLE sbtest1",
+	}
+	if got := SplitSQLStatements(script, true); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected statements:\n%#v\nwant\n%#v", got, want)
+	}
+}
+
+func TestSplitSQLStatementsMysqlDialect(t *testing.T) {
+	script := "SET @a = 'x\\';y'; # comment; here\nSELECT `a;b` FROM t"
+	want := []string{"SET @a = 'x\\';y'", "# comment; here\nSELECT `a;b` FROM t"}
+	if got := SplitSQLStatements(script, true); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected statements:\n%#v\nwant\n%#v", got, want)
+	}
+}
+
+func TestSplitSQLStatementsPostgresDialect(t *testing.T) {
+	script := `CREATE FUNCTION f() RETURNS int AS $body$ SELECT 1; $body$ LANGUAGE sql;
+SELECT 'C:\';
+SELECT data #> '{a}' FROM t WHERE id = $1;
+SET work_mem = '64MB'`
+	want := []string{
+		"CREATE FUNCTION f() RETURNS int AS $body$ SELECT 1; $body$ LANGUAGE sql",
+		`SELECT 'C:\'`,
+		"SELECT data #> '{a}' FROM t WHERE id = $1",
+		"SET work_mem = '64MB'",
+	}
+	if got := SplitSQLStatements(script, false); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected statements:\n%#v\nwant\n%#v", got, want)
+	}
+}
+
+func TestSQLScriptFilesReadsConfigMapDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"02-analyze.sql", "01-index.sql", "README.md"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("SELECT 1;"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a mounted ConfigMap keeps its data in hidden directories
+	if err := os.Mkdir(filepath.Join(dir, "..data"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := sqlScriptFiles([]string{"first.sql"}, []string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"first.sql", filepath.Join(dir, "01-index.sql"), filepath.Join(dir, "02-analyze.sql")}
+	if !reflect.DeepEqual(files, want) {
+		t.Fatalf("expected files %v, got %v", want, files)
+	}
+
+	if _, err := sqlScriptFiles(nil, nil); err == nil {
+		t.Fatal("expected error without scripts")
+	}
+}
+
+func TestParseIsolation(t *testing.T) {
+	for level, want := range map[string]sql.IsolationLevel{
+		"":                sql.LevelDefault,
+		"read-committed":  sql.LevelReadCommitted,
+		"REPEATABLE_READ": sql.LevelRepeatableRead,
+		"serializable":    sql.LevelSerializable,
+	} {
+		got, err := parseIsolation(level)
+		if err != nil || got != want {
+			t.Fatalf("parseIsolation(%q) = %v, %v, want %v", level, got, err, want)
+		}
+	}
+	if _, err := parseIsolation("snapshot"); err == nil {
+		t.Fatal("expected unknown isolation level error")
+	}
+}
+
+func TestExecSQLScripts(t *testing.T) {
+	scripts := []SQLScript{
+		{Name: "a.sql", Statements: []string{"SET x = 1", "FAIL one", "SELECT 1"}},
+		{Name: "b.sql", Statements: []string{"SELECT 2"}},
+	}
+
+	tests := []struct {
+		name     string
+		opts     SQLExecOptions
+		wantLog  []string
+		wantExec SQLExecResult
+	}{
+		{
+			name:     "autocommit stops on error",
+			opts:     SQLExecOptions{StopOnError: true},
+			wantLog:  []string{"SET x = 1", "FAIL one"},
+			wantExec: SQLExecResult{Executed: 2, Failed: 1},
+		},
+		{
+			name:     "autocommit continues on error",
+			opts:     SQLExecOptions{},
+			wantLog:  []string{"SET x = 1", "FAIL one", "SELECT 1", "SELECT 2"},
+			wantExec: SQLExecResult{Executed: 4, Failed: 1},
+		},
+		{
+			name:     "transaction per file rolls back the failed file",
+			opts:     SQLExecOptions{Transaction: SQLTransactionFile},
+			wantLog:  []string{"BEGIN", "SET x = 1", "FAIL one", "ROLLBACK", "BEGIN", "SELECT 2", "COMMIT"},
+			wantExec: SQLExecResult{Executed: 3, Failed: 1},
+		},
+		{
+			name:     "single transaction",
+			opts:     SQLExecOptions{Transaction: SQLTransactionAll, StopOnError: true},
+			wantLog:  []string{"BEGIN", "SET x = 1", "FAIL one", "ROLLBACK"},
+			wantExec: SQLExecResult{Executed: 2, Failed: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, recorder := openRecordingDB(t)
+			result, err := ExecSQLScripts(context.Background(), db, scripts, tt.opts)
+			if err == nil || !strings.Contains(err.Error(), "a.sql statement 2") {
+				t.Fatalf("expected error of the failed statement, got %v", err)
+			}
+			if result != tt.wantExec {
+				t.Fatalf("expected result %+v, got %+v", tt.wantExec, result)
+			}
+			if got := recorder.log(); !reflect.DeepEqual(got, tt.wantLog) {
+				t.Fatalf("expected log %v, got %v", tt.wantLog, got)
+			}
+			if recorder.conns != 1 {
+				t.Fatalf("expected a single connection, got %d", recorder.conns)
+			}
+		})
+	}
+
+	db, recorder := openRecordingDB(t)
+	ok := []SQLScript{{Name: "ok.sql", Statements: []string{"SELECT 1"}}}
+	if _, err := ExecSQLScripts(context.Background(), db, ok, SQLExecOptions{Transaction: SQLTransactionAll, Isolation: sql.LevelSerializable}); err != nil {
+		t.Fatal(err)
+	}
+	if got := recorder.log(); !reflect.DeepEqual(got, []string{"BEGIN", "SELECT 1", "COMMIT"}) {
+		t.Fatalf("unexpected log %v", got)
+	}
+	if recorder.isolation != driver.IsolationLevel(sql.LevelSerializable) {
+		t.Fatalf("expected serializable transaction, got %v", recorder.isolation)
+	}
+}
+
+// recordingDriver records every statement, statements starting with FAIL
+// return an error
+type recordingDriver struct {
+	mu         sync.Mutex
+	statements []string
+	conns      int
+	isolation  driver.IsolationLevel
+}
+
+func (d *recordingDriver) record(statement string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.statements = append(d.statements, statement)
+}
+
+func (d *recordingDriver) log() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string{}, d.statements...)
+}
+
+func (d *recordingDriver) Open(string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.conns++
+	return &recordingConn{driver: d}, nil
+}
+
+type recordingConn struct {
+	driver *recordingDriver
+}
+
+func (c *recordingConn) Prepare(string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepare is not supported")
+}
+
+func (c *recordingConn) Close() error { return nil }
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *recordingConn) BeginTx(_ context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.driver.mu.Lock()
+	c.driver.isolation = opts.Isolation
+	c.driver.mu.Unlock()
+	c.driver.record("BEGIN")
+	return c, nil
+}
+
+func (c *recordingConn) Commit() error {
+	c.driver.record("COMMIT")
+	return nil
+}
+
+func (c *recordingConn) Rollback() error {
+	c.driver.record("ROLLBACK")
+	return nil
+}
+
+func (c *recordingConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.driver.record(query)
+	if strings.HasPrefix(query, "FAIL") {
+		return nil, fmt.Errorf("syntax error")
+	}
+	return driver.RowsAffected(1), nil
+}
+
+var recordingDriverCount int
+
+func openRecordingDB(t *testing.T) (*sql.DB, *recordingDriver) {
+	t.Helper()
+	recorder := &recordingDriver{}
+	recordingDriverCount++
+	name := fmt.Sprintf("recording-%d", recordingDriverCount)
+	sql.Register(name, recorder)
+
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db, recorder
+}
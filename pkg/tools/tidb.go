@@ -0,0 +1,135 @@
+package tools
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+// TiDB speaks the MySQL protocol and supports the same database statements,
+// so the TiDB tools share the MySQL client and only differ in their defaults.
+
+func NewTiDBCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tidb",
+		Short: "TiDB tools",
+		Long:  "TiDB tools for creating and dropping databases",
+	}
+
+	cmd.AddCommand(newCreateTidbDatabaseCmd())
+	cmd.AddCommand(newDropTidbDatabaseCmd())
+	cmd.AddCommand(newPingTidbCmd())
+	cmd.AddCommand(newVerifyTidbCmd())
+
+	return cmd
+}
+
+func newCreateTidbDatabaseCmd() *cobra.Command {
+	client := &MySQLClient{}
+
+	cmd := &cobra.Command{
+		Use:   "create [database name]",
+		Short: "Create new databases",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := client.InitClient(); err != nil {
+				log.Fatalf("failed to connect to TiDB server: %v", err)
+			}
+			defer client.Close()
+
+			for _, name := range args {
+				if err := client.CreateDatabase(name); err != nil {
+					log.Fatalf("failed to create database %s: %v", name, err)
+				}
+				fmt.Printf("Database %s created\n", name)
+			}
+		},
+	}
+
+	addTidbFlags(cmd, client)
+
+	return cmd
+}
+
+func newDropTidbDatabaseCmd() *cobra.Command {
+	client := &MySQLClient{}
+
+	cmd := &cobra.Command{
+		Use:   "drop [database name]",
+		Short: "Drop databases",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := client.InitClient(); err != nil {
+				log.Fatalf("failed to connect to TiDB server: %v", err)
+			}
+			defer client.Close()
+
+			for _, name := range args {
+				if err := client.DropDatabase(name); err != nil {
+					log.Fatalf("failed to drop database %s: %v", name, err)
+				}
+				fmt.Printf("Database %s dropped\n", name)
+			}
+		},
+	}
+
+	addTidbFlags(cmd, client)
+
+	return cmd
+}
+
+func newPingTidbCmd() *cobra.Command {
+	client := &MySQLClient{}
+
+	cmd := &cobra.Command{
+		Use:   "ping",
+		Short: "Ping TiDB server",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := client.InitClient(); err != nil {
+				log.Fatalf("failed to connect to TiDB server: %v", err)
+			}
+			defer client.Close()
+
+			if err := client.CheckConnection(); err != nil {
+				log.Fatalf("failed to ping TiDB server: %v", err)
+			}
+			fmt.Println("Pong")
+		},
+	}
+
+	addTidbFlags(cmd, client)
+
+	return cmd
+}
+
+func newVerifyTidbCmd() *cobra.Command {
+	client := &MySQLClient{}
+	expect := map[string]int64{}
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify prepared tables and their row counts",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := client.InitClient(); err != nil {
+				log.Fatalf("failed to connect to TiDB server: %v", err)
+			}
+			defer client.Close()
+
+			if err := client.VerifyTables(expect); err != nil {
+				log.Fatalf("failed to verify prepared data in database %s: %v", client.Database, err)
+			}
+			fmt.Println("Prepared data verified")
+		},
+	}
+
+	addTidbFlags(cmd, client)
+	addVerifyFlags(cmd, &client.Database, &expect)
+
+	return cmd
+}
+
+func addTidbFlags(cmd *cobra.Command, client *MySQLClient) {
+	cmd.Flags().StringVar(&client.Host, "host", "localhost", "TiDB host")
+	cmd.Flags().IntVar(&client.Port, "port", 4000, "TiDB port")
+	cmd.Flags().StringVar(&client.Username, "user", "root", "TiDB username")
+	cmd.Flags().StringVar(&client.Password, "password", "", "TiDB password")
+}
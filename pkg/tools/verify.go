@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// tableChecker reports whether a table exists and how many rows it holds.
+type tableChecker interface {
+	TableExists(table string) (bool, error)
+	CountRows(table string) (int64, error)
+}
+
+// VerifyTables checks that every expected table exists and holds exactly the
+// expected number of rows. All mismatches are reported together so a partial
+// prepare can be diagnosed from a single run.
+func VerifyTables(checker tableChecker, expect map[string]int64) error {
+	if len(expect) == 0 {
+		return fmt.Errorf("no tables to verify")
+	}
+
+	tables := make([]string, 0, len(expect))
+	for table := range expect {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	problems := make([]string, 0)
+	for _, table := range tables {
+		exists, err := checker.TableExists(table)
+		if err != nil {
+			return fmt.Errorf("failed to check table %s: %v", table, err)
+		}
+		if !exists {
+			problems = append(problems, fmt.Sprintf("table %s does not exist", table))
+			continue
+		}
+
+		rows, err := checker.CountRows(table)
+		if err != nil {
+			return fmt.Errorf("failed to count rows of table %s: %v", table, err)
+		}
+		if rows != expect[table] {
+			problems = append(problems, fmt.Sprintf("table %s has %d rows, expected %d", table, rows, expect[table]))
+			continue
+		}
+		fmt.Printf("Table %s has %d rows\n", table, rows)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// sqlTableChecker implements tableChecker on top of database/sql.
+type sqlTableChecker struct {
+	db *sql.DB
+
+	// existsQuery takes the table name as its only parameter and returns
+	// the number of matching tables.
+	existsQuery string
+
+	// quote quotes a table name as an identifier.
+	quote func(string) string
+}
+
+func (c *sqlTableChecker) TableExists(table string) (bool, error) {
+	var count int
+	if err := c.db.QueryRow(c.existsQuery, table).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (c *sqlTableChecker) CountRows(table string) (int64, error) {
+	var count int64
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", c.quote(table))
+	if err := c.db.QueryRow(query).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func addVerifyFlags(cmd *cobra.Command, database *string, expect *map[string]int64) {
+	cmd.Flags().StringVar(database, "database", "", "database that holds the prepared tables")
+	cmd.Flags().StringToInt64Var(expect, "expect", nil, "expected row count per table, e.g. --expect sbtest1=10000")
+	_ = cmd.MarkFlagRequired("expect")
+}
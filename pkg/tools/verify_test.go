@@ -0,0 +1,53 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type fakeTableChecker struct {
+	rows map[string]int64
+}
+
+func (c *fakeTableChecker) TableExists(table string) (bool, error) {
+	_, ok := c.rows[table]
+	return ok, nil
+}
+
+func (c *fakeTableChecker) CountRows(table string) (int64, error) {
+	rows, ok := c.rows[table]
+	if !ok {
+		return 0, fmt.Errorf("table %s does not exist", table)
+	}
+	return rows, nil
+}
+
+func TestVerifyTables(t *testing.T) {
+	checker := &fakeTableChecker{rows: map[string]int64{"sbtest1": 10000, "sbtest2": 10000}}
+	if err := VerifyTables(checker, map[string]int64{"sbtest1": 10000, "sbtest2": 10000}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyTablesReportsAllMismatches(t *testing.T) {
+	checker := &fakeTableChecker{rows: map[string]int64{"sbtest1": 10000, "sbtest2": 4096}}
+	err := VerifyTables(checker, map[string]int64{"sbtest1": 10000, "sbtest2": 10000, "sbtest3": 10000})
+	if err == nil {
+		t.Fatal("expected verification error")
+	}
+	for _, want := range []string{"table sbtest2 has 4096 rows, expected 10000", "table sbtest3 does not exist"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected error to contain %q, got %q", want, err.Error())
+		}
+	}
+	if strings.Contains(err.Error(), "sbtest1") {
+		t.Fatalf("did not expect matching table in error: %q", err.Error())
+	}
+}
+
+func TestVerifyTablesRequiresExpectations(t *testing.T) {
+	if err := VerifyTables(&fakeTableChecker{}, nil); err == nil {
+		t.Fatal("expected error without expectations")
+	}
+}
@@ -34,6 +34,7 @@ target_version = os.environ.get("TARGET_VERSION", "").strip()
 track_path = Path(os.environ["GENERATED_TRACK_PATH"])
 documents_file = Path(os.environ["DOCUMENTS_FILE"])
 index_body_file = "index.json"
+index_config_dir = os.environ.get("INDEX_CONFIG_DIR", "")
 
 random.seed(7)
 base_time = datetime.datetime(2024, 1, 1, tzinfo=datetime.timezone.utc)
@@ -242,10 +243,24 @@ def index_body_for_profile():
             "embedding": {"type": "dense_vector", "dims": 8},
         },
     }
-    return {
+    body = {
         "settings": {"number_of_shards": 1, "number_of_replicas": 0},
         "mappings": typed_mappings(profile_mappings[profile]),
     }
+    return apply_index_config(body)
+
+
+def apply_index_config(body):
+    # settings.json and mappings.json from spec.indexConfigMap replace the
+    # generated ones, the prepare step creates the index from the same files
+    if not index_config_dir:
+        return body
+    for key in ("settings", "mappings"):
+        path = Path(index_config_dir) / f"{key}.json"
+        if path.is_file():
+            body[key] = json.loads(path.read_text(encoding="utf-8"))
+            print(f"Using index {key} from {path}")
+    return body
 
 
 def log_doc(i):
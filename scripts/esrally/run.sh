@@ -10,7 +10,19 @@ if [ "$status" -ne 0 ]; then
   exit "$status"
 fi
 
-set -- race --pipeline=benchmark-only --target-hosts "$TARGET_HOSTS" --track-path "$GENERATED_TRACK_PATH" --offline --on-error "$ON_ERROR" --report-format "$REPORT_FORMAT" --report-file "$REPORT_FILE" --challenge "$WORKLOAD"
+# Rally has no json summary report, the json report is the results of the
+# race taken from race.json, Rally writes its csv report aside
+RALLY_REPORT_FORMAT="$REPORT_FORMAT"
+RALLY_REPORT_FILE="$REPORT_FILE"
+RACE_ID=""
+if [ "$REPORT_FORMAT" = "json" ]; then
+  RACE_ID="$(python3 -c 'import uuid; print(uuid.uuid4())')"
+  RALLY_REPORT_FORMAT=csv
+  RALLY_REPORT_FILE=/tmp/esrally-report.csv
+fi
+
+set -- race --pipeline=benchmark-only --target-hosts "$TARGET_HOSTS" --track-path "$GENERATED_TRACK_PATH" --offline --on-error "$ON_ERROR" --report-format "$RALLY_REPORT_FORMAT" --report-file "$RALLY_REPORT_FILE" --challenge "$WORKLOAD"
+if [ -n "$RACE_ID" ]; then set -- "$@" --race-id "$RACE_ID"; fi
 if [ -n "$TRACK_PARAMS" ]; then set -- "$@" --track-params "$TRACK_PARAMS"; fi
 if [ -n "$CLIENT_OPTIONS" ]; then set -- "$@" --client-options "$CLIENT_OPTIONS"; fi
 if [ -n "$TELEMETRY" ]; then set -- "$@" --telemetry "$TELEMETRY"; fi
@@ -20,7 +32,17 @@ set +e
 esrally "$@" > /tmp/esrally.out 2>&1
 status=$?
 cat /tmp/esrally.out | tee -a "${ESRALLY_LOG_FILE}"
-if [ -f "$REPORT_FILE" ]; then
+if [ -n "$RACE_ID" ]; then
+  RACE_FILE="${RALLY_HOME:-$HOME/.rally}/benchmarks/races/$RACE_ID/race.json"
+  if [ -f "$RACE_FILE" ]; then
+    python3 -c 'import json, sys; json.dump(json.load(open(sys.argv[1]))["results"], open(sys.argv[2], "w"))' "$RACE_FILE" "$REPORT_FILE"
+  fi
+  if [ -f "$REPORT_FILE" ]; then
+    echo "Rally JSON report:" | tee -a "${ESRALLY_LOG_FILE}"
+    cat "$REPORT_FILE" | tee -a "${ESRALLY_LOG_FILE}"
+    echo | tee -a "${ESRALLY_LOG_FILE}"
+  fi
+elif [ -f "$REPORT_FILE" ]; then
   echo "Rally CSV report:" | tee -a "${ESRALLY_LOG_FILE}"
   cat "$REPORT_FILE" | tee -a "${ESRALLY_LOG_FILE}"
 fi